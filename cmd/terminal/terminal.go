@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"github.com/cirruslabs/terminal/internal/command"
 	"log"
 	"os"
@@ -25,6 +26,13 @@ func main() {
 
 	if err := command.NewRootCmd().ExecuteContext(ctx); err != nil {
 		cancel()
+
+		// Propagate the exit code of the remote command
+		var exitCodeErr *command.ExitCodeError
+		if errors.As(err, &exitCodeErr) {
+			os.Exit(exitCodeErr.Code)
+		}
+
 		log.Fatal(err)
 	}
 
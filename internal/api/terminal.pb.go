@@ -20,6 +20,179 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Compression int32
+
+const (
+	Compression_COMPRESSION_NONE Compression = 0
+	// Raw DEFLATE stream (RFC 1951)
+	Compression_DEFLATE Compression = 1
+	// Zstandard frame (RFC 8878)
+	Compression_ZSTD Compression = 2
+)
+
+// Enum value maps for Compression.
+var (
+	Compression_name = map[int32]string{
+		0: "COMPRESSION_NONE",
+		1: "DEFLATE",
+		2: "ZSTD",
+	}
+	Compression_value = map[string]int32{
+		"COMPRESSION_NONE": 0,
+		"DEFLATE":          1,
+		"ZSTD":             2,
+	}
+)
+
+func (x Compression) Enum() *Compression {
+	p := new(Compression)
+	*p = x
+	return p
+}
+
+func (x Compression) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Compression) Descriptor() protoreflect.EnumDescriptor {
+	return file_terminal_proto_enumTypes[0].Descriptor()
+}
+
+func (Compression) Type() protoreflect.EnumType {
+	return &file_terminal_proto_enumTypes[0]
+}
+
+func (x Compression) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Compression.Descriptor instead.
+func (Compression) EnumDescriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{0}
+}
+
+type FileTransfer_Direction int32
+
+const (
+	FileTransfer_DIRECTION_UNSPECIFIED FileTransfer_Direction = 0
+	// From the Host to the Guest
+	FileTransfer_DOWNLOAD FileTransfer_Direction = 1
+	// From the Guest to the Host
+	FileTransfer_UPLOAD FileTransfer_Direction = 2
+)
+
+// Enum value maps for FileTransfer_Direction.
+var (
+	FileTransfer_Direction_name = map[int32]string{
+		0: "DIRECTION_UNSPECIFIED",
+		1: "DOWNLOAD",
+		2: "UPLOAD",
+	}
+	FileTransfer_Direction_value = map[string]int32{
+		"DIRECTION_UNSPECIFIED": 0,
+		"DOWNLOAD":              1,
+		"UPLOAD":                2,
+	}
+)
+
+func (x FileTransfer_Direction) Enum() *FileTransfer_Direction {
+	p := new(FileTransfer_Direction)
+	*p = x
+	return p
+}
+
+func (x FileTransfer_Direction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FileTransfer_Direction) Descriptor() protoreflect.EnumDescriptor {
+	return file_terminal_proto_enumTypes[1].Descriptor()
+}
+
+func (FileTransfer_Direction) Type() protoreflect.EnumType {
+	return &file_terminal_proto_enumTypes[1]
+}
+
+func (x FileTransfer_Direction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FileTransfer_Direction.Descriptor instead.
+func (FileTransfer_Direction) EnumDescriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{17, 0}
+}
+
+type Termination_Reason int32
+
+const (
+	Termination_REASON_UNSPECIFIED Termination_Reason = 0
+	// The shell or the command has exited, see exit_status
+	Termination_SHELL_EXITED Termination_Reason = 1
+	// The Host has disconnected or has failed to pick up the session
+	Termination_HOST_DISCONNECTED Termination_Reason = 2
+	// The session has been idle for too long
+	Termination_IDLE_TIMEOUT Termination_Reason = 3
+	// The session was forcibly ended by an administrator
+	Termination_KICKED_BY_ADMIN Termination_Reason = 4
+	// The server is shutting down
+	Termination_SERVER_SHUTDOWN Termination_Reason = 5
+	// The session has reached its maximum duration
+	Termination_MAX_DURATION_EXCEEDED Termination_Reason = 6
+	// The shell, the command or the working directory requested by the Guest is not allowed by the Host
+	Termination_NOT_ALLOWED Termination_Reason = 7
+)
+
+// Enum value maps for Termination_Reason.
+var (
+	Termination_Reason_name = map[int32]string{
+		0: "REASON_UNSPECIFIED",
+		1: "SHELL_EXITED",
+		2: "HOST_DISCONNECTED",
+		3: "IDLE_TIMEOUT",
+		4: "KICKED_BY_ADMIN",
+		5: "SERVER_SHUTDOWN",
+		6: "MAX_DURATION_EXCEEDED",
+		7: "NOT_ALLOWED",
+	}
+	Termination_Reason_value = map[string]int32{
+		"REASON_UNSPECIFIED":    0,
+		"SHELL_EXITED":          1,
+		"HOST_DISCONNECTED":     2,
+		"IDLE_TIMEOUT":          3,
+		"KICKED_BY_ADMIN":       4,
+		"SERVER_SHUTDOWN":       5,
+		"MAX_DURATION_EXCEEDED": 6,
+		"NOT_ALLOWED":           7,
+	}
+)
+
+func (x Termination_Reason) Enum() *Termination_Reason {
+	p := new(Termination_Reason)
+	*p = x
+	return p
+}
+
+func (x Termination_Reason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Termination_Reason) Descriptor() protoreflect.EnumDescriptor {
+	return file_terminal_proto_enumTypes[2].Descriptor()
+}
+
+func (Termination_Reason) Type() protoreflect.EnumType {
+	return &file_terminal_proto_enumTypes[2]
+}
+
+func (x Termination_Reason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Termination_Reason.Descriptor instead.
+func (Termination_Reason) EnumDescriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{26, 0}
+}
+
 type GuestTerminalRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -29,6 +202,7 @@ type GuestTerminalRequest struct {
 	//	*GuestTerminalRequest_Hello_
 	//	*GuestTerminalRequest_ChangeDimensions
 	//	*GuestTerminalRequest_Input
+	//	*GuestTerminalRequest_CloseInput
 	Operation isGuestTerminalRequest_Operation `protobuf_oneof:"operation"`
 }
 
@@ -92,6 +266,13 @@ func (x *GuestTerminalRequest) GetInput() *Data {
 	return nil
 }
 
+func (x *GuestTerminalRequest) GetCloseInput() bool {
+	if x, ok := x.GetOperation().(*GuestTerminalRequest_CloseInput); ok {
+		return x.CloseInput
+	}
+	return false
+}
+
 type isGuestTerminalRequest_Operation interface {
 	isGuestTerminalRequest_Operation()
 }
@@ -111,12 +292,19 @@ type GuestTerminalRequest_Input struct {
 	Input *Data `protobuf:"bytes,3,opt,name=input,proto3,oneof"`
 }
 
+type GuestTerminalRequest_CloseInput struct {
+	// Signals the end of the input to the command, only makes sense for the commands without a PTY
+	CloseInput bool `protobuf:"varint,4,opt,name=close_input,json=closeInput,proto3,oneof"`
+}
+
 func (*GuestTerminalRequest_Hello_) isGuestTerminalRequest_Operation() {}
 
 func (*GuestTerminalRequest_ChangeDimensions) isGuestTerminalRequest_Operation() {}
 
 func (*GuestTerminalRequest_Input) isGuestTerminalRequest_Operation() {}
 
+func (*GuestTerminalRequest_CloseInput) isGuestTerminalRequest_Operation() {}
+
 type GuestTerminalResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -124,6 +312,11 @@ type GuestTerminalResponse struct {
 
 	// Types that are assignable to Operation:
 	//	*GuestTerminalResponse_Output
+	//	*GuestTerminalResponse_Hello_
+	//	*GuestTerminalResponse_ErrorOutput
+	//	*GuestTerminalResponse_Termination
+	//	*GuestTerminalResponse_OutputTruncated
+	//	*GuestTerminalResponse_InputIgnored
 	Operation isGuestTerminalResponse_Operation `protobuf_oneof:"operation"`
 }
 
@@ -173,6 +366,41 @@ func (x *GuestTerminalResponse) GetOutput() *Data {
 	return nil
 }
 
+func (x *GuestTerminalResponse) GetHello() *GuestTerminalResponse_Hello {
+	if x, ok := x.GetOperation().(*GuestTerminalResponse_Hello_); ok {
+		return x.Hello
+	}
+	return nil
+}
+
+func (x *GuestTerminalResponse) GetErrorOutput() *Data {
+	if x, ok := x.GetOperation().(*GuestTerminalResponse_ErrorOutput); ok {
+		return x.ErrorOutput
+	}
+	return nil
+}
+
+func (x *GuestTerminalResponse) GetTermination() *Termination {
+	if x, ok := x.GetOperation().(*GuestTerminalResponse_Termination); ok {
+		return x.Termination
+	}
+	return nil
+}
+
+func (x *GuestTerminalResponse) GetOutputTruncated() *OutputTruncated {
+	if x, ok := x.GetOperation().(*GuestTerminalResponse_OutputTruncated); ok {
+		return x.OutputTruncated
+	}
+	return nil
+}
+
+func (x *GuestTerminalResponse) GetInputIgnored() *InputIgnored {
+	if x, ok := x.GetOperation().(*GuestTerminalResponse_InputIgnored); ok {
+		return x.InputIgnored
+	}
+	return nil
+}
+
 type isGuestTerminalResponse_Operation interface {
 	isGuestTerminalResponse_Operation()
 }
@@ -182,20 +410,54 @@ type GuestTerminalResponse_Output struct {
 	Output *Data `protobuf:"bytes,1,opt,name=output,proto3,oneof"`
 }
 
+type GuestTerminalResponse_Hello_ struct {
+	// Sent once in reply to the Hello message from the Guest
+	Hello *GuestTerminalResponse_Hello `protobuf:"bytes,2,opt,name=hello,proto3,oneof"`
+}
+
+type GuestTerminalResponse_ErrorOutput struct {
+	// Error output of the command, only sent for the commands without a PTY
+	ErrorOutput *Data `protobuf:"bytes,3,opt,name=error_output,json=errorOutput,proto3,oneof"`
+}
+
+type GuestTerminalResponse_Termination struct {
+	// Sent once the session ends (e.g. when the shell or the command exits), no more messages will follow
+	Termination *Termination `protobuf:"bytes,4,opt,name=termination,proto3,oneof"`
+}
+
+type GuestTerminalResponse_OutputTruncated struct {
+	// Sent in place of the terminal output that was dropped because this Guest couldn't keep up with it
+	OutputTruncated *OutputTruncated `protobuf:"bytes,5,opt,name=output_truncated,json=outputTruncated,proto3,oneof"`
+}
+
+type GuestTerminalResponse_InputIgnored struct {
+	// Sent once the read-only Guest tries to send the terminal input or the dimension changes, which are ignored
+	InputIgnored *InputIgnored `protobuf:"bytes,6,opt,name=input_ignored,json=inputIgnored,proto3,oneof"`
+}
+
 func (*GuestTerminalResponse_Output) isGuestTerminalResponse_Operation() {}
 
-type HostControlRequest struct {
+func (*GuestTerminalResponse_Hello_) isGuestTerminalResponse_Operation() {}
+
+func (*GuestTerminalResponse_ErrorOutput) isGuestTerminalResponse_Operation() {}
+
+func (*GuestTerminalResponse_Termination) isGuestTerminalResponse_Operation() {}
+
+func (*GuestTerminalResponse_OutputTruncated) isGuestTerminalResponse_Operation() {}
+
+func (*GuestTerminalResponse_InputIgnored) isGuestTerminalResponse_Operation() {}
+
+type InputIgnored struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Types that are assignable to Operation:
-	//	*HostControlRequest_Hello_
-	Operation isHostControlRequest_Operation `protobuf_oneof:"operation"`
+	// Human-readable explanation of why the input is ignored
+	Reason string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
 }
 
-func (x *HostControlRequest) Reset() {
-	*x = HostControlRequest{}
+func (x *InputIgnored) Reset() {
+	*x = InputIgnored{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_terminal_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -203,13 +465,13 @@ func (x *HostControlRequest) Reset() {
 	}
 }
 
-func (x *HostControlRequest) String() string {
+func (x *InputIgnored) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HostControlRequest) ProtoMessage() {}
+func (*InputIgnored) ProtoMessage() {}
 
-func (x *HostControlRequest) ProtoReflect() protoreflect.Message {
+func (x *InputIgnored) ProtoReflect() protoreflect.Message {
 	mi := &file_terminal_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -221,49 +483,31 @@ func (x *HostControlRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HostControlRequest.ProtoReflect.Descriptor instead.
-func (*HostControlRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use InputIgnored.ProtoReflect.Descriptor instead.
+func (*InputIgnored) Descriptor() ([]byte, []int) {
 	return file_terminal_proto_rawDescGZIP(), []int{2}
 }
 
-func (m *HostControlRequest) GetOperation() isHostControlRequest_Operation {
-	if m != nil {
-		return m.Operation
-	}
-	return nil
-}
-
-func (x *HostControlRequest) GetHello() *HostControlRequest_Hello {
-	if x, ok := x.GetOperation().(*HostControlRequest_Hello_); ok {
-		return x.Hello
+func (x *InputIgnored) GetReason() string {
+	if x != nil {
+		return x.Reason
 	}
-	return nil
-}
-
-type isHostControlRequest_Operation interface {
-	isHostControlRequest_Operation()
-}
-
-type HostControlRequest_Hello_ struct {
-	// Mandatory first message from the Host after it opens this channel
-	Hello *HostControlRequest_Hello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+	return ""
 }
 
-func (*HostControlRequest_Hello_) isHostControlRequest_Operation() {}
-
-type HostControlResponse struct {
+type OutputTruncated struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Types that are assignable to Operation:
-	//	*HostControlResponse_Hello_
-	//	*HostControlResponse_DataChannelRequest_
-	Operation isHostControlResponse_Operation `protobuf_oneof:"operation"`
+	// Number of the dropped terminal output bytes, which still count towards the output offset
+	DroppedOutputBytes uint64 `protobuf:"varint,1,opt,name=dropped_output_bytes,json=droppedOutputBytes,proto3" json:"dropped_output_bytes,omitempty"`
+	// Number of the dropped error output bytes
+	DroppedErrorOutputBytes uint64 `protobuf:"varint,2,opt,name=dropped_error_output_bytes,json=droppedErrorOutputBytes,proto3" json:"dropped_error_output_bytes,omitempty"`
 }
 
-func (x *HostControlResponse) Reset() {
-	*x = HostControlResponse{}
+func (x *OutputTruncated) Reset() {
+	*x = OutputTruncated{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_terminal_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -271,13 +515,13 @@ func (x *HostControlResponse) Reset() {
 	}
 }
 
-func (x *HostControlResponse) String() string {
+func (x *OutputTruncated) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HostControlResponse) ProtoMessage() {}
+func (*OutputTruncated) ProtoMessage() {}
 
-func (x *HostControlResponse) ProtoReflect() protoreflect.Message {
+func (x *OutputTruncated) ProtoReflect() protoreflect.Message {
 	mi := &file_terminal_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -289,63 +533,42 @@ func (x *HostControlResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HostControlResponse.ProtoReflect.Descriptor instead.
-func (*HostControlResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use OutputTruncated.ProtoReflect.Descriptor instead.
+func (*OutputTruncated) Descriptor() ([]byte, []int) {
 	return file_terminal_proto_rawDescGZIP(), []int{3}
 }
 
-func (m *HostControlResponse) GetOperation() isHostControlResponse_Operation {
-	if m != nil {
-		return m.Operation
-	}
-	return nil
-}
-
-func (x *HostControlResponse) GetHello() *HostControlResponse_Hello {
-	if x, ok := x.GetOperation().(*HostControlResponse_Hello_); ok {
-		return x.Hello
+func (x *OutputTruncated) GetDroppedOutputBytes() uint64 {
+	if x != nil {
+		return x.DroppedOutputBytes
 	}
-	return nil
+	return 0
 }
 
-func (x *HostControlResponse) GetDataChannelRequest() *HostControlResponse_DataChannelRequest {
-	if x, ok := x.GetOperation().(*HostControlResponse_DataChannelRequest_); ok {
-		return x.DataChannelRequest
+func (x *OutputTruncated) GetDroppedErrorOutputBytes() uint64 {
+	if x != nil {
+		return x.DroppedErrorOutputBytes
 	}
-	return nil
-}
-
-type isHostControlResponse_Operation interface {
-	isHostControlResponse_Operation()
-}
-
-type HostControlResponse_Hello_ struct {
-	// Mandatory reply to the Hello message sent from the Host
-	Hello *HostControlResponse_Hello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
-}
-
-type HostControlResponse_DataChannelRequest_ struct {
-	// Emitted when a Guest opens a new terminal channel
-	DataChannelRequest *HostControlResponse_DataChannelRequest `protobuf:"bytes,2,opt,name=data_channel_request,json=dataChannelRequest,proto3,oneof"`
+	return 0
 }
 
-func (*HostControlResponse_Hello_) isHostControlResponse_Operation() {}
-
-func (*HostControlResponse_DataChannelRequest_) isHostControlResponse_Operation() {}
-
-type HostDataRequest struct {
+type ReplayRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Types that are assignable to Operation:
-	//	*HostDataRequest_Hello_
-	//	*HostDataRequest_Output
-	Operation isHostDataRequest_Operation `protobuf_oneof:"operation"`
+	// Locator of the terminal on which the recorded session took place
+	Locator string `protobuf:"bytes,1,opt,name=locator,proto3" json:"locator,omitempty"`
+	// Either the trusted or the read-only secret of that terminal, only the former gives access to the recorded input
+	Secret string `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	// Recording identifier received in the GuestTerminalResponse's Hello message
+	RecordingId string `protobuf:"bytes,3,opt,name=recording_id,json=recordingId,proto3" json:"recording_id,omitempty"`
+	// Playback speed multiplier, 1 (the original timing) by default
+	Speed float64 `protobuf:"fixed64,4,opt,name=speed,proto3" json:"speed,omitempty"`
 }
 
-func (x *HostDataRequest) Reset() {
-	*x = HostDataRequest{}
+func (x *ReplayRequest) Reset() {
+	*x = ReplayRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_terminal_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -353,13 +576,13 @@ func (x *HostDataRequest) Reset() {
 	}
 }
 
-func (x *HostDataRequest) String() string {
+func (x *ReplayRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HostDataRequest) ProtoMessage() {}
+func (*ReplayRequest) ProtoMessage() {}
 
-func (x *HostDataRequest) ProtoReflect() protoreflect.Message {
+func (x *ReplayRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_terminal_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -371,63 +594,53 @@ func (x *HostDataRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HostDataRequest.ProtoReflect.Descriptor instead.
-func (*HostDataRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReplayRequest.ProtoReflect.Descriptor instead.
+func (*ReplayRequest) Descriptor() ([]byte, []int) {
 	return file_terminal_proto_rawDescGZIP(), []int{4}
 }
 
-func (m *HostDataRequest) GetOperation() isHostDataRequest_Operation {
-	if m != nil {
-		return m.Operation
+func (x *ReplayRequest) GetLocator() string {
+	if x != nil {
+		return x.Locator
 	}
-	return nil
+	return ""
 }
 
-func (x *HostDataRequest) GetHello() *HostDataRequest_Hello {
-	if x, ok := x.GetOperation().(*HostDataRequest_Hello_); ok {
-		return x.Hello
+func (x *ReplayRequest) GetSecret() string {
+	if x != nil {
+		return x.Secret
 	}
-	return nil
+	return ""
 }
 
-func (x *HostDataRequest) GetOutput() *Data {
-	if x, ok := x.GetOperation().(*HostDataRequest_Output); ok {
-		return x.Output
+func (x *ReplayRequest) GetRecordingId() string {
+	if x != nil {
+		return x.RecordingId
 	}
-	return nil
-}
-
-type isHostDataRequest_Operation interface {
-	isHostDataRequest_Operation()
-}
-
-type HostDataRequest_Hello_ struct {
-	// Mandatory first message to be sent by the Host
-	Hello *HostDataRequest_Hello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+	return ""
 }
 
-type HostDataRequest_Output struct {
-	// Terminal output to the Guest
-	Output *Data `protobuf:"bytes,2,opt,name=output,proto3,oneof"`
+func (x *ReplayRequest) GetSpeed() float64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
 }
 
-func (*HostDataRequest_Hello_) isHostDataRequest_Operation() {}
-
-func (*HostDataRequest_Output) isHostDataRequest_Operation() {}
-
-type HostDataResponse struct {
+type ReplayResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	// Types that are assignable to Operation:
-	//	*HostDataResponse_ChangeDimensions
-	//	*HostDataResponse_Input
-	Operation isHostDataResponse_Operation `protobuf_oneof:"operation"`
+	//	*ReplayResponse_Output
+	//	*ReplayResponse_ChangeDimensions
+	//	*ReplayResponse_Input
+	Operation isReplayResponse_Operation `protobuf_oneof:"operation"`
 }
 
-func (x *HostDataResponse) Reset() {
-	*x = HostDataResponse{}
+func (x *ReplayResponse) Reset() {
+	*x = ReplayResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_terminal_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -435,13 +648,13 @@ func (x *HostDataResponse) Reset() {
 	}
 }
 
-func (x *HostDataResponse) String() string {
+func (x *ReplayResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HostDataResponse) ProtoMessage() {}
+func (*ReplayResponse) ProtoMessage() {}
 
-func (x *HostDataResponse) ProtoReflect() protoreflect.Message {
+func (x *ReplayResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_terminal_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -453,61 +666,78 @@ func (x *HostDataResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HostDataResponse.ProtoReflect.Descriptor instead.
-func (*HostDataResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReplayResponse.ProtoReflect.Descriptor instead.
+func (*ReplayResponse) Descriptor() ([]byte, []int) {
 	return file_terminal_proto_rawDescGZIP(), []int{5}
 }
 
-func (m *HostDataResponse) GetOperation() isHostDataResponse_Operation {
+func (m *ReplayResponse) GetOperation() isReplayResponse_Operation {
 	if m != nil {
 		return m.Operation
 	}
 	return nil
 }
 
-func (x *HostDataResponse) GetChangeDimensions() *TerminalDimensions {
-	if x, ok := x.GetOperation().(*HostDataResponse_ChangeDimensions); ok {
+func (x *ReplayResponse) GetOutput() *Data {
+	if x, ok := x.GetOperation().(*ReplayResponse_Output); ok {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *ReplayResponse) GetChangeDimensions() *TerminalDimensions {
+	if x, ok := x.GetOperation().(*ReplayResponse_ChangeDimensions); ok {
 		return x.ChangeDimensions
 	}
 	return nil
 }
 
-func (x *HostDataResponse) GetInput() *Data {
-	if x, ok := x.GetOperation().(*HostDataResponse_Input); ok {
+func (x *ReplayResponse) GetInput() *Data {
+	if x, ok := x.GetOperation().(*ReplayResponse_Input); ok {
 		return x.Input
 	}
 	return nil
 }
 
-type isHostDataResponse_Operation interface {
-	isHostDataResponse_Operation()
+type isReplayResponse_Operation interface {
+	isReplayResponse_Operation()
 }
 
-type HostDataResponse_ChangeDimensions struct {
-	// Emitted when the Guest decides to change an already created terminal dimensions (e.g. when the web UI terminal size changes)
-	ChangeDimensions *TerminalDimensions `protobuf:"bytes,1,opt,name=change_dimensions,json=changeDimensions,proto3,oneof"`
+type ReplayResponse_Output struct {
+	// Terminal output as it was recorded
+	Output *Data `protobuf:"bytes,1,opt,name=output,proto3,oneof"`
 }
 
-type HostDataResponse_Input struct {
-	// Terminal input from the Guest
-	Input *Data `protobuf:"bytes,2,opt,name=input,proto3,oneof"`
+type ReplayResponse_ChangeDimensions struct {
+	// Sent before any output with the initial terminal dimensions and then each time they've changed
+	ChangeDimensions *TerminalDimensions `protobuf:"bytes,2,opt,name=change_dimensions,json=changeDimensions,proto3,oneof"`
 }
 
-func (*HostDataResponse_ChangeDimensions) isHostDataResponse_Operation() {}
+type ReplayResponse_Input struct {
+	//
+	// Terminal input as it was recorded (which may contain passwords), only sent when
+	// the server records the input and the trusted secret of the terminal was provided
+	Input *Data `protobuf:"bytes,3,opt,name=input,proto3,oneof"`
+}
 
-func (*HostDataResponse_Input) isHostDataResponse_Operation() {}
+func (*ReplayResponse_Output) isReplayResponse_Operation() {}
 
-type TerminalDimensions struct {
+func (*ReplayResponse_ChangeDimensions) isReplayResponse_Operation() {}
+
+func (*ReplayResponse_Input) isReplayResponse_Operation() {}
+
+type HostControlRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	WidthColumns uint32 `protobuf:"varint,1,opt,name=width_columns,json=widthColumns,proto3" json:"width_columns,omitempty"`
-	HeightRows   uint32 `protobuf:"varint,2,opt,name=height_rows,json=heightRows,proto3" json:"height_rows,omitempty"`
+	// Types that are assignable to Operation:
+	//	*HostControlRequest_Hello_
+	Operation isHostControlRequest_Operation `protobuf_oneof:"operation"`
 }
 
-func (x *TerminalDimensions) Reset() {
-	*x = TerminalDimensions{}
+func (x *HostControlRequest) Reset() {
+	*x = HostControlRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_terminal_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -515,13 +745,13 @@ func (x *TerminalDimensions) Reset() {
 	}
 }
 
-func (x *TerminalDimensions) String() string {
+func (x *HostControlRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TerminalDimensions) ProtoMessage() {}
+func (*HostControlRequest) ProtoMessage() {}
 
-func (x *TerminalDimensions) ProtoReflect() protoreflect.Message {
+func (x *HostControlRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_terminal_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -533,35 +763,52 @@ func (x *TerminalDimensions) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TerminalDimensions.ProtoReflect.Descriptor instead.
-func (*TerminalDimensions) Descriptor() ([]byte, []int) {
+// Deprecated: Use HostControlRequest.ProtoReflect.Descriptor instead.
+func (*HostControlRequest) Descriptor() ([]byte, []int) {
 	return file_terminal_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *TerminalDimensions) GetWidthColumns() uint32 {
-	if x != nil {
-		return x.WidthColumns
+func (m *HostControlRequest) GetOperation() isHostControlRequest_Operation {
+	if m != nil {
+		return m.Operation
 	}
-	return 0
+	return nil
 }
 
-func (x *TerminalDimensions) GetHeightRows() uint32 {
-	if x != nil {
-		return x.HeightRows
+func (x *HostControlRequest) GetHello() *HostControlRequest_Hello {
+	if x, ok := x.GetOperation().(*HostControlRequest_Hello_); ok {
+		return x.Hello
 	}
-	return 0
+	return nil
 }
 
-type Data struct {
+type isHostControlRequest_Operation interface {
+	isHostControlRequest_Operation()
+}
+
+type HostControlRequest_Hello_ struct {
+	// Mandatory first message from the Host after it opens this channel
+	Hello *HostControlRequest_Hello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+}
+
+func (*HostControlRequest_Hello_) isHostControlRequest_Operation() {}
+
+type HostControlResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// Types that are assignable to Operation:
+	//	*HostControlResponse_Hello_
+	//	*HostControlResponse_DataChannelRequest_
+	//	*HostControlResponse_TunnelRequest_
+	//	*HostControlResponse_FileTransferRequest_
+	//	*HostControlResponse_Drain_
+	Operation isHostControlResponse_Operation `protobuf_oneof:"operation"`
 }
 
-func (x *Data) Reset() {
-	*x = Data{}
+func (x *HostControlResponse) Reset() {
+	*x = HostControlResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_terminal_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -569,13 +816,13 @@ func (x *Data) Reset() {
 	}
 }
 
-func (x *Data) String() string {
+func (x *HostControlResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Data) ProtoMessage() {}
+func (*HostControlResponse) ProtoMessage() {}
 
-func (x *Data) ProtoReflect() protoreflect.Message {
+func (x *HostControlResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_terminal_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -587,28 +834,109 @@ func (x *Data) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Data.ProtoReflect.Descriptor instead.
-func (*Data) Descriptor() ([]byte, []int) {
+// Deprecated: Use HostControlResponse.ProtoReflect.Descriptor instead.
+func (*HostControlResponse) Descriptor() ([]byte, []int) {
 	return file_terminal_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *Data) GetData() []byte {
-	if x != nil {
-		return x.Data
+func (m *HostControlResponse) GetOperation() isHostControlResponse_Operation {
+	if m != nil {
+		return m.Operation
 	}
 	return nil
 }
 
-type Error struct {
+func (x *HostControlResponse) GetHello() *HostControlResponse_Hello {
+	if x, ok := x.GetOperation().(*HostControlResponse_Hello_); ok {
+		return x.Hello
+	}
+	return nil
+}
+
+func (x *HostControlResponse) GetDataChannelRequest() *HostControlResponse_DataChannelRequest {
+	if x, ok := x.GetOperation().(*HostControlResponse_DataChannelRequest_); ok {
+		return x.DataChannelRequest
+	}
+	return nil
+}
+
+func (x *HostControlResponse) GetTunnelRequest() *HostControlResponse_TunnelRequest {
+	if x, ok := x.GetOperation().(*HostControlResponse_TunnelRequest_); ok {
+		return x.TunnelRequest
+	}
+	return nil
+}
+
+func (x *HostControlResponse) GetFileTransferRequest() *HostControlResponse_FileTransferRequest {
+	if x, ok := x.GetOperation().(*HostControlResponse_FileTransferRequest_); ok {
+		return x.FileTransferRequest
+	}
+	return nil
+}
+
+func (x *HostControlResponse) GetDrain() *HostControlResponse_Drain {
+	if x, ok := x.GetOperation().(*HostControlResponse_Drain_); ok {
+		return x.Drain
+	}
+	return nil
+}
+
+type isHostControlResponse_Operation interface {
+	isHostControlResponse_Operation()
+}
+
+type HostControlResponse_Hello_ struct {
+	// Mandatory reply to the Hello message sent from the Host
+	Hello *HostControlResponse_Hello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+}
+
+type HostControlResponse_DataChannelRequest_ struct {
+	// Emitted when a Guest opens a new terminal channel
+	DataChannelRequest *HostControlResponse_DataChannelRequest `protobuf:"bytes,2,opt,name=data_channel_request,json=dataChannelRequest,proto3,oneof"`
+}
+
+type HostControlResponse_TunnelRequest_ struct {
+	// Emitted when a Guest opens a new tunnel channel
+	TunnelRequest *HostControlResponse_TunnelRequest `protobuf:"bytes,3,opt,name=tunnel_request,json=tunnelRequest,proto3,oneof"`
+}
+
+type HostControlResponse_FileTransferRequest_ struct {
+	// Emitted when a Guest opens a new file transfer channel
+	FileTransferRequest *HostControlResponse_FileTransferRequest `protobuf:"bytes,4,opt,name=file_transfer_request,json=fileTransferRequest,proto3,oneof"`
+}
+
+type HostControlResponse_Drain_ struct {
+	//
+	// Emitted when the server is about to shut down, the Host should re-connect (presumably to another server),
+	// while the existing sessions keep running on this server until they finish or the server stops
+	Drain *HostControlResponse_Drain `protobuf:"bytes,5,opt,name=drain,proto3,oneof"`
+}
+
+func (*HostControlResponse_Hello_) isHostControlResponse_Operation() {}
+
+func (*HostControlResponse_DataChannelRequest_) isHostControlResponse_Operation() {}
+
+func (*HostControlResponse_TunnelRequest_) isHostControlResponse_Operation() {}
+
+func (*HostControlResponse_FileTransferRequest_) isHostControlResponse_Operation() {}
+
+func (*HostControlResponse_Drain_) isHostControlResponse_Operation() {}
+
+type HostDataRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// Types that are assignable to Operation:
+	//	*HostDataRequest_Hello_
+	//	*HostDataRequest_Output
+	//	*HostDataRequest_ErrorOutput
+	//	*HostDataRequest_Termination
+	Operation isHostDataRequest_Operation `protobuf_oneof:"operation"`
 }
 
-func (x *Error) Reset() {
-	*x = Error{}
+func (x *HostDataRequest) Reset() {
+	*x = HostDataRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_terminal_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -616,13 +944,13 @@ func (x *Error) Reset() {
 	}
 }
 
-func (x *Error) String() string {
+func (x *HostDataRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Error) ProtoMessage() {}
+func (*HostDataRequest) ProtoMessage() {}
 
-func (x *Error) ProtoReflect() protoreflect.Message {
+func (x *HostDataRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_terminal_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -634,35 +962,93 @@ func (x *Error) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Error.ProtoReflect.Descriptor instead.
-func (*Error) Descriptor() ([]byte, []int) {
+// Deprecated: Use HostDataRequest.ProtoReflect.Descriptor instead.
+func (*HostDataRequest) Descriptor() ([]byte, []int) {
 	return file_terminal_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *Error) GetMessage() string {
-	if x != nil {
-		return x.Message
+func (m *HostDataRequest) GetOperation() isHostDataRequest_Operation {
+	if m != nil {
+		return m.Operation
 	}
-	return ""
+	return nil
 }
 
-type GuestTerminalRequest_Hello struct {
+func (x *HostDataRequest) GetHello() *HostDataRequest_Hello {
+	if x, ok := x.GetOperation().(*HostDataRequest_Hello_); ok {
+		return x.Hello
+	}
+	return nil
+}
+
+func (x *HostDataRequest) GetOutput() *Data {
+	if x, ok := x.GetOperation().(*HostDataRequest_Output); ok {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *HostDataRequest) GetErrorOutput() *Data {
+	if x, ok := x.GetOperation().(*HostDataRequest_ErrorOutput); ok {
+		return x.ErrorOutput
+	}
+	return nil
+}
+
+func (x *HostDataRequest) GetTermination() *Termination {
+	if x, ok := x.GetOperation().(*HostDataRequest_Termination); ok {
+		return x.Termination
+	}
+	return nil
+}
+
+type isHostDataRequest_Operation interface {
+	isHostDataRequest_Operation()
+}
+
+type HostDataRequest_Hello_ struct {
+	// Mandatory first message to be sent by the Host
+	Hello *HostDataRequest_Hello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+}
+
+type HostDataRequest_Output struct {
+	// Terminal output to the Guest
+	Output *Data `protobuf:"bytes,2,opt,name=output,proto3,oneof"`
+}
+
+type HostDataRequest_ErrorOutput struct {
+	// Error output of the command to the Guest, only sent for the commands without a PTY
+	ErrorOutput *Data `protobuf:"bytes,3,opt,name=error_output,json=errorOutput,proto3,oneof"`
+}
+
+type HostDataRequest_Termination struct {
+	// Sent once the session ends (e.g. when the shell or the command exits), no more messages will follow
+	Termination *Termination `protobuf:"bytes,4,opt,name=termination,proto3,oneof"`
+}
+
+func (*HostDataRequest_Hello_) isHostDataRequest_Operation() {}
+
+func (*HostDataRequest_Output) isHostDataRequest_Operation() {}
+
+func (*HostDataRequest_ErrorOutput) isHostDataRequest_Operation() {}
+
+func (*HostDataRequest_Termination) isHostDataRequest_Operation() {}
+
+type HostDataResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Unique Host identifier assigned by the HostService
-	Locator string `protobuf:"bytes,1,opt,name=locator,proto3" json:"locator,omitempty"`
-	//
-	// Symmetric key used to authenticate against a Host specified by the locator above,
-	// should match the Host's trusted_secret
-	Secret string `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
-	// Dimensions of the terminal to be created on the Host
-	RequestedDimensions *TerminalDimensions `protobuf:"bytes,3,opt,name=requested_dimensions,json=requestedDimensions,proto3" json:"requested_dimensions,omitempty"`
+	// Types that are assignable to Operation:
+	//	*HostDataResponse_ChangeDimensions
+	//	*HostDataResponse_Input
+	//	*HostDataResponse_CloseInput
+	//	*HostDataResponse_WindowUpdate
+	Operation isHostDataResponse_Operation `protobuf_oneof:"operation"`
 }
 
-func (x *GuestTerminalRequest_Hello) Reset() {
-	*x = GuestTerminalRequest_Hello{}
+func (x *HostDataResponse) Reset() {
+	*x = HostDataResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_terminal_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -670,13 +1056,13 @@ func (x *GuestTerminalRequest_Hello) Reset() {
 	}
 }
 
-func (x *GuestTerminalRequest_Hello) String() string {
+func (x *HostDataResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GuestTerminalRequest_Hello) ProtoMessage() {}
+func (*HostDataResponse) ProtoMessage() {}
 
-func (x *GuestTerminalRequest_Hello) ProtoReflect() protoreflect.Message {
+func (x *HostDataResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_terminal_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -688,43 +1074,89 @@ func (x *GuestTerminalRequest_Hello) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GuestTerminalRequest_Hello.ProtoReflect.Descriptor instead.
-func (*GuestTerminalRequest_Hello) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{0, 0}
+// Deprecated: Use HostDataResponse.ProtoReflect.Descriptor instead.
+func (*HostDataResponse) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *GuestTerminalRequest_Hello) GetLocator() string {
-	if x != nil {
-		return x.Locator
+func (m *HostDataResponse) GetOperation() isHostDataResponse_Operation {
+	if m != nil {
+		return m.Operation
 	}
-	return ""
+	return nil
 }
 
-func (x *GuestTerminalRequest_Hello) GetSecret() string {
-	if x != nil {
-		return x.Secret
+func (x *HostDataResponse) GetChangeDimensions() *TerminalDimensions {
+	if x, ok := x.GetOperation().(*HostDataResponse_ChangeDimensions); ok {
+		return x.ChangeDimensions
 	}
-	return ""
+	return nil
 }
 
-func (x *GuestTerminalRequest_Hello) GetRequestedDimensions() *TerminalDimensions {
-	if x != nil {
-		return x.RequestedDimensions
+func (x *HostDataResponse) GetInput() *Data {
+	if x, ok := x.GetOperation().(*HostDataResponse_Input); ok {
+		return x.Input
 	}
 	return nil
 }
 
-type HostControlRequest_Hello struct {
+func (x *HostDataResponse) GetCloseInput() bool {
+	if x, ok := x.GetOperation().(*HostDataResponse_CloseInput); ok {
+		return x.CloseInput
+	}
+	return false
+}
+
+func (x *HostDataResponse) GetWindowUpdate() uint64 {
+	if x, ok := x.GetOperation().(*HostDataResponse_WindowUpdate); ok {
+		return x.WindowUpdate
+	}
+	return 0
+}
+
+type isHostDataResponse_Operation interface {
+	isHostDataResponse_Operation()
+}
+
+type HostDataResponse_ChangeDimensions struct {
+	// Emitted when the Guest decides to change an already created terminal dimensions (e.g. when the web UI terminal size changes)
+	ChangeDimensions *TerminalDimensions `protobuf:"bytes,1,opt,name=change_dimensions,json=changeDimensions,proto3,oneof"`
+}
+
+type HostDataResponse_Input struct {
+	// Terminal input from the Guest
+	Input *Data `protobuf:"bytes,2,opt,name=input,proto3,oneof"`
+}
+
+type HostDataResponse_CloseInput struct {
+	// Emitted when the Guest signals the end of the input to the command
+	CloseInput bool `protobuf:"varint,3,opt,name=close_input,json=closeInput,proto3,oneof"`
+}
+
+type HostDataResponse_WindowUpdate struct {
+	// Number of the output bytes consumed by the server, which the Host can now send in addition
+	WindowUpdate uint64 `protobuf:"varint,4,opt,name=window_update,json=windowUpdate,proto3,oneof"`
+}
+
+func (*HostDataResponse_ChangeDimensions) isHostDataResponse_Operation() {}
+
+func (*HostDataResponse_Input) isHostDataResponse_Operation() {}
+
+func (*HostDataResponse_CloseInput) isHostDataResponse_Operation() {}
+
+func (*HostDataResponse_WindowUpdate) isHostDataResponse_Operation() {}
+
+type TerminalDimensions struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Symmetric key, the knowledge of which by the Guest can be used to spawn a new terminal on to this Host
-	TrustedSecret string `protobuf:"bytes,1,opt,name=trusted_secret,json=trustedSecret,proto3" json:"trusted_secret,omitempty"`
+	WidthColumns uint32 `protobuf:"varint,1,opt,name=width_columns,json=widthColumns,proto3" json:"width_columns,omitempty"`
+	HeightRows   uint32 `protobuf:"varint,2,opt,name=height_rows,json=heightRows,proto3" json:"height_rows,omitempty"`
 }
 
-func (x *HostControlRequest_Hello) Reset() {
-	*x = HostControlRequest_Hello{}
+func (x *TerminalDimensions) Reset() {
+	*x = TerminalDimensions{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_terminal_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -732,13 +1164,13 @@ func (x *HostControlRequest_Hello) Reset() {
 	}
 }
 
-func (x *HostControlRequest_Hello) String() string {
+func (x *TerminalDimensions) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HostControlRequest_Hello) ProtoMessage() {}
+func (*TerminalDimensions) ProtoMessage() {}
 
-func (x *HostControlRequest_Hello) ProtoReflect() protoreflect.Message {
+func (x *TerminalDimensions) ProtoReflect() protoreflect.Message {
 	mi := &file_terminal_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -750,29 +1182,39 @@ func (x *HostControlRequest_Hello) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HostControlRequest_Hello.ProtoReflect.Descriptor instead.
-func (*HostControlRequest_Hello) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{2, 0}
+// Deprecated: Use TerminalDimensions.ProtoReflect.Descriptor instead.
+func (*TerminalDimensions) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *HostControlRequest_Hello) GetTrustedSecret() string {
+func (x *TerminalDimensions) GetWidthColumns() uint32 {
 	if x != nil {
-		return x.TrustedSecret
+		return x.WidthColumns
 	}
-	return ""
+	return 0
 }
 
-type HostControlResponse_Hello struct {
+func (x *TerminalDimensions) GetHeightRows() uint32 {
+	if x != nil {
+		return x.HeightRows
+	}
+	return 0
+}
+
+type Data struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// A unique identifier that the HostService assigns to this Host
-	Locator string `protobuf:"bytes,1,opt,name=locator,proto3" json:"locator,omitempty"`
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	//
+	// Compression the data is compressed with, each message is compressed independently,
+	// only used for the output and only when the receiving side has announced its support
+	Compression Compression `protobuf:"varint,2,opt,name=compression,proto3,enum=Compression" json:"compression,omitempty"`
 }
 
-func (x *HostControlResponse_Hello) Reset() {
-	*x = HostControlResponse_Hello{}
+func (x *Data) Reset() {
+	*x = Data{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_terminal_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -780,13 +1222,13 @@ func (x *HostControlResponse_Hello) Reset() {
 	}
 }
 
-func (x *HostControlResponse_Hello) String() string {
+func (x *Data) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HostControlResponse_Hello) ProtoMessage() {}
+func (*Data) ProtoMessage() {}
 
-func (x *HostControlResponse_Hello) ProtoReflect() protoreflect.Message {
+func (x *Data) ProtoReflect() protoreflect.Message {
 	mi := &file_terminal_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -798,31 +1240,40 @@ func (x *HostControlResponse_Hello) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HostControlResponse_Hello.ProtoReflect.Descriptor instead.
-func (*HostControlResponse_Hello) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{3, 0}
+// Deprecated: Use Data.ProtoReflect.Descriptor instead.
+func (*Data) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *HostControlResponse_Hello) GetLocator() string {
+func (x *Data) GetData() []byte {
 	if x != nil {
-		return x.Locator
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-type HostControlResponse_DataChannelRequest struct {
+func (x *Data) GetCompression() Compression {
+	if x != nil {
+		return x.Compression
+	}
+	return Compression_COMPRESSION_NONE
+}
+
+type Command struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Token that can be used to create a new data channel
-	Token string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
-	// Dimensions of the new terminal that will be created and attached to the data channel
-	RequestedDimensions *TerminalDimensions `protobuf:"bytes,3,opt,name=requested_dimensions,json=requestedDimensions,proto3" json:"requested_dimensions,omitempty"`
+	// Program to run, looked up in the PATH if it contains no path separators
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Arguments to pass to the program
+	Args []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	// Whether to attach the program to a PTY, in which case the error output is merged into the output
+	Pty bool `protobuf:"varint,3,opt,name=pty,proto3" json:"pty,omitempty"`
 }
 
-func (x *HostControlResponse_DataChannelRequest) Reset() {
-	*x = HostControlResponse_DataChannelRequest{}
+func (x *Command) Reset() {
+	*x = Command{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_terminal_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -830,270 +1281,3130 @@ func (x *HostControlResponse_DataChannelRequest) Reset() {
 	}
 }
 
-func (x *HostControlResponse_DataChannelRequest) String() string {
+func (x *Command) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HostControlResponse_DataChannelRequest) ProtoMessage() {}
+func (*Command) ProtoMessage() {}
 
-func (x *HostControlResponse_DataChannelRequest) ProtoReflect() protoreflect.Message {
+func (x *Command) ProtoReflect() protoreflect.Message {
 	mi := &file_terminal_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
 		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use HostControlResponse_DataChannelRequest.ProtoReflect.Descriptor instead.
-func (*HostControlResponse_DataChannelRequest) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{3, 1}
-}
-
-func (x *HostControlResponse_DataChannelRequest) GetToken() string {
-	if x != nil {
-		return x.Token
-	}
-	return ""
-}
-
-func (x *HostControlResponse_DataChannelRequest) GetRequestedDimensions() *TerminalDimensions {
-	if x != nil {
-		return x.RequestedDimensions
-	}
-	return nil
-}
-
-type HostDataRequest_Hello struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	// Host's locator
-	Locator string `protobuf:"bytes,1,opt,name=locator,proto3" json:"locator,omitempty"`
-	// Token provided to the Host in DataChannelRequest
-	Token string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
-}
-
-func (x *HostDataRequest_Hello) Reset() {
-	*x = HostDataRequest_Hello{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[13]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
-}
-
-func (x *HostDataRequest_Hello) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*HostDataRequest_Hello) ProtoMessage() {}
-
-func (x *HostDataRequest_Hello) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[13]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Command.ProtoReflect.Descriptor instead.
+func (*Command) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Command) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Command) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *Command) GetPty() bool {
+	if x != nil {
+		return x.Pty
+	}
+	return false
+}
+
+type GuestTunnelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Operation:
+	//	*GuestTunnelRequest_Hello_
+	//	*GuestTunnelRequest_Data
+	Operation isGuestTunnelRequest_Operation `protobuf_oneof:"operation"`
+}
+
+func (x *GuestTunnelRequest) Reset() {
+	*x = GuestTunnelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GuestTunnelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GuestTunnelRequest) ProtoMessage() {}
+
+func (x *GuestTunnelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GuestTunnelRequest.ProtoReflect.Descriptor instead.
+func (*GuestTunnelRequest) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{13}
+}
+
+func (m *GuestTunnelRequest) GetOperation() isGuestTunnelRequest_Operation {
+	if m != nil {
+		return m.Operation
+	}
+	return nil
+}
+
+func (x *GuestTunnelRequest) GetHello() *GuestTunnelRequest_Hello {
+	if x, ok := x.GetOperation().(*GuestTunnelRequest_Hello_); ok {
+		return x.Hello
+	}
+	return nil
+}
+
+func (x *GuestTunnelRequest) GetData() *Data {
+	if x, ok := x.GetOperation().(*GuestTunnelRequest_Data); ok {
+		return x.Data
+	}
+	return nil
+}
+
+type isGuestTunnelRequest_Operation interface {
+	isGuestTunnelRequest_Operation()
+}
+
+type GuestTunnelRequest_Hello_ struct {
+	// Mandatory first message from a Guest after it opens this channel
+	Hello *GuestTunnelRequest_Hello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+}
+
+type GuestTunnelRequest_Data struct {
+	// Data to write to the tunneled connection, the end of data is signalled by closing the stream
+	Data *Data `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+}
+
+func (*GuestTunnelRequest_Hello_) isGuestTunnelRequest_Operation() {}
+
+func (*GuestTunnelRequest_Data) isGuestTunnelRequest_Operation() {}
+
+type GuestTunnelResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Operation:
+	//	*GuestTunnelResponse_Hello_
+	//	*GuestTunnelResponse_Data
+	//	*GuestTunnelResponse_CloseWrite
+	Operation isGuestTunnelResponse_Operation `protobuf_oneof:"operation"`
+}
+
+func (x *GuestTunnelResponse) Reset() {
+	*x = GuestTunnelResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GuestTunnelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GuestTunnelResponse) ProtoMessage() {}
+
+func (x *GuestTunnelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GuestTunnelResponse.ProtoReflect.Descriptor instead.
+func (*GuestTunnelResponse) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{14}
+}
+
+func (m *GuestTunnelResponse) GetOperation() isGuestTunnelResponse_Operation {
+	if m != nil {
+		return m.Operation
+	}
+	return nil
+}
+
+func (x *GuestTunnelResponse) GetHello() *GuestTunnelResponse_Hello {
+	if x, ok := x.GetOperation().(*GuestTunnelResponse_Hello_); ok {
+		return x.Hello
+	}
+	return nil
+}
+
+func (x *GuestTunnelResponse) GetData() *Data {
+	if x, ok := x.GetOperation().(*GuestTunnelResponse_Data); ok {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *GuestTunnelResponse) GetCloseWrite() bool {
+	if x, ok := x.GetOperation().(*GuestTunnelResponse_CloseWrite); ok {
+		return x.CloseWrite
+	}
+	return false
+}
+
+type isGuestTunnelResponse_Operation interface {
+	isGuestTunnelResponse_Operation()
+}
+
+type GuestTunnelResponse_Hello_ struct {
+	// Sent once the Host has connected to the requested port
+	Hello *GuestTunnelResponse_Hello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+}
+
+type GuestTunnelResponse_Data struct {
+	// Data read from the tunneled connection
+	Data *Data `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+}
+
+type GuestTunnelResponse_CloseWrite struct {
+	// Signals that no more data will be read from the tunneled connection
+	CloseWrite bool `protobuf:"varint,3,opt,name=close_write,json=closeWrite,proto3,oneof"`
+}
+
+func (*GuestTunnelResponse_Hello_) isGuestTunnelResponse_Operation() {}
+
+func (*GuestTunnelResponse_Data) isGuestTunnelResponse_Operation() {}
+
+func (*GuestTunnelResponse_CloseWrite) isGuestTunnelResponse_Operation() {}
+
+type HostTunnelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Operation:
+	//	*HostTunnelRequest_Hello_
+	//	*HostTunnelRequest_Data
+	Operation isHostTunnelRequest_Operation `protobuf_oneof:"operation"`
+}
+
+func (x *HostTunnelRequest) Reset() {
+	*x = HostTunnelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostTunnelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostTunnelRequest) ProtoMessage() {}
+
+func (x *HostTunnelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostTunnelRequest.ProtoReflect.Descriptor instead.
+func (*HostTunnelRequest) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{15}
+}
+
+func (m *HostTunnelRequest) GetOperation() isHostTunnelRequest_Operation {
+	if m != nil {
+		return m.Operation
+	}
+	return nil
+}
+
+func (x *HostTunnelRequest) GetHello() *HostTunnelRequest_Hello {
+	if x, ok := x.GetOperation().(*HostTunnelRequest_Hello_); ok {
+		return x.Hello
+	}
+	return nil
+}
+
+func (x *HostTunnelRequest) GetData() *Data {
+	if x, ok := x.GetOperation().(*HostTunnelRequest_Data); ok {
+		return x.Data
+	}
+	return nil
+}
+
+type isHostTunnelRequest_Operation interface {
+	isHostTunnelRequest_Operation()
+}
+
+type HostTunnelRequest_Hello_ struct {
+	// Mandatory first message to be sent by the Host
+	Hello *HostTunnelRequest_Hello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+}
+
+type HostTunnelRequest_Data struct {
+	// Data read from the tunneled connection, the end of data is signalled by closing the stream
+	Data *Data `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+}
+
+func (*HostTunnelRequest_Hello_) isHostTunnelRequest_Operation() {}
+
+func (*HostTunnelRequest_Data) isHostTunnelRequest_Operation() {}
+
+type HostTunnelResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Operation:
+	//	*HostTunnelResponse_Data
+	//	*HostTunnelResponse_CloseWrite
+	Operation isHostTunnelResponse_Operation `protobuf_oneof:"operation"`
+}
+
+func (x *HostTunnelResponse) Reset() {
+	*x = HostTunnelResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostTunnelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostTunnelResponse) ProtoMessage() {}
+
+func (x *HostTunnelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostTunnelResponse.ProtoReflect.Descriptor instead.
+func (*HostTunnelResponse) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{16}
+}
+
+func (m *HostTunnelResponse) GetOperation() isHostTunnelResponse_Operation {
+	if m != nil {
+		return m.Operation
+	}
+	return nil
+}
+
+func (x *HostTunnelResponse) GetData() *Data {
+	if x, ok := x.GetOperation().(*HostTunnelResponse_Data); ok {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *HostTunnelResponse) GetCloseWrite() bool {
+	if x, ok := x.GetOperation().(*HostTunnelResponse_CloseWrite); ok {
+		return x.CloseWrite
+	}
+	return false
+}
+
+type isHostTunnelResponse_Operation interface {
+	isHostTunnelResponse_Operation()
+}
+
+type HostTunnelResponse_Data struct {
+	// Data to write to the tunneled connection
+	Data *Data `protobuf:"bytes,1,opt,name=data,proto3,oneof"`
+}
+
+type HostTunnelResponse_CloseWrite struct {
+	// Signals that the Guest won't send any more data
+	CloseWrite bool `protobuf:"varint,2,opt,name=close_write,json=closeWrite,proto3,oneof"`
+}
+
+func (*HostTunnelResponse_Data) isHostTunnelResponse_Operation() {}
+
+func (*HostTunnelResponse_CloseWrite) isHostTunnelResponse_Operation() {}
+
+type FileTransfer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Direction FileTransfer_Direction `protobuf:"varint,1,opt,name=direction,proto3,enum=FileTransfer_Direction" json:"direction,omitempty"`
+	// Absolute path of the file on the Host, should be inside of one of the Host's root directories
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// Size of the uploaded file
+	Size uint64 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	// Permission bits of the uploaded file
+	Mode uint32 `protobuf:"varint,4,opt,name=mode,proto3" json:"mode,omitempty"`
+}
+
+func (x *FileTransfer) Reset() {
+	*x = FileTransfer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FileTransfer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileTransfer) ProtoMessage() {}
+
+func (x *FileTransfer) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileTransfer.ProtoReflect.Descriptor instead.
+func (*FileTransfer) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *FileTransfer) GetDirection() FileTransfer_Direction {
+	if x != nil {
+		return x.Direction
+	}
+	return FileTransfer_DIRECTION_UNSPECIFIED
+}
+
+func (x *FileTransfer) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileTransfer) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileTransfer) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+type FileHeader struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Size of the downloaded file
+	Size uint64 `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	// Permission bits of the downloaded file
+	Mode uint32 `protobuf:"varint,2,opt,name=mode,proto3" json:"mode,omitempty"`
+}
+
+func (x *FileHeader) Reset() {
+	*x = FileHeader{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FileHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileHeader) ProtoMessage() {}
+
+func (x *FileHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileHeader.ProtoReflect.Descriptor instead.
+func (*FileHeader) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *FileHeader) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileHeader) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+type FileTrailer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// SHA-256 checksum of the file contents
+	Sha256 []byte `protobuf:"bytes,1,opt,name=sha256,proto3" json:"sha256,omitempty"`
+}
+
+func (x *FileTrailer) Reset() {
+	*x = FileTrailer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FileTrailer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileTrailer) ProtoMessage() {}
+
+func (x *FileTrailer) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileTrailer.ProtoReflect.Descriptor instead.
+func (*FileTrailer) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *FileTrailer) GetSha256() []byte {
+	if x != nil {
+		return x.Sha256
+	}
+	return nil
+}
+
+type GuestFileTransferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Operation:
+	//	*GuestFileTransferRequest_Hello_
+	//	*GuestFileTransferRequest_Chunk
+	//	*GuestFileTransferRequest_Trailer
+	Operation isGuestFileTransferRequest_Operation `protobuf_oneof:"operation"`
+}
+
+func (x *GuestFileTransferRequest) Reset() {
+	*x = GuestFileTransferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GuestFileTransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GuestFileTransferRequest) ProtoMessage() {}
+
+func (x *GuestFileTransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GuestFileTransferRequest.ProtoReflect.Descriptor instead.
+func (*GuestFileTransferRequest) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{20}
+}
+
+func (m *GuestFileTransferRequest) GetOperation() isGuestFileTransferRequest_Operation {
+	if m != nil {
+		return m.Operation
+	}
+	return nil
+}
+
+func (x *GuestFileTransferRequest) GetHello() *GuestFileTransferRequest_Hello {
+	if x, ok := x.GetOperation().(*GuestFileTransferRequest_Hello_); ok {
+		return x.Hello
+	}
+	return nil
+}
+
+func (x *GuestFileTransferRequest) GetChunk() *Data {
+	if x, ok := x.GetOperation().(*GuestFileTransferRequest_Chunk); ok {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *GuestFileTransferRequest) GetTrailer() *FileTrailer {
+	if x, ok := x.GetOperation().(*GuestFileTransferRequest_Trailer); ok {
+		return x.Trailer
+	}
+	return nil
+}
+
+type isGuestFileTransferRequest_Operation interface {
+	isGuestFileTransferRequest_Operation()
+}
+
+type GuestFileTransferRequest_Hello_ struct {
+	// Mandatory first message from a Guest after it opens this channel
+	Hello *GuestFileTransferRequest_Hello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+}
+
+type GuestFileTransferRequest_Chunk struct {
+	// Chunk of the uploaded file
+	Chunk *Data `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+type GuestFileTransferRequest_Trailer struct {
+	// Sent after the last chunk of the uploaded file
+	Trailer *FileTrailer `protobuf:"bytes,3,opt,name=trailer,proto3,oneof"`
+}
+
+func (*GuestFileTransferRequest_Hello_) isGuestFileTransferRequest_Operation() {}
+
+func (*GuestFileTransferRequest_Chunk) isGuestFileTransferRequest_Operation() {}
+
+func (*GuestFileTransferRequest_Trailer) isGuestFileTransferRequest_Operation() {}
+
+type GuestFileTransferResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Operation:
+	//	*GuestFileTransferResponse_Header
+	//	*GuestFileTransferResponse_Chunk
+	//	*GuestFileTransferResponse_Trailer
+	Operation isGuestFileTransferResponse_Operation `protobuf_oneof:"operation"`
+}
+
+func (x *GuestFileTransferResponse) Reset() {
+	*x = GuestFileTransferResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GuestFileTransferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GuestFileTransferResponse) ProtoMessage() {}
+
+func (x *GuestFileTransferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GuestFileTransferResponse.ProtoReflect.Descriptor instead.
+func (*GuestFileTransferResponse) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{21}
+}
+
+func (m *GuestFileTransferResponse) GetOperation() isGuestFileTransferResponse_Operation {
+	if m != nil {
+		return m.Operation
+	}
+	return nil
+}
+
+func (x *GuestFileTransferResponse) GetHeader() *FileHeader {
+	if x, ok := x.GetOperation().(*GuestFileTransferResponse_Header); ok {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *GuestFileTransferResponse) GetChunk() *Data {
+	if x, ok := x.GetOperation().(*GuestFileTransferResponse_Chunk); ok {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *GuestFileTransferResponse) GetTrailer() *FileTrailer {
+	if x, ok := x.GetOperation().(*GuestFileTransferResponse_Trailer); ok {
+		return x.Trailer
+	}
+	return nil
+}
+
+type isGuestFileTransferResponse_Operation interface {
+	isGuestFileTransferResponse_Operation()
+}
+
+type GuestFileTransferResponse_Header struct {
+	// Sent before the first chunk of the downloaded file
+	Header *FileHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type GuestFileTransferResponse_Chunk struct {
+	// Chunk of the downloaded file
+	Chunk *Data `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+type GuestFileTransferResponse_Trailer struct {
+	// Sent after the last chunk of the downloaded file, or once the uploaded file is stored on the Host
+	Trailer *FileTrailer `protobuf:"bytes,3,opt,name=trailer,proto3,oneof"`
+}
+
+func (*GuestFileTransferResponse_Header) isGuestFileTransferResponse_Operation() {}
+
+func (*GuestFileTransferResponse_Chunk) isGuestFileTransferResponse_Operation() {}
+
+func (*GuestFileTransferResponse_Trailer) isGuestFileTransferResponse_Operation() {}
+
+type HostFileTransferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Operation:
+	//	*HostFileTransferRequest_Hello_
+	//	*HostFileTransferRequest_Header
+	//	*HostFileTransferRequest_Chunk
+	//	*HostFileTransferRequest_Trailer
+	//	*HostFileTransferRequest_Error
+	Operation isHostFileTransferRequest_Operation `protobuf_oneof:"operation"`
+}
+
+func (x *HostFileTransferRequest) Reset() {
+	*x = HostFileTransferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostFileTransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostFileTransferRequest) ProtoMessage() {}
+
+func (x *HostFileTransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostFileTransferRequest.ProtoReflect.Descriptor instead.
+func (*HostFileTransferRequest) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{22}
+}
+
+func (m *HostFileTransferRequest) GetOperation() isHostFileTransferRequest_Operation {
+	if m != nil {
+		return m.Operation
+	}
+	return nil
+}
+
+func (x *HostFileTransferRequest) GetHello() *HostFileTransferRequest_Hello {
+	if x, ok := x.GetOperation().(*HostFileTransferRequest_Hello_); ok {
+		return x.Hello
+	}
+	return nil
+}
+
+func (x *HostFileTransferRequest) GetHeader() *FileHeader {
+	if x, ok := x.GetOperation().(*HostFileTransferRequest_Header); ok {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *HostFileTransferRequest) GetChunk() *Data {
+	if x, ok := x.GetOperation().(*HostFileTransferRequest_Chunk); ok {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *HostFileTransferRequest) GetTrailer() *FileTrailer {
+	if x, ok := x.GetOperation().(*HostFileTransferRequest_Trailer); ok {
+		return x.Trailer
+	}
+	return nil
+}
+
+func (x *HostFileTransferRequest) GetError() *Error {
+	if x, ok := x.GetOperation().(*HostFileTransferRequest_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+type isHostFileTransferRequest_Operation interface {
+	isHostFileTransferRequest_Operation()
+}
+
+type HostFileTransferRequest_Hello_ struct {
+	// Mandatory first message to be sent by the Host
+	Hello *HostFileTransferRequest_Hello `protobuf:"bytes,1,opt,name=hello,proto3,oneof"`
+}
+
+type HostFileTransferRequest_Header struct {
+	// Sent before the first chunk of the downloaded file
+	Header *FileHeader `protobuf:"bytes,2,opt,name=header,proto3,oneof"`
+}
+
+type HostFileTransferRequest_Chunk struct {
+	// Chunk of the downloaded file
+	Chunk *Data `protobuf:"bytes,3,opt,name=chunk,proto3,oneof"`
+}
+
+type HostFileTransferRequest_Trailer struct {
+	// Sent after the last chunk of the downloaded file, or once the uploaded file is stored
+	Trailer *FileTrailer `protobuf:"bytes,4,opt,name=trailer,proto3,oneof"`
+}
+
+type HostFileTransferRequest_Error struct {
+	// Sent when the file transfer has failed midway
+	Error *Error `protobuf:"bytes,5,opt,name=error,proto3,oneof"`
+}
+
+func (*HostFileTransferRequest_Hello_) isHostFileTransferRequest_Operation() {}
+
+func (*HostFileTransferRequest_Header) isHostFileTransferRequest_Operation() {}
+
+func (*HostFileTransferRequest_Chunk) isHostFileTransferRequest_Operation() {}
+
+func (*HostFileTransferRequest_Trailer) isHostFileTransferRequest_Operation() {}
+
+func (*HostFileTransferRequest_Error) isHostFileTransferRequest_Operation() {}
+
+type HostFileTransferResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Operation:
+	//	*HostFileTransferResponse_Chunk
+	//	*HostFileTransferResponse_Trailer
+	Operation isHostFileTransferResponse_Operation `protobuf_oneof:"operation"`
+}
+
+func (x *HostFileTransferResponse) Reset() {
+	*x = HostFileTransferResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostFileTransferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostFileTransferResponse) ProtoMessage() {}
+
+func (x *HostFileTransferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostFileTransferResponse.ProtoReflect.Descriptor instead.
+func (*HostFileTransferResponse) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{23}
+}
+
+func (m *HostFileTransferResponse) GetOperation() isHostFileTransferResponse_Operation {
+	if m != nil {
+		return m.Operation
+	}
+	return nil
+}
+
+func (x *HostFileTransferResponse) GetChunk() *Data {
+	if x, ok := x.GetOperation().(*HostFileTransferResponse_Chunk); ok {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *HostFileTransferResponse) GetTrailer() *FileTrailer {
+	if x, ok := x.GetOperation().(*HostFileTransferResponse_Trailer); ok {
+		return x.Trailer
+	}
+	return nil
+}
+
+type isHostFileTransferResponse_Operation interface {
+	isHostFileTransferResponse_Operation()
+}
+
+type HostFileTransferResponse_Chunk struct {
+	// Chunk of the uploaded file
+	Chunk *Data `protobuf:"bytes,1,opt,name=chunk,proto3,oneof"`
+}
+
+type HostFileTransferResponse_Trailer struct {
+	// Sent after the last chunk of the uploaded file
+	Trailer *FileTrailer `protobuf:"bytes,2,opt,name=trailer,proto3,oneof"`
+}
+
+func (*HostFileTransferResponse_Chunk) isHostFileTransferResponse_Operation() {}
+
+func (*HostFileTransferResponse_Trailer) isHostFileTransferResponse_Operation() {}
+
+type ShellOverride struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Shell to run instead of the Host's default one, should be in the Host's allowlist
+	Shell string `protobuf:"bytes,1,opt,name=shell,proto3" json:"shell,omitempty"`
+	// Arguments to pass to the shell
+	Args []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	// Directory to start the shell or the command in, should be in the Host's allowlist
+	WorkingDirectory string `protobuf:"bytes,3,opt,name=working_directory,json=workingDirectory,proto3" json:"working_directory,omitempty"`
+}
+
+func (x *ShellOverride) Reset() {
+	*x = ShellOverride{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShellOverride) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShellOverride) ProtoMessage() {}
+
+func (x *ShellOverride) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShellOverride.ProtoReflect.Descriptor instead.
+func (*ShellOverride) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ShellOverride) GetShell() string {
+	if x != nil {
+		return x.Shell
+	}
+	return ""
+}
+
+func (x *ShellOverride) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ShellOverride) GetWorkingDirectory() string {
+	if x != nil {
+		return x.WorkingDirectory
+	}
+	return ""
+}
+
+type ExitStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Exit code of the shell or the command, -1 if it was killed by a signal
+	Code int32 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Name of the signal that killed the shell or the command without the "SIG" prefix (e.g. "KILL"), if any
+	Signal string `protobuf:"bytes,2,opt,name=signal,proto3" json:"signal,omitempty"`
+}
+
+func (x *ExitStatus) Reset() {
+	*x = ExitStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExitStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExitStatus) ProtoMessage() {}
+
+func (x *ExitStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExitStatus.ProtoReflect.Descriptor instead.
+func (*ExitStatus) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ExitStatus) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ExitStatus) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+type Termination struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reason Termination_Reason `protobuf:"varint,1,opt,name=reason,proto3,enum=Termination_Reason" json:"reason,omitempty"`
+	// Only set when the reason is SHELL_EXITED
+	ExitStatus *ExitStatus `protobuf:"bytes,2,opt,name=exit_status,json=exitStatus,proto3" json:"exit_status,omitempty"`
+	// Human-readable details, if any
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *Termination) Reset() {
+	*x = Termination{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Termination) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Termination) ProtoMessage() {}
+
+func (x *Termination) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Termination.ProtoReflect.Descriptor instead.
+func (*Termination) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Termination) GetReason() Termination_Reason {
+	if x != nil {
+		return x.Reason
+	}
+	return Termination_REASON_UNSPECIFIED
+}
+
+func (x *Termination) GetExitStatus() *ExitStatus {
+	if x != nil {
+		return x.ExitStatus
+	}
+	return nil
+}
+
+func (x *Termination) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type Error struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GuestTerminalRequest_Hello struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Unique Host identifier assigned by the HostService
+	Locator string `protobuf:"bytes,1,opt,name=locator,proto3" json:"locator,omitempty"`
+	//
+	// Symmetric key used to authenticate against a Host specified by the locator above,
+	// should match the Host's trusted_secret
+	Secret string `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	// Dimensions of the terminal to be created on the Host
+	RequestedDimensions *TerminalDimensions `protobuf:"bytes,3,opt,name=requested_dimensions,json=requestedDimensions,proto3" json:"requested_dimensions,omitempty"`
+	//
+	// Join an existing session with the specified ID instead of creating a new one,
+	// the joined Guest can only observe the terminal output
+	SessionId string `protobuf:"bytes,4,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	//
+	// Resume the session previously started by this Guest instead of creating a new one,
+	// should match the resume_token received in the GuestTerminalResponse's Hello
+	ResumeToken string `protobuf:"bytes,5,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	// Offset in the terminal output (number of bytes received so far) to resume from
+	ResumeOffset uint64 `protobuf:"varint,6,opt,name=resume_offset,json=resumeOffset,proto3" json:"resume_offset,omitempty"`
+	// Run the specified command non-interactively instead of the shell
+	Command *Command `protobuf:"bytes,7,opt,name=command,proto3" json:"command,omitempty"`
+	// Run a different shell or start it in a different directory, subject to the Host's allowlist
+	ShellOverride *ShellOverride `protobuf:"bytes,8,opt,name=shell_override,json=shellOverride,proto3" json:"shell_override,omitempty"`
+	// Compressions the Guest can decompress the output with, in the order of preference
+	AcceptedCompressions []Compression `protobuf:"varint,9,rep,packed,name=accepted_compressions,json=acceptedCompressions,proto3,enum=Compression" json:"accepted_compressions,omitempty"`
+	// Protocol version the Guest implements, zero for the Guests predating the versioning
+	ProtocolVersion uint32 `protobuf:"varint,10,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	// Optional protocol features the Guest supports (e.g. "output-truncated")
+	Capabilities []string `protobuf:"bytes,11,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	//
+	// Credentials to use instead of the secret (e.g. a signed JWT), which are
+	// validated by the server's authenticator rather than by the Host's secrets
+	Token string `protobuf:"bytes,12,opt,name=token,proto3" json:"token,omitempty"`
+}
+
+func (x *GuestTerminalRequest_Hello) Reset() {
+	*x = GuestTerminalRequest_Hello{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GuestTerminalRequest_Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GuestTerminalRequest_Hello) ProtoMessage() {}
+
+func (x *GuestTerminalRequest_Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GuestTerminalRequest_Hello.ProtoReflect.Descriptor instead.
+func (*GuestTerminalRequest_Hello) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{0, 0}
+}
+
+func (x *GuestTerminalRequest_Hello) GetLocator() string {
+	if x != nil {
+		return x.Locator
+	}
+	return ""
+}
+
+func (x *GuestTerminalRequest_Hello) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *GuestTerminalRequest_Hello) GetRequestedDimensions() *TerminalDimensions {
+	if x != nil {
+		return x.RequestedDimensions
+	}
+	return nil
+}
+
+func (x *GuestTerminalRequest_Hello) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *GuestTerminalRequest_Hello) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *GuestTerminalRequest_Hello) GetResumeOffset() uint64 {
+	if x != nil {
+		return x.ResumeOffset
+	}
+	return 0
+}
+
+func (x *GuestTerminalRequest_Hello) GetCommand() *Command {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *GuestTerminalRequest_Hello) GetShellOverride() *ShellOverride {
+	if x != nil {
+		return x.ShellOverride
+	}
+	return nil
+}
+
+func (x *GuestTerminalRequest_Hello) GetAcceptedCompressions() []Compression {
+	if x != nil {
+		return x.AcceptedCompressions
+	}
+	return nil
+}
+
+func (x *GuestTerminalRequest_Hello) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *GuestTerminalRequest_Hello) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *GuestTerminalRequest_Hello) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type GuestTerminalResponse_Hello struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Session identifier that other Guests can use to join this session
+	SessionId string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Whether the terminal input and dimension changes from this Guest will be ignored
+	ReadOnly bool `protobuf:"varint,2,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	//
+	// Token that can be used to resume this session after the Guest gets disconnected,
+	// only sent to the Guest that is in control of the session
+	ResumeToken string `protobuf:"bytes,3,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	// Offset in the terminal output of the first byte following this message
+	OutputOffset uint64 `protobuf:"varint,4,opt,name=output_offset,json=outputOffset,proto3" json:"output_offset,omitempty"`
+	// SHA-256 hash of the session token that identifies the session recording, only sent when the server records sessions
+	RecordingId string `protobuf:"bytes,5,opt,name=recording_id,json=recordingId,proto3" json:"recording_id,omitempty"`
+	// Compression the server will use for the output sent to this Guest, chosen from the accepted_compressions
+	Compression Compression `protobuf:"varint,6,opt,name=compression,proto3,enum=Compression" json:"compression,omitempty"`
+	// Protocol version the server implements
+	ProtocolVersion uint32 `protobuf:"varint,7,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	// Capabilities from the Hello message that the server supports too
+	Capabilities []string `protobuf:"bytes,8,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	// Capabilities supported by both the Host and the server (e.g. "tunnel"), which tell what the Guest can request
+	HostCapabilities []string `protobuf:"bytes,9,rep,name=host_capabilities,json=hostCapabilities,proto3" json:"host_capabilities,omitempty"`
+}
+
+func (x *GuestTerminalResponse_Hello) Reset() {
+	*x = GuestTerminalResponse_Hello{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GuestTerminalResponse_Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GuestTerminalResponse_Hello) ProtoMessage() {}
+
+func (x *GuestTerminalResponse_Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GuestTerminalResponse_Hello.ProtoReflect.Descriptor instead.
+func (*GuestTerminalResponse_Hello) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{1, 0}
+}
+
+func (x *GuestTerminalResponse_Hello) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *GuestTerminalResponse_Hello) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+func (x *GuestTerminalResponse_Hello) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *GuestTerminalResponse_Hello) GetOutputOffset() uint64 {
+	if x != nil {
+		return x.OutputOffset
+	}
+	return 0
+}
+
+func (x *GuestTerminalResponse_Hello) GetRecordingId() string {
+	if x != nil {
+		return x.RecordingId
+	}
+	return ""
+}
+
+func (x *GuestTerminalResponse_Hello) GetCompression() Compression {
+	if x != nil {
+		return x.Compression
+	}
+	return Compression_COMPRESSION_NONE
+}
+
+func (x *GuestTerminalResponse_Hello) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *GuestTerminalResponse_Hello) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *GuestTerminalResponse_Hello) GetHostCapabilities() []string {
+	if x != nil {
+		return x.HostCapabilities
+	}
+	return nil
+}
+
+type HostControlRequest_Hello struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Symmetric key, the knowledge of which by the Guest can be used to spawn a new terminal on to this Host
+	TrustedSecret string `protobuf:"bytes,1,opt,name=trusted_secret,json=trustedSecret,proto3" json:"trusted_secret,omitempty"`
+	//
+	// Locator previously assigned to this Host, set when re-connecting
+	// to keep the already shared terminal links working
+	Locator string `protobuf:"bytes,2,opt,name=locator,proto3" json:"locator,omitempty"`
+	// Proof of the locator ownership previously received in the HostControlResponse's Hello
+	LocatorProof string `protobuf:"bytes,3,opt,name=locator_proof,json=locatorProof,proto3" json:"locator_proof,omitempty"`
+	// Symmetric key, the knowledge of which by the Guest only allows to observe the existing sessions
+	ReadOnlySecret string `protobuf:"bytes,4,opt,name=read_only_secret,json=readOnlySecret,proto3" json:"read_only_secret,omitempty"`
+	// Protocol version the Host implements, zero for the Hosts predating the versioning
+	ProtocolVersion uint32 `protobuf:"varint,5,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	// Optional protocol features the Host supports (e.g. "tunnel"), the server won't request the others
+	Capabilities []string `protobuf:"bytes,6,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+}
+
+func (x *HostControlRequest_Hello) Reset() {
+	*x = HostControlRequest_Hello{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostControlRequest_Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostControlRequest_Hello) ProtoMessage() {}
+
+func (x *HostControlRequest_Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostControlRequest_Hello.ProtoReflect.Descriptor instead.
+func (*HostControlRequest_Hello) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{6, 0}
+}
+
+func (x *HostControlRequest_Hello) GetTrustedSecret() string {
+	if x != nil {
+		return x.TrustedSecret
+	}
+	return ""
+}
+
+func (x *HostControlRequest_Hello) GetLocator() string {
+	if x != nil {
+		return x.Locator
+	}
+	return ""
+}
+
+func (x *HostControlRequest_Hello) GetLocatorProof() string {
+	if x != nil {
+		return x.LocatorProof
+	}
+	return ""
+}
+
+func (x *HostControlRequest_Hello) GetReadOnlySecret() string {
+	if x != nil {
+		return x.ReadOnlySecret
+	}
+	return ""
+}
+
+func (x *HostControlRequest_Hello) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *HostControlRequest_Hello) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+type HostControlResponse_Hello struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// A unique identifier that the HostService assigns to this Host
+	Locator string `protobuf:"bytes,1,opt,name=locator,proto3" json:"locator,omitempty"`
+	// Secret value that lets the Host to re-claim the same locator when re-connecting
+	LocatorProof string `protobuf:"bytes,2,opt,name=locator_proof,json=locatorProof,proto3" json:"locator_proof,omitempty"`
+	// Protocol version the server implements
+	ProtocolVersion uint32 `protobuf:"varint,3,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	// Capabilities from the Hello message that the server supports too
+	Capabilities []string `protobuf:"bytes,4,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+}
+
+func (x *HostControlResponse_Hello) Reset() {
+	*x = HostControlResponse_Hello{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostControlResponse_Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostControlResponse_Hello) ProtoMessage() {}
+
+func (x *HostControlResponse_Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostControlResponse_Hello.ProtoReflect.Descriptor instead.
+func (*HostControlResponse_Hello) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{7, 0}
+}
+
+func (x *HostControlResponse_Hello) GetLocator() string {
+	if x != nil {
+		return x.Locator
+	}
+	return ""
+}
+
+func (x *HostControlResponse_Hello) GetLocatorProof() string {
+	if x != nil {
+		return x.LocatorProof
+	}
+	return ""
+}
+
+func (x *HostControlResponse_Hello) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *HostControlResponse_Hello) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+type HostControlResponse_DataChannelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Token that can be used to create a new data channel
+	Token string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// Dimensions of the new terminal that will be created and attached to the data channel
+	RequestedDimensions *TerminalDimensions `protobuf:"bytes,3,opt,name=requested_dimensions,json=requestedDimensions,proto3" json:"requested_dimensions,omitempty"`
+	// Command to run instead of the shell, if any
+	Command *Command `protobuf:"bytes,4,opt,name=command,proto3" json:"command,omitempty"`
+	// Shell and working directory requested by the Guest, if any
+	ShellOverride *ShellOverride `protobuf:"bytes,5,opt,name=shell_override,json=shellOverride,proto3" json:"shell_override,omitempty"`
+	//
+	// Number of the output bytes the Host can send on the data channel before waiting for a window_update,
+	// zero disables the flow control
+	FlowControlWindow uint64 `protobuf:"varint,6,opt,name=flow_control_window,json=flowControlWindow,proto3" json:"flow_control_window,omitempty"`
+	// Compressions the Host can use for the output sent on the data channel, in the order of preference
+	SupportedCompressions []Compression `protobuf:"varint,7,rep,packed,name=supported_compressions,json=supportedCompressions,proto3,enum=Compression" json:"supported_compressions,omitempty"`
+}
+
+func (x *HostControlResponse_DataChannelRequest) Reset() {
+	*x = HostControlResponse_DataChannelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostControlResponse_DataChannelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostControlResponse_DataChannelRequest) ProtoMessage() {}
+
+func (x *HostControlResponse_DataChannelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostControlResponse_DataChannelRequest.ProtoReflect.Descriptor instead.
+func (*HostControlResponse_DataChannelRequest) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{7, 1}
+}
+
+func (x *HostControlResponse_DataChannelRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *HostControlResponse_DataChannelRequest) GetRequestedDimensions() *TerminalDimensions {
+	if x != nil {
+		return x.RequestedDimensions
+	}
+	return nil
+}
+
+func (x *HostControlResponse_DataChannelRequest) GetCommand() *Command {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *HostControlResponse_DataChannelRequest) GetShellOverride() *ShellOverride {
+	if x != nil {
+		return x.ShellOverride
+	}
+	return nil
+}
+
+func (x *HostControlResponse_DataChannelRequest) GetFlowControlWindow() uint64 {
+	if x != nil {
+		return x.FlowControlWindow
+	}
+	return 0
+}
+
+func (x *HostControlResponse_DataChannelRequest) GetSupportedCompressions() []Compression {
+	if x != nil {
+		return x.SupportedCompressions
+	}
+	return nil
+}
+
+type HostControlResponse_TunnelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Token that can be used to open a new tunnel data channel
+	Token string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// Port on the Host's loopback interface to connect to
+	Port uint32 `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	// Subsystem to serve instead of connecting to the port, e.g. "sftp"
+	Subsystem string `protobuf:"bytes,3,opt,name=subsystem,proto3" json:"subsystem,omitempty"`
+}
+
+func (x *HostControlResponse_TunnelRequest) Reset() {
+	*x = HostControlResponse_TunnelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostControlResponse_TunnelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostControlResponse_TunnelRequest) ProtoMessage() {}
+
+func (x *HostControlResponse_TunnelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostControlResponse_TunnelRequest.ProtoReflect.Descriptor instead.
+func (*HostControlResponse_TunnelRequest) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{7, 2}
+}
+
+func (x *HostControlResponse_TunnelRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *HostControlResponse_TunnelRequest) GetPort() uint32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *HostControlResponse_TunnelRequest) GetSubsystem() string {
+	if x != nil {
+		return x.Subsystem
+	}
+	return ""
+}
+
+type HostControlResponse_FileTransferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Token that can be used to open a new file transfer data channel
+	Token        string        `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	FileTransfer *FileTransfer `protobuf:"bytes,2,opt,name=file_transfer,json=fileTransfer,proto3" json:"file_transfer,omitempty"`
+}
+
+func (x *HostControlResponse_FileTransferRequest) Reset() {
+	*x = HostControlResponse_FileTransferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostControlResponse_FileTransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostControlResponse_FileTransferRequest) ProtoMessage() {}
+
+func (x *HostControlResponse_FileTransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostControlResponse_FileTransferRequest.ProtoReflect.Descriptor instead.
+func (*HostControlResponse_FileTransferRequest) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{7, 3}
+}
+
+func (x *HostControlResponse_FileTransferRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *HostControlResponse_FileTransferRequest) GetFileTransfer() *FileTransfer {
+	if x != nil {
+		return x.FileTransfer
+	}
+	return nil
+}
+
+type HostControlResponse_Drain struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Human-readable explanation of why the server is draining
+	Reason string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *HostControlResponse_Drain) Reset() {
+	*x = HostControlResponse_Drain{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostControlResponse_Drain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostControlResponse_Drain) ProtoMessage() {}
+
+func (x *HostControlResponse_Drain) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostControlResponse_Drain.ProtoReflect.Descriptor instead.
+func (*HostControlResponse_Drain) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{7, 4}
+}
+
+func (x *HostControlResponse_Drain) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type HostDataRequest_Hello struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Host's locator
+	Locator string `protobuf:"bytes,1,opt,name=locator,proto3" json:"locator,omitempty"`
+	// Token provided to the Host in DataChannelRequest
+	Token string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// Whether the Host honors the flow_control_window from the DataChannelRequest and expects the window updates
+	FlowControl bool `protobuf:"varint,3,opt,name=flow_control,json=flowControl,proto3" json:"flow_control,omitempty"`
+}
+
+func (x *HostDataRequest_Hello) Reset() {
+	*x = HostDataRequest_Hello{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostDataRequest_Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostDataRequest_Hello) ProtoMessage() {}
+
+func (x *HostDataRequest_Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostDataRequest_Hello.ProtoReflect.Descriptor instead.
+func (*HostDataRequest_Hello) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{8, 0}
+}
+
+func (x *HostDataRequest_Hello) GetLocator() string {
+	if x != nil {
+		return x.Locator
+	}
+	return ""
+}
+
+func (x *HostDataRequest_Hello) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *HostDataRequest_Hello) GetFlowControl() bool {
+	if x != nil {
+		return x.FlowControl
+	}
+	return false
+}
+
+type GuestTunnelRequest_Hello struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Locator of the terminal whose Host to connect through
+	Locator string `protobuf:"bytes,1,opt,name=locator,proto3" json:"locator,omitempty"`
+	// Should match the Host's trusted_secret, the read-only secret doesn't allow tunneling
+	Secret string `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	// Port on the Host's loopback interface to connect to, should be in the Host's allowlist
+	Port uint32 `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	// Subsystem to talk to instead of connecting to a port, currently only "sftp" is supported
+	Subsystem string `protobuf:"bytes,4,opt,name=subsystem,proto3" json:"subsystem,omitempty"`
+}
+
+func (x *GuestTunnelRequest_Hello) Reset() {
+	*x = GuestTunnelRequest_Hello{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GuestTunnelRequest_Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GuestTunnelRequest_Hello) ProtoMessage() {}
+
+func (x *GuestTunnelRequest_Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GuestTunnelRequest_Hello.ProtoReflect.Descriptor instead.
+func (*GuestTunnelRequest_Hello) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{13, 0}
+}
+
+func (x *GuestTunnelRequest_Hello) GetLocator() string {
+	if x != nil {
+		return x.Locator
+	}
+	return ""
+}
+
+func (x *GuestTunnelRequest_Hello) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *GuestTunnelRequest_Hello) GetPort() uint32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *GuestTunnelRequest_Hello) GetSubsystem() string {
+	if x != nil {
+		return x.Subsystem
+	}
+	return ""
+}
+
+type GuestTunnelResponse_Hello struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GuestTunnelResponse_Hello) Reset() {
+	*x = GuestTunnelResponse_Hello{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GuestTunnelResponse_Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GuestTunnelResponse_Hello) ProtoMessage() {}
+
+func (x *GuestTunnelResponse_Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GuestTunnelResponse_Hello.ProtoReflect.Descriptor instead.
+func (*GuestTunnelResponse_Hello) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{14, 0}
+}
+
+type HostTunnelRequest_Hello struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Host's locator
+	Locator string `protobuf:"bytes,1,opt,name=locator,proto3" json:"locator,omitempty"`
+	// Token provided to the Host in TunnelRequest
+	Token string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// Set when the Host has failed to connect to the requested port or refused to
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *HostTunnelRequest_Hello) Reset() {
+	*x = HostTunnelRequest_Hello{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostTunnelRequest_Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostTunnelRequest_Hello) ProtoMessage() {}
+
+func (x *HostTunnelRequest_Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostTunnelRequest_Hello.ProtoReflect.Descriptor instead.
+func (*HostTunnelRequest_Hello) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{15, 0}
+}
+
+func (x *HostTunnelRequest_Hello) GetLocator() string {
+	if x != nil {
+		return x.Locator
+	}
+	return ""
+}
+
+func (x *HostTunnelRequest_Hello) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *HostTunnelRequest_Hello) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type GuestFileTransferRequest_Hello struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Locator of the terminal whose Host to transfer the file from/to
+	Locator string `protobuf:"bytes,1,opt,name=locator,proto3" json:"locator,omitempty"`
+	// Should match the Host's trusted_secret, the read-only secret doesn't allow file transfers
+	Secret       string        `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	FileTransfer *FileTransfer `protobuf:"bytes,3,opt,name=file_transfer,json=fileTransfer,proto3" json:"file_transfer,omitempty"`
+}
+
+func (x *GuestFileTransferRequest_Hello) Reset() {
+	*x = GuestFileTransferRequest_Hello{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GuestFileTransferRequest_Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GuestFileTransferRequest_Hello) ProtoMessage() {}
+
+func (x *GuestFileTransferRequest_Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GuestFileTransferRequest_Hello.ProtoReflect.Descriptor instead.
+func (*GuestFileTransferRequest_Hello) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{20, 0}
+}
+
+func (x *GuestFileTransferRequest_Hello) GetLocator() string {
+	if x != nil {
+		return x.Locator
+	}
+	return ""
+}
+
+func (x *GuestFileTransferRequest_Hello) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *GuestFileTransferRequest_Hello) GetFileTransfer() *FileTransfer {
+	if x != nil {
+		return x.FileTransfer
+	}
+	return nil
+}
+
+type HostFileTransferRequest_Hello struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Host's locator
+	Locator string `protobuf:"bytes,1,opt,name=locator,proto3" json:"locator,omitempty"`
+	// Token provided to the Host in FileTransferRequest
+	Token string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// Set when the Host refuses the file transfer, e.g. when the path is outside of its root directories
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *HostFileTransferRequest_Hello) Reset() {
+	*x = HostFileTransferRequest_Hello{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HostFileTransferRequest_Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostFileTransferRequest_Hello) ProtoMessage() {}
+
+func (x *HostFileTransferRequest_Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostFileTransferRequest_Hello.ProtoReflect.Descriptor instead.
+func (*HostFileTransferRequest_Hello) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{22, 0}
+}
+
+func (x *HostFileTransferRequest_Hello) GetLocator() string {
+	if x != nil {
+		return x.Locator
+	}
+	return ""
+}
+
+func (x *HostFileTransferRequest_Hello) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *HostFileTransferRequest_Hello) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+var File_terminal_proto protoreflect.FileDescriptor
+
+var file_terminal_proto_rawDesc = []byte{
+	0x0a, 0x0e, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0xcc, 0x05, 0x0a, 0x14, 0x47, 0x75, 0x65, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
+	0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x33, 0x0a, 0x05, 0x68, 0x65, 0x6c,
+	0x6c, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x47, 0x75, 0x65, 0x73, 0x74,
+	0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
+	0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x48, 0x00, 0x52, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x42,
+	0x0a, 0x11, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x5f, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x54, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x48, 0x00,
+	0x52, 0x10, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75,
+	0x74, 0x12, 0x21, 0x0a, 0x0b, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x5f, 0x69, 0x6e, 0x70, 0x75, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x0a, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x49,
+	0x6e, 0x70, 0x75, 0x74, 0x1a, 0xeb, 0x03, 0x0a, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x18,
+	0x0a, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x72,
+	0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74,
+	0x12, 0x46, 0x0a, 0x14, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x64, 0x69,
+	0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x52, 0x13, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x44, 0x69,
+	0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x73, 0x75, 0x6d,
+	0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72,
+	0x65, 0x73, 0x75, 0x6d, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65,
+	0x73, 0x75, 0x6d, 0x65, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0c, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12,
+	0x22, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x08, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x12, 0x35, 0x0a, 0x0e, 0x73, 0x68, 0x65, 0x6c, 0x6c, 0x5f, 0x6f, 0x76, 0x65,
+	0x72, 0x72, 0x69, 0x64, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x53, 0x68,
+	0x65, 0x6c, 0x6c, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x52, 0x0d, 0x73, 0x68, 0x65,
+	0x6c, 0x6c, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x12, 0x41, 0x0a, 0x15, 0x61, 0x63,
+	0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x43, 0x6f, 0x6d, 0x70,
+	0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x14, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65,
+	0x64, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x29, 0x0a,
+	0x10, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f,
+	0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x22, 0x0a, 0x0c, 0x63, 0x61, 0x70, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c,
+	0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b,
+	0x65, 0x6e, 0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22,
+	0xab, 0x05, 0x0a, 0x15, 0x47, 0x75, 0x65, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
+	0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x06, 0x6f, 0x75, 0x74,
+	0x70, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61,
+	0x48, 0x00, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x34, 0x0a, 0x05, 0x68, 0x65,
+	0x6c, 0x6c, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x47, 0x75, 0x65, 0x73,
+	0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x2e, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x48, 0x00, 0x52, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f,
+	0x12, 0x2a, 0x0a, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52,
+	0x0b, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x30, 0x0a, 0x0b,
+	0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0c, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48,
+	0x00, 0x52, 0x0b, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3d,
+	0x0a, 0x10, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74,
+	0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75,
+	0x74, 0x54, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x0f, 0x6f, 0x75,
+	0x74, 0x70, 0x75, 0x74, 0x54, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12, 0x34, 0x0a,
+	0x0d, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x5f, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x64, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x49, 0x67, 0x6e, 0x6f,
+	0x72, 0x65, 0x64, 0x48, 0x00, 0x52, 0x0c, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x49, 0x67, 0x6e, 0x6f,
+	0x72, 0x65, 0x64, 0x1a, 0xda, 0x02, 0x0a, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x1d, 0x0a,
+	0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09,
+	0x72, 0x65, 0x61, 0x64, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x08, 0x72, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x73,
+	0x75, 0x6d, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x23, 0x0a, 0x0d,
+	0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0c, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x4f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x69,
+	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69,
+	0x6e, 0x67, 0x49, 0x64, 0x12, 0x2e, 0x0a, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x43, 0x6f, 0x6d, 0x70,
+	0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c,
+	0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x22, 0x0a, 0x0c, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18,
+	0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x69, 0x65, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x68, 0x6f, 0x73, 0x74, 0x5f, 0x63, 0x61, 0x70, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10,
+	0x68, 0x6f, 0x73, 0x74, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73,
+	0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x26, 0x0a,
+	0x0c, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x49, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x64, 0x12, 0x16, 0x0a,
+	0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x80, 0x01, 0x0a, 0x0f, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x54, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12, 0x30, 0x0a, 0x14, 0x64, 0x72, 0x6f,
+	0x70, 0x70, 0x65, 0x64, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x62, 0x79, 0x74, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64,
+	0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x3b, 0x0a, 0x1a, 0x64,
+	0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6f, 0x75, 0x74,
+	0x70, 0x75, 0x74, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x17, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x4f, 0x75, 0x74,
+	0x70, 0x75, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0x7a, 0x0a, 0x0d, 0x52, 0x65, 0x70, 0x6c,
+	0x61, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x63,
+	0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x72,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x49, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x73, 0x70, 0x65, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73,
+	0x70, 0x65, 0x65, 0x64, 0x22, 0xa1, 0x01, 0x0a, 0x0e, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00,
+	0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x42, 0x0a, 0x11, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x5f, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x44, 0x69,
+	0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x48, 0x00, 0x52, 0x10, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x05,
+	0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61,
+	0x74, 0x61, 0x48, 0x00, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x42, 0x0b, 0x0a, 0x09, 0x6f,
+	0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xbd, 0x02, 0x0a, 0x12, 0x48, 0x6f, 0x73,
+	0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x31, 0x0a, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x48, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x2e, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x48, 0x00, 0x52, 0x05, 0x68, 0x65, 0x6c,
+	0x6c, 0x6f, 0x1a, 0xe6, 0x01, 0x0a, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x25, 0x0a, 0x0e,
+	0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x53, 0x65, 0x63,
+	0x72, 0x65, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x23, 0x0a,
+	0x0d, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x72, 0x6f,
+	0x6f, 0x66, 0x12, 0x28, 0x0a, 0x10, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x5f,
+	0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x72, 0x65,
+	0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x12, 0x29, 0x0a, 0x10,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x22, 0x0a, 0x0c, 0x63, 0x61, 0x70, 0x61, 0x62,
+	0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x63,
+	0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x42, 0x0b, 0x0a, 0x09, 0x6f,
+	0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xcc, 0x08, 0x0a, 0x13, 0x48, 0x6f, 0x73,
+	0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x32, 0x0a, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x48, 0x00, 0x52, 0x05, 0x68,
+	0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x5b, 0x0a, 0x14, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x63, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x27, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x43, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x12, 0x64,
+	0x61, 0x74, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x4b, 0x0a, 0x0e, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x48, 0x6f, 0x73, 0x74,
+	0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e,
+	0x54, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52,
+	0x0d, 0x74, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x5e,
+	0x0a, 0x15, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x5f,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e,
+	0x48, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x13, 0x66, 0x69, 0x6c, 0x65, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x32,
+	0x0a, 0x05, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x48, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x2e, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x48, 0x00, 0x52, 0x05, 0x64, 0x72, 0x61,
+	0x69, 0x6e, 0x1a, 0x95, 0x01, 0x0a, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x18, 0x0a, 0x07,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f,
+	0x72, 0x5f, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x29, 0x0a, 0x10, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x22, 0x0a, 0x0c, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69,
+	0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x61,
+	0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x1a, 0xc2, 0x02, 0x0a, 0x12, 0x44,
+	0x61, 0x74, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x46, 0x0a, 0x14, 0x72, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c,
+	0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x13, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x65, 0x64, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x22, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x08, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x12, 0x35, 0x0a, 0x0e, 0x73, 0x68, 0x65, 0x6c, 0x6c, 0x5f, 0x6f, 0x76, 0x65,
+	0x72, 0x72, 0x69, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x53, 0x68,
+	0x65, 0x6c, 0x6c, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x52, 0x0d, 0x73, 0x68, 0x65,
+	0x6c, 0x6c, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x12, 0x2e, 0x0a, 0x13, 0x66, 0x6c,
+	0x6f, 0x77, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x5f, 0x77, 0x69, 0x6e, 0x64, 0x6f,
+	0x77, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11, 0x66, 0x6c, 0x6f, 0x77, 0x43, 0x6f, 0x6e,
+	0x74, 0x72, 0x6f, 0x6c, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x12, 0x43, 0x0a, 0x16, 0x73, 0x75,
+	0x70, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x43, 0x6f, 0x6d,
+	0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x15, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72,
+	0x74, 0x65, 0x64, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x1a,
+	0x57, 0x0a, 0x0d, 0x54, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x75,
+	0x62, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73,
+	0x75, 0x62, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x1a, 0x5f, 0x0a, 0x13, 0x46, 0x69, 0x6c, 0x65,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x32, 0x0a, 0x0d, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x46,
+	0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x0c, 0x66, 0x69, 0x6c,
+	0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x1a, 0x1f, 0x0a, 0x05, 0x44, 0x72, 0x61,
+	0x69, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xa9, 0x02, 0x0a, 0x0f, 0x48, 0x6f, 0x73, 0x74,
+	0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x05, 0x68,
+	0x65, 0x6c, 0x6c, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x48, 0x6f, 0x73,
+	0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x48, 0x65, 0x6c,
+	0x6c, 0x6f, 0x48, 0x00, 0x52, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x1f, 0x0a, 0x06, 0x6f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61,
+	0x74, 0x61, 0x48, 0x00, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x2a, 0x0a, 0x0c,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x0b, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x30, 0x0a, 0x0b, 0x74, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
+	0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x0b, 0x74,
+	0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x5a, 0x0a, 0x05, 0x48, 0x65,
+	0x6c, 0x6c, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x14, 0x0a,
+	0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x66, 0x6c, 0x6f, 0x77, 0x5f, 0x63, 0x6f, 0x6e, 0x74,
+	0x72, 0x6f, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x66, 0x6c, 0x6f, 0x77, 0x43,
+	0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x22, 0xcc, 0x01, 0x0a, 0x10, 0x48, 0x6f, 0x73, 0x74, 0x44, 0x61, 0x74, 0x61,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x11, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x5f, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x44, 0x69,
+	0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x48, 0x00, 0x52, 0x10, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x05,
+	0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61,
+	0x74, 0x61, 0x48, 0x00, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x21, 0x0a, 0x0b, 0x63,
+	0x6c, 0x6f, 0x73, 0x65, 0x5f, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x48, 0x00, 0x52, 0x0a, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x25,
+	0x0a, 0x0d, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00, 0x52, 0x0c, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x55,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x22, 0x5a, 0x0a, 0x12, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x44, 0x69,
+	0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x77, 0x69, 0x64, 0x74,
+	0x68, 0x5f, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0c, 0x77, 0x69, 0x64, 0x74, 0x68, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x1f, 0x0a,
+	0x0b, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x72, 0x6f, 0x77, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0a, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x52, 0x6f, 0x77, 0x73, 0x22, 0x4a,
+	0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x2e, 0x0a, 0x0b, 0x63, 0x6f,
+	0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x0c, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x63,
+	0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x43, 0x0a, 0x07, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x72, 0x67,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x61, 0x72, 0x67, 0x73, 0x12, 0x10, 0x0a,
+	0x03, 0x70, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x70, 0x74, 0x79, 0x22,
+	0xde, 0x01, 0x0a, 0x12, 0x47, 0x75, 0x65, 0x73, 0x74, 0x54, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x31, 0x0a, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x47, 0x75, 0x65, 0x73, 0x74, 0x54, 0x75, 0x6e,
+	0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x48, 0x65, 0x6c, 0x6c, 0x6f,
+	0x48, 0x00, 0x52, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x1b, 0x0a, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00,
+	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x6b, 0x0a, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12,
+	0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x63,
+	0x72, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x04, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x75, 0x62, 0x73, 0x79, 0x73, 0x74,
+	0x65, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x75, 0x62, 0x73, 0x79, 0x73,
+	0x74, 0x65, 0x6d, 0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x22, 0x9f, 0x01, 0x0a, 0x13, 0x47, 0x75, 0x65, 0x73, 0x74, 0x54, 0x75, 0x6e, 0x6e, 0x65, 0x6c,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x05, 0x68, 0x65, 0x6c, 0x6c,
+	0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x47, 0x75, 0x65, 0x73, 0x74, 0x54,
+	0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x48, 0x65,
+	0x6c, 0x6c, 0x6f, 0x48, 0x00, 0x52, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x1b, 0x0a, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74,
+	0x61, 0x48, 0x00, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x21, 0x0a, 0x0b, 0x63, 0x6c, 0x6f,
+	0x73, 0x65, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00,
+	0x52, 0x0a, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x57, 0x72, 0x69, 0x74, 0x65, 0x1a, 0x07, 0x0a, 0x05,
+	0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x22, 0xc6, 0x01, 0x0a, 0x11, 0x48, 0x6f, 0x73, 0x74, 0x54, 0x75, 0x6e, 0x6e, 0x65,
+	0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x30, 0x0a, 0x05, 0x68, 0x65, 0x6c, 0x6c,
+	0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x54, 0x75,
+	0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x48, 0x65, 0x6c, 0x6c,
+	0x6f, 0x48, 0x00, 0x52, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x1b, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48,
+	0x00, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x55, 0x0a, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f,
+	0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
+	0x12, 0x1c, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x06, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x0b,
+	0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x61, 0x0a, 0x12, 0x48,
+	0x6f, 0x73, 0x74, 0x54, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x1b, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x21,
+	0x0a, 0x0b, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x0a, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x57, 0x72, 0x69, 0x74,
+	0x65, 0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xc3,
+	0x01, 0x0a, 0x0c, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x12,
+	0x35, 0x0a, 0x09, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x17, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65,
+	0x72, 0x2e, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x64, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69,
+	0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x6d, 0x6f,
+	0x64, 0x65, 0x22, 0x40, 0x0a, 0x09, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x19, 0x0a, 0x15, 0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x53,
+	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x44, 0x4f,
+	0x57, 0x4e, 0x4c, 0x4f, 0x41, 0x44, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x50, 0x4c, 0x4f,
+	0x41, 0x44, 0x10, 0x02, 0x22, 0x34, 0x0a, 0x0a, 0x46, 0x69, 0x6c, 0x65, 0x48, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x22, 0x25, 0x0a, 0x0b, 0x46, 0x69,
+	0x6c, 0x65, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x68, 0x61,
+	0x32, 0x35, 0x36, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x68, 0x61, 0x32, 0x35,
+	0x36, 0x22, 0x98, 0x02, 0x0a, 0x18, 0x47, 0x75, 0x65, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x37,
+	0x0a, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e,
+	0x47, 0x75, 0x65, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65,
+	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x48, 0x00,
+	0x52, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x1d, 0x0a, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52,
+	0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x28, 0x0a, 0x07, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x65,
+	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72,
+	0x61, 0x69, 0x6c, 0x65, 0x72, 0x48, 0x00, 0x52, 0x07, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x65, 0x72,
+	0x1a, 0x6d, 0x0a, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x63,
+	0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x12, 0x32, 0x0a, 0x0d, 0x66,
+	0x69, 0x6c, 0x65, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65,
+	0x72, 0x52, 0x0c, 0x66, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x42,
+	0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x98, 0x01, 0x0a,
+	0x19, 0x47, 0x75, 0x65, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x25, 0x0a, 0x06, 0x68, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x46, 0x69, 0x6c,
+	0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x48, 0x00, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x12, 0x1d, 0x0a, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b,
+	0x12, 0x28, 0x0a, 0x07, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0c, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x65, 0x72, 0x48,
+	0x00, 0x52, 0x07, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x65, 0x72, 0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xc5, 0x02, 0x0a, 0x17, 0x48, 0x6f, 0x73, 0x74,
+	0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x36, 0x0a, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x48, 0x65, 0x6c,
+	0x6c, 0x6f, 0x48, 0x00, 0x52, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x25, 0x0a, 0x06, 0x68,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x46, 0x69,
+	0x6c, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x48, 0x00, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x12, 0x1d, 0x0a, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x05, 0x63, 0x68, 0x75, 0x6e,
+	0x6b, 0x12, 0x28, 0x0a, 0x07, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x65, 0x72,
+	0x48, 0x00, 0x52, 0x07, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x65, 0x72, 0x12, 0x1e, 0x0a, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x1a, 0x55, 0x0a, 0x05, 0x48,
+	0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x14,
+	0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1c, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22,
+	0x70, 0x0a, 0x18, 0x48, 0x6f, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x05, 0x63,
+	0x68, 0x75, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74,
+	0x61, 0x48, 0x00, 0x52, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x28, 0x0a, 0x07, 0x74, 0x72,
+	0x61, 0x69, 0x6c, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x46, 0x69,
+	0x6c, 0x65, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x65, 0x72, 0x48, 0x00, 0x52, 0x07, 0x74, 0x72, 0x61,
+	0x69, 0x6c, 0x65, 0x72, 0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x22, 0x66, 0x0a, 0x0d, 0x53, 0x68, 0x65, 0x6c, 0x6c, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69,
+	0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x68, 0x65, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x73, 0x68, 0x65, 0x6c, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x72, 0x67, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x61, 0x72, 0x67, 0x73, 0x12, 0x2b, 0x0a, 0x11,
+	0x77, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x77, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67,
+	0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x22, 0x38, 0x0a, 0x0a, 0x45, 0x78, 0x69,
+	0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x6c, 0x22, 0xba, 0x02, 0x0a, 0x0b, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x2b, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x2e, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x12, 0x2c, 0x0a, 0x0b, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x45, 0x78, 0x69, 0x74, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x0a, 0x65, 0x78, 0x69, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1c,
+	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xb1, 0x01, 0x0a,
+	0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x12, 0x52, 0x45, 0x41, 0x53, 0x4f,
+	0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12,
+	0x10, 0x0a, 0x0c, 0x53, 0x48, 0x45, 0x4c, 0x4c, 0x5f, 0x45, 0x58, 0x49, 0x54, 0x45, 0x44, 0x10,
+	0x01, 0x12, 0x15, 0x0a, 0x11, 0x48, 0x4f, 0x53, 0x54, 0x5f, 0x44, 0x49, 0x53, 0x43, 0x4f, 0x4e,
+	0x4e, 0x45, 0x43, 0x54, 0x45, 0x44, 0x10, 0x02, 0x12, 0x10, 0x0a, 0x0c, 0x49, 0x44, 0x4c, 0x45,
+	0x5f, 0x54, 0x49, 0x4d, 0x45, 0x4f, 0x55, 0x54, 0x10, 0x03, 0x12, 0x13, 0x0a, 0x0f, 0x4b, 0x49,
+	0x43, 0x4b, 0x45, 0x44, 0x5f, 0x42, 0x59, 0x5f, 0x41, 0x44, 0x4d, 0x49, 0x4e, 0x10, 0x04, 0x12,
+	0x13, 0x0a, 0x0f, 0x53, 0x45, 0x52, 0x56, 0x45, 0x52, 0x5f, 0x53, 0x48, 0x55, 0x54, 0x44, 0x4f,
+	0x57, 0x4e, 0x10, 0x05, 0x12, 0x19, 0x0a, 0x15, 0x4d, 0x41, 0x58, 0x5f, 0x44, 0x55, 0x52, 0x41,
+	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x45, 0x58, 0x43, 0x45, 0x45, 0x44, 0x45, 0x44, 0x10, 0x06, 0x12,
+	0x0f, 0x0a, 0x0b, 0x4e, 0x4f, 0x54, 0x5f, 0x41, 0x4c, 0x4c, 0x4f, 0x57, 0x45, 0x44, 0x10, 0x07,
+	0x22, 0x21, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x2a, 0x3a, 0x0a, 0x0b, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x10, 0x43, 0x4f, 0x4d, 0x50, 0x52, 0x45, 0x53, 0x53, 0x49, 0x4f,
+	0x4e, 0x5f, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x44, 0x45, 0x46, 0x4c,
+	0x41, 0x54, 0x45, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x5a, 0x53, 0x54, 0x44, 0x10, 0x02, 0x32,
+	0x93, 0x02, 0x0a, 0x0c, 0x47, 0x75, 0x65, 0x73, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x12, 0x44, 0x0a, 0x0f, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x43, 0x68, 0x61, 0x6e,
+	0x6e, 0x65, 0x6c, 0x12, 0x15, 0x2e, 0x47, 0x75, 0x65, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69,
+	0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x47, 0x75, 0x65,
+	0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x12, 0x2b, 0x0a, 0x06, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79,
+	0x12, 0x0e, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x0f, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x30, 0x01, 0x12, 0x3e, 0x0a, 0x0d, 0x54, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x43, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x13, 0x2e, 0x47, 0x75, 0x65, 0x73, 0x74, 0x54, 0x75, 0x6e, 0x6e,
+	0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x47, 0x75, 0x65, 0x73,
+	0x74, 0x54, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28,
+	0x01, 0x30, 0x01, 0x12, 0x50, 0x0a, 0x13, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x65, 0x72, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x19, 0x2e, 0x47, 0x75, 0x65,
+	0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x47, 0x75, 0x65, 0x73, 0x74, 0x46, 0x69, 0x6c,
+	0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x28, 0x01, 0x30, 0x01, 0x32, 0x9c, 0x02, 0x0a, 0x0b, 0x48, 0x6f, 0x73, 0x74, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3f, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c,
+	0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x13, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x43, 0x6f,
+	0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x48,
+	0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x12, 0x36, 0x0a, 0x0b, 0x44, 0x61, 0x74, 0x61, 0x43, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x10, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x44, 0x61, 0x74, 0x61,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x44, 0x61,
+	0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x12, 0x40,
+	0x0a, 0x11, 0x54, 0x75, 0x6e, 0x6e, 0x65, 0x6c, 0x44, 0x61, 0x74, 0x61, 0x43, 0x68, 0x61, 0x6e,
+	0x6e, 0x65, 0x6c, 0x12, 0x12, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x54, 0x75, 0x6e, 0x6e, 0x65, 0x6c,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x54, 0x75,
+	0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01,
+	0x12, 0x52, 0x0a, 0x17, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72,
+	0x44, 0x61, 0x74, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x18, 0x2e, 0x48, 0x6f,
+	0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x28, 0x01, 0x30, 0x01, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x63, 0x69, 0x72, 0x72, 0x75, 0x73, 0x6c, 0x61, 0x62, 0x73, 0x2f, 0x74, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f,
+	0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_terminal_proto_rawDescOnce sync.Once
+	file_terminal_proto_rawDescData = file_terminal_proto_rawDesc
+)
+
+func file_terminal_proto_rawDescGZIP() []byte {
+	file_terminal_proto_rawDescOnce.Do(func() {
+		file_terminal_proto_rawDescData = protoimpl.X.CompressGZIP(file_terminal_proto_rawDescData)
+	})
+	return file_terminal_proto_rawDescData
+}
+
+var file_terminal_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_terminal_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
+var file_terminal_proto_goTypes = []interface{}{
+	(Compression)(0),                                // 0: Compression
+	(FileTransfer_Direction)(0),                     // 1: FileTransfer.Direction
+	(Termination_Reason)(0),                         // 2: Termination.Reason
+	(*GuestTerminalRequest)(nil),                    // 3: GuestTerminalRequest
+	(*GuestTerminalResponse)(nil),                   // 4: GuestTerminalResponse
+	(*InputIgnored)(nil),                            // 5: InputIgnored
+	(*OutputTruncated)(nil),                         // 6: OutputTruncated
+	(*ReplayRequest)(nil),                           // 7: ReplayRequest
+	(*ReplayResponse)(nil),                          // 8: ReplayResponse
+	(*HostControlRequest)(nil),                      // 9: HostControlRequest
+	(*HostControlResponse)(nil),                     // 10: HostControlResponse
+	(*HostDataRequest)(nil),                         // 11: HostDataRequest
+	(*HostDataResponse)(nil),                        // 12: HostDataResponse
+	(*TerminalDimensions)(nil),                      // 13: TerminalDimensions
+	(*Data)(nil),                                    // 14: Data
+	(*Command)(nil),                                 // 15: Command
+	(*GuestTunnelRequest)(nil),                      // 16: GuestTunnelRequest
+	(*GuestTunnelResponse)(nil),                     // 17: GuestTunnelResponse
+	(*HostTunnelRequest)(nil),                       // 18: HostTunnelRequest
+	(*HostTunnelResponse)(nil),                      // 19: HostTunnelResponse
+	(*FileTransfer)(nil),                            // 20: FileTransfer
+	(*FileHeader)(nil),                              // 21: FileHeader
+	(*FileTrailer)(nil),                             // 22: FileTrailer
+	(*GuestFileTransferRequest)(nil),                // 23: GuestFileTransferRequest
+	(*GuestFileTransferResponse)(nil),               // 24: GuestFileTransferResponse
+	(*HostFileTransferRequest)(nil),                 // 25: HostFileTransferRequest
+	(*HostFileTransferResponse)(nil),                // 26: HostFileTransferResponse
+	(*ShellOverride)(nil),                           // 27: ShellOverride
+	(*ExitStatus)(nil),                              // 28: ExitStatus
+	(*Termination)(nil),                             // 29: Termination
+	(*Error)(nil),                                   // 30: Error
+	(*GuestTerminalRequest_Hello)(nil),              // 31: GuestTerminalRequest.Hello
+	(*GuestTerminalResponse_Hello)(nil),             // 32: GuestTerminalResponse.Hello
+	(*HostControlRequest_Hello)(nil),                // 33: HostControlRequest.Hello
+	(*HostControlResponse_Hello)(nil),               // 34: HostControlResponse.Hello
+	(*HostControlResponse_DataChannelRequest)(nil),  // 35: HostControlResponse.DataChannelRequest
+	(*HostControlResponse_TunnelRequest)(nil),       // 36: HostControlResponse.TunnelRequest
+	(*HostControlResponse_FileTransferRequest)(nil), // 37: HostControlResponse.FileTransferRequest
+	(*HostControlResponse_Drain)(nil),               // 38: HostControlResponse.Drain
+	(*HostDataRequest_Hello)(nil),                   // 39: HostDataRequest.Hello
+	(*GuestTunnelRequest_Hello)(nil),                // 40: GuestTunnelRequest.Hello
+	(*GuestTunnelResponse_Hello)(nil),               // 41: GuestTunnelResponse.Hello
+	(*HostTunnelRequest_Hello)(nil),                 // 42: HostTunnelRequest.Hello
+	(*GuestFileTransferRequest_Hello)(nil),          // 43: GuestFileTransferRequest.Hello
+	(*HostFileTransferRequest_Hello)(nil),           // 44: HostFileTransferRequest.Hello
+}
+var file_terminal_proto_depIdxs = []int32{
+	31, // 0: GuestTerminalRequest.hello:type_name -> GuestTerminalRequest.Hello
+	13, // 1: GuestTerminalRequest.change_dimensions:type_name -> TerminalDimensions
+	14, // 2: GuestTerminalRequest.input:type_name -> Data
+	14, // 3: GuestTerminalResponse.output:type_name -> Data
+	32, // 4: GuestTerminalResponse.hello:type_name -> GuestTerminalResponse.Hello
+	14, // 5: GuestTerminalResponse.error_output:type_name -> Data
+	29, // 6: GuestTerminalResponse.termination:type_name -> Termination
+	6,  // 7: GuestTerminalResponse.output_truncated:type_name -> OutputTruncated
+	5,  // 8: GuestTerminalResponse.input_ignored:type_name -> InputIgnored
+	14, // 9: ReplayResponse.output:type_name -> Data
+	13, // 10: ReplayResponse.change_dimensions:type_name -> TerminalDimensions
+	14, // 11: ReplayResponse.input:type_name -> Data
+	33, // 12: HostControlRequest.hello:type_name -> HostControlRequest.Hello
+	34, // 13: HostControlResponse.hello:type_name -> HostControlResponse.Hello
+	35, // 14: HostControlResponse.data_channel_request:type_name -> HostControlResponse.DataChannelRequest
+	36, // 15: HostControlResponse.tunnel_request:type_name -> HostControlResponse.TunnelRequest
+	37, // 16: HostControlResponse.file_transfer_request:type_name -> HostControlResponse.FileTransferRequest
+	38, // 17: HostControlResponse.drain:type_name -> HostControlResponse.Drain
+	39, // 18: HostDataRequest.hello:type_name -> HostDataRequest.Hello
+	14, // 19: HostDataRequest.output:type_name -> Data
+	14, // 20: HostDataRequest.error_output:type_name -> Data
+	29, // 21: HostDataRequest.termination:type_name -> Termination
+	13, // 22: HostDataResponse.change_dimensions:type_name -> TerminalDimensions
+	14, // 23: HostDataResponse.input:type_name -> Data
+	0,  // 24: Data.compression:type_name -> Compression
+	40, // 25: GuestTunnelRequest.hello:type_name -> GuestTunnelRequest.Hello
+	14, // 26: GuestTunnelRequest.data:type_name -> Data
+	41, // 27: GuestTunnelResponse.hello:type_name -> GuestTunnelResponse.Hello
+	14, // 28: GuestTunnelResponse.data:type_name -> Data
+	42, // 29: HostTunnelRequest.hello:type_name -> HostTunnelRequest.Hello
+	14, // 30: HostTunnelRequest.data:type_name -> Data
+	14, // 31: HostTunnelResponse.data:type_name -> Data
+	1,  // 32: FileTransfer.direction:type_name -> FileTransfer.Direction
+	43, // 33: GuestFileTransferRequest.hello:type_name -> GuestFileTransferRequest.Hello
+	14, // 34: GuestFileTransferRequest.chunk:type_name -> Data
+	22, // 35: GuestFileTransferRequest.trailer:type_name -> FileTrailer
+	21, // 36: GuestFileTransferResponse.header:type_name -> FileHeader
+	14, // 37: GuestFileTransferResponse.chunk:type_name -> Data
+	22, // 38: GuestFileTransferResponse.trailer:type_name -> FileTrailer
+	44, // 39: HostFileTransferRequest.hello:type_name -> HostFileTransferRequest.Hello
+	21, // 40: HostFileTransferRequest.header:type_name -> FileHeader
+	14, // 41: HostFileTransferRequest.chunk:type_name -> Data
+	22, // 42: HostFileTransferRequest.trailer:type_name -> FileTrailer
+	30, // 43: HostFileTransferRequest.error:type_name -> Error
+	14, // 44: HostFileTransferResponse.chunk:type_name -> Data
+	22, // 45: HostFileTransferResponse.trailer:type_name -> FileTrailer
+	2,  // 46: Termination.reason:type_name -> Termination.Reason
+	28, // 47: Termination.exit_status:type_name -> ExitStatus
+	30, // 48: Termination.error:type_name -> Error
+	13, // 49: GuestTerminalRequest.Hello.requested_dimensions:type_name -> TerminalDimensions
+	15, // 50: GuestTerminalRequest.Hello.command:type_name -> Command
+	27, // 51: GuestTerminalRequest.Hello.shell_override:type_name -> ShellOverride
+	0,  // 52: GuestTerminalRequest.Hello.accepted_compressions:type_name -> Compression
+	0,  // 53: GuestTerminalResponse.Hello.compression:type_name -> Compression
+	13, // 54: HostControlResponse.DataChannelRequest.requested_dimensions:type_name -> TerminalDimensions
+	15, // 55: HostControlResponse.DataChannelRequest.command:type_name -> Command
+	27, // 56: HostControlResponse.DataChannelRequest.shell_override:type_name -> ShellOverride
+	0,  // 57: HostControlResponse.DataChannelRequest.supported_compressions:type_name -> Compression
+	20, // 58: HostControlResponse.FileTransferRequest.file_transfer:type_name -> FileTransfer
+	30, // 59: HostTunnelRequest.Hello.error:type_name -> Error
+	20, // 60: GuestFileTransferRequest.Hello.file_transfer:type_name -> FileTransfer
+	30, // 61: HostFileTransferRequest.Hello.error:type_name -> Error
+	3,  // 62: GuestService.TerminalChannel:input_type -> GuestTerminalRequest
+	7,  // 63: GuestService.Replay:input_type -> ReplayRequest
+	16, // 64: GuestService.TunnelChannel:input_type -> GuestTunnelRequest
+	23, // 65: GuestService.FileTransferChannel:input_type -> GuestFileTransferRequest
+	9,  // 66: HostService.ControlChannel:input_type -> HostControlRequest
+	11, // 67: HostService.DataChannel:input_type -> HostDataRequest
+	18, // 68: HostService.TunnelDataChannel:input_type -> HostTunnelRequest
+	25, // 69: HostService.FileTransferDataChannel:input_type -> HostFileTransferRequest
+	4,  // 70: GuestService.TerminalChannel:output_type -> GuestTerminalResponse
+	8,  // 71: GuestService.Replay:output_type -> ReplayResponse
+	17, // 72: GuestService.TunnelChannel:output_type -> GuestTunnelResponse
+	24, // 73: GuestService.FileTransferChannel:output_type -> GuestFileTransferResponse
+	10, // 74: HostService.ControlChannel:output_type -> HostControlResponse
+	12, // 75: HostService.DataChannel:output_type -> HostDataResponse
+	19, // 76: HostService.TunnelDataChannel:output_type -> HostTunnelResponse
+	26, // 77: HostService.FileTransferDataChannel:output_type -> HostFileTransferResponse
+	70, // [70:78] is the sub-list for method output_type
+	62, // [62:70] is the sub-list for method input_type
+	62, // [62:62] is the sub-list for extension type_name
+	62, // [62:62] is the sub-list for extension extendee
+	0,  // [0:62] is the sub-list for field type_name
+}
+
+func init() { file_terminal_proto_init() }
+func file_terminal_proto_init() {
+	if File_terminal_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_terminal_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GuestTerminalRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GuestTerminalResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InputIgnored); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OutputTruncated); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplayRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplayResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostControlRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostControlResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostDataRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostDataResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TerminalDimensions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Data); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Command); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GuestTunnelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GuestTunnelResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostTunnelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostTunnelResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FileTransfer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FileHeader); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
 		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use HostDataRequest_Hello.ProtoReflect.Descriptor instead.
-func (*HostDataRequest_Hello) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{4, 0}
-}
-
-func (x *HostDataRequest_Hello) GetLocator() string {
-	if x != nil {
-		return x.Locator
-	}
-	return ""
-}
-
-func (x *HostDataRequest_Hello) GetToken() string {
-	if x != nil {
-		return x.Token
-	}
-	return ""
-}
-
-var File_terminal_proto protoreflect.FileDescriptor
-
-var file_terminal_proto_rawDesc = []byte{
-	0x0a, 0x0e, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x22, 0xbf, 0x02, 0x0a, 0x14, 0x47, 0x75, 0x65, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
-	0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x33, 0x0a, 0x05, 0x68, 0x65, 0x6c,
-	0x6c, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x47, 0x75, 0x65, 0x73, 0x74,
-	0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
-	0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x48, 0x00, 0x52, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x42,
-	0x0a, 0x11, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x5f, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69,
-	0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x54, 0x65, 0x72, 0x6d,
-	0x69, 0x6e, 0x61, 0x6c, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x48, 0x00,
-	0x52, 0x10, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f,
-	0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75,
-	0x74, 0x1a, 0x81, 0x01, 0x0a, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x6c,
-	0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f,
-	0x63, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x12, 0x46, 0x0a,
-	0x14, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x6d, 0x65, 0x6e,
-	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x54, 0x65,
-	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73,
-	0x52, 0x13, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x44, 0x69, 0x6d, 0x65, 0x6e,
-	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x22, 0x45, 0x0a, 0x15, 0x47, 0x75, 0x65, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69,
-	0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x06, 0x6f,
-	0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61,
-	0x74, 0x61, 0x48, 0x00, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x42, 0x0b, 0x0a, 0x09,
-	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x84, 0x01, 0x0a, 0x12, 0x48, 0x6f,
-	0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x31, 0x0a, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x19, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x2e, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x48, 0x00, 0x52, 0x05, 0x68, 0x65,
-	0x6c, 0x6c, 0x6f, 0x1a, 0x2e, 0x0a, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x25, 0x0a, 0x0e,
-	0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x53, 0x65, 0x63,
-	0x72, 0x65, 0x74, 0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x22, 0xca, 0x02, 0x0a, 0x13, 0x48, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x05, 0x68, 0x65, 0x6c, 0x6c,
-	0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x43, 0x6f,
-	0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x48, 0x65,
-	0x6c, 0x6c, 0x6f, 0x48, 0x00, 0x52, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x5b, 0x0a, 0x14,
-	0x64, 0x61, 0x74, 0x61, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x72, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x48, 0x6f, 0x73,
-	0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x2e, 0x44, 0x61, 0x74, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x12, 0x64, 0x61, 0x74, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x6e,
-	0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x0a, 0x05, 0x48, 0x65, 0x6c,
-	0x6c, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x1a, 0x72, 0x0a, 0x12,
-	0x44, 0x61, 0x74, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x46, 0x0a, 0x14, 0x72, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
-	0x6c, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x13, 0x72, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73,
-	0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xa8, 0x01,
-	0x0a, 0x0f, 0x48, 0x6f, 0x73, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x2e, 0x0a, 0x05, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x16, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x2e, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x48, 0x00, 0x52, 0x05, 0x68, 0x65, 0x6c, 0x6c,
-	0x6f, 0x12, 0x1f, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70,
-	0x75, 0x74, 0x1a, 0x37, 0x0a, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x6c,
-	0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f,
-	0x63, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x42, 0x0b, 0x0a, 0x09, 0x6f,
-	0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x82, 0x01, 0x0a, 0x10, 0x48, 0x6f, 0x73,
-	0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a,
-	0x11, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x5f, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f,
-	0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69,
-	0x6e, 0x61, 0x6c, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x48, 0x00, 0x52,
-	0x10, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e,
-	0x73, 0x12, 0x1d, 0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x05, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74,
-	0x42, 0x0b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x5a, 0x0a,
-	0x12, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x44, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69,
-	0x6f, 0x6e, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x77, 0x69, 0x64, 0x74, 0x68, 0x5f, 0x63, 0x6f, 0x6c,
-	0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x77, 0x69, 0x64, 0x74,
-	0x68, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x68, 0x65, 0x69, 0x67,
-	0x68, 0x74, 0x5f, 0x72, 0x6f, 0x77, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x68,
-	0x65, 0x69, 0x67, 0x68, 0x74, 0x52, 0x6f, 0x77, 0x73, 0x22, 0x1a, 0x0a, 0x04, 0x44, 0x61, 0x74,
-	0x61, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x21, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x18,
-	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x32, 0x54, 0x0a, 0x0c, 0x47, 0x75, 0x65, 0x73,
-	0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x44, 0x0a, 0x0f, 0x54, 0x65, 0x72, 0x6d,
-	0x69, 0x6e, 0x61, 0x6c, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x15, 0x2e, 0x47, 0x75,
-	0x65, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x47, 0x75, 0x65, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
-	0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x32, 0x86,
-	0x01, 0x0a, 0x0b, 0x48, 0x6f, 0x73, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3f,
-	0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
-	0x12, 0x13, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74,
-	0x72, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x12,
-	0x36, 0x0a, 0x0b, 0x44, 0x61, 0x74, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x10,
-	0x2e, 0x48, 0x6f, 0x73, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x11, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75,
-	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x69, 0x72, 0x72, 0x75, 0x73, 0x6c, 0x61, 0x62, 0x73,
-	0x2f, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e,
-	0x61, 0x6c, 0x2f, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
-
-var (
-	file_terminal_proto_rawDescOnce sync.Once
-	file_terminal_proto_rawDescData = file_terminal_proto_rawDesc
-)
-
-func file_terminal_proto_rawDescGZIP() []byte {
-	file_terminal_proto_rawDescOnce.Do(func() {
-		file_terminal_proto_rawDescData = protoimpl.X.CompressGZIP(file_terminal_proto_rawDescData)
-	})
-	return file_terminal_proto_rawDescData
-}
-
-var file_terminal_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
-var file_terminal_proto_goTypes = []interface{}{
-	(*GuestTerminalRequest)(nil),                   // 0: GuestTerminalRequest
-	(*GuestTerminalResponse)(nil),                  // 1: GuestTerminalResponse
-	(*HostControlRequest)(nil),                     // 2: HostControlRequest
-	(*HostControlResponse)(nil),                    // 3: HostControlResponse
-	(*HostDataRequest)(nil),                        // 4: HostDataRequest
-	(*HostDataResponse)(nil),                       // 5: HostDataResponse
-	(*TerminalDimensions)(nil),                     // 6: TerminalDimensions
-	(*Data)(nil),                                   // 7: Data
-	(*Error)(nil),                                  // 8: Error
-	(*GuestTerminalRequest_Hello)(nil),             // 9: GuestTerminalRequest.Hello
-	(*HostControlRequest_Hello)(nil),               // 10: HostControlRequest.Hello
-	(*HostControlResponse_Hello)(nil),              // 11: HostControlResponse.Hello
-	(*HostControlResponse_DataChannelRequest)(nil), // 12: HostControlResponse.DataChannelRequest
-	(*HostDataRequest_Hello)(nil),                  // 13: HostDataRequest.Hello
-}
-var file_terminal_proto_depIdxs = []int32{
-	9,  // 0: GuestTerminalRequest.hello:type_name -> GuestTerminalRequest.Hello
-	6,  // 1: GuestTerminalRequest.change_dimensions:type_name -> TerminalDimensions
-	7,  // 2: GuestTerminalRequest.input:type_name -> Data
-	7,  // 3: GuestTerminalResponse.output:type_name -> Data
-	10, // 4: HostControlRequest.hello:type_name -> HostControlRequest.Hello
-	11, // 5: HostControlResponse.hello:type_name -> HostControlResponse.Hello
-	12, // 6: HostControlResponse.data_channel_request:type_name -> HostControlResponse.DataChannelRequest
-	13, // 7: HostDataRequest.hello:type_name -> HostDataRequest.Hello
-	7,  // 8: HostDataRequest.output:type_name -> Data
-	6,  // 9: HostDataResponse.change_dimensions:type_name -> TerminalDimensions
-	7,  // 10: HostDataResponse.input:type_name -> Data
-	6,  // 11: GuestTerminalRequest.Hello.requested_dimensions:type_name -> TerminalDimensions
-	6,  // 12: HostControlResponse.DataChannelRequest.requested_dimensions:type_name -> TerminalDimensions
-	0,  // 13: GuestService.TerminalChannel:input_type -> GuestTerminalRequest
-	2,  // 14: HostService.ControlChannel:input_type -> HostControlRequest
-	4,  // 15: HostService.DataChannel:input_type -> HostDataRequest
-	1,  // 16: GuestService.TerminalChannel:output_type -> GuestTerminalResponse
-	3,  // 17: HostService.ControlChannel:output_type -> HostControlResponse
-	5,  // 18: HostService.DataChannel:output_type -> HostDataResponse
-	16, // [16:19] is the sub-list for method output_type
-	13, // [13:16] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
-}
-
-func init() { file_terminal_proto_init() }
-func file_terminal_proto_init() {
-	if File_terminal_proto != nil {
-		return
-	}
-	if !protoimpl.UnsafeEnabled {
-		file_terminal_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GuestTerminalRequest); i {
+		file_terminal_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FileTrailer); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1104,8 +4415,8 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GuestTerminalResponse); i {
+		file_terminal_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GuestFileTransferRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1116,8 +4427,8 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HostControlRequest); i {
+		file_terminal_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GuestFileTransferResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1128,8 +4439,8 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HostControlResponse); i {
+		file_terminal_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostFileTransferRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1140,8 +4451,8 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HostDataRequest); i {
+		file_terminal_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostFileTransferResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1152,8 +4463,8 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HostDataResponse); i {
+		file_terminal_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShellOverride); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1164,8 +4475,8 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TerminalDimensions); i {
+		file_terminal_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExitStatus); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1176,8 +4487,8 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Data); i {
+		file_terminal_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Termination); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1188,7 +4499,7 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+		file_terminal_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Error); i {
 			case 0:
 				return &v.state
@@ -1200,7 +4511,7 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+		file_terminal_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GuestTerminalRequest_Hello); i {
 			case 0:
 				return &v.state
@@ -1212,7 +4523,19 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+		file_terminal_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GuestTerminalResponse_Hello); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*HostControlRequest_Hello); i {
 			case 0:
 				return &v.state
@@ -1224,7 +4547,7 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+		file_terminal_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*HostControlResponse_Hello); i {
 			case 0:
 				return &v.state
@@ -1236,7 +4559,7 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+		file_terminal_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*HostControlResponse_DataChannelRequest); i {
 			case 0:
 				return &v.state
@@ -1248,7 +4571,43 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
-		file_terminal_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+		file_terminal_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostControlResponse_TunnelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostControlResponse_FileTransferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostControlResponse_Drain); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*HostDataRequest_Hello); i {
 			case 0:
 				return &v.state
@@ -1260,42 +4619,159 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
+		file_terminal_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GuestTunnelRequest_Hello); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GuestTunnelResponse_Hello); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostTunnelRequest_Hello); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GuestFileTransferRequest_Hello); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostFileTransferRequest_Hello); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_terminal_proto_msgTypes[0].OneofWrappers = []interface{}{
 		(*GuestTerminalRequest_Hello_)(nil),
 		(*GuestTerminalRequest_ChangeDimensions)(nil),
 		(*GuestTerminalRequest_Input)(nil),
+		(*GuestTerminalRequest_CloseInput)(nil),
 	}
 	file_terminal_proto_msgTypes[1].OneofWrappers = []interface{}{
 		(*GuestTerminalResponse_Output)(nil),
+		(*GuestTerminalResponse_Hello_)(nil),
+		(*GuestTerminalResponse_ErrorOutput)(nil),
+		(*GuestTerminalResponse_Termination)(nil),
+		(*GuestTerminalResponse_OutputTruncated)(nil),
+		(*GuestTerminalResponse_InputIgnored)(nil),
+	}
+	file_terminal_proto_msgTypes[5].OneofWrappers = []interface{}{
+		(*ReplayResponse_Output)(nil),
+		(*ReplayResponse_ChangeDimensions)(nil),
+		(*ReplayResponse_Input)(nil),
 	}
-	file_terminal_proto_msgTypes[2].OneofWrappers = []interface{}{
+	file_terminal_proto_msgTypes[6].OneofWrappers = []interface{}{
 		(*HostControlRequest_Hello_)(nil),
 	}
-	file_terminal_proto_msgTypes[3].OneofWrappers = []interface{}{
+	file_terminal_proto_msgTypes[7].OneofWrappers = []interface{}{
 		(*HostControlResponse_Hello_)(nil),
 		(*HostControlResponse_DataChannelRequest_)(nil),
+		(*HostControlResponse_TunnelRequest_)(nil),
+		(*HostControlResponse_FileTransferRequest_)(nil),
+		(*HostControlResponse_Drain_)(nil),
 	}
-	file_terminal_proto_msgTypes[4].OneofWrappers = []interface{}{
+	file_terminal_proto_msgTypes[8].OneofWrappers = []interface{}{
 		(*HostDataRequest_Hello_)(nil),
 		(*HostDataRequest_Output)(nil),
+		(*HostDataRequest_ErrorOutput)(nil),
+		(*HostDataRequest_Termination)(nil),
 	}
-	file_terminal_proto_msgTypes[5].OneofWrappers = []interface{}{
+	file_terminal_proto_msgTypes[9].OneofWrappers = []interface{}{
 		(*HostDataResponse_ChangeDimensions)(nil),
 		(*HostDataResponse_Input)(nil),
+		(*HostDataResponse_CloseInput)(nil),
+		(*HostDataResponse_WindowUpdate)(nil),
+	}
+	file_terminal_proto_msgTypes[13].OneofWrappers = []interface{}{
+		(*GuestTunnelRequest_Hello_)(nil),
+		(*GuestTunnelRequest_Data)(nil),
+	}
+	file_terminal_proto_msgTypes[14].OneofWrappers = []interface{}{
+		(*GuestTunnelResponse_Hello_)(nil),
+		(*GuestTunnelResponse_Data)(nil),
+		(*GuestTunnelResponse_CloseWrite)(nil),
+	}
+	file_terminal_proto_msgTypes[15].OneofWrappers = []interface{}{
+		(*HostTunnelRequest_Hello_)(nil),
+		(*HostTunnelRequest_Data)(nil),
+	}
+	file_terminal_proto_msgTypes[16].OneofWrappers = []interface{}{
+		(*HostTunnelResponse_Data)(nil),
+		(*HostTunnelResponse_CloseWrite)(nil),
+	}
+	file_terminal_proto_msgTypes[20].OneofWrappers = []interface{}{
+		(*GuestFileTransferRequest_Hello_)(nil),
+		(*GuestFileTransferRequest_Chunk)(nil),
+		(*GuestFileTransferRequest_Trailer)(nil),
+	}
+	file_terminal_proto_msgTypes[21].OneofWrappers = []interface{}{
+		(*GuestFileTransferResponse_Header)(nil),
+		(*GuestFileTransferResponse_Chunk)(nil),
+		(*GuestFileTransferResponse_Trailer)(nil),
+	}
+	file_terminal_proto_msgTypes[22].OneofWrappers = []interface{}{
+		(*HostFileTransferRequest_Hello_)(nil),
+		(*HostFileTransferRequest_Header)(nil),
+		(*HostFileTransferRequest_Chunk)(nil),
+		(*HostFileTransferRequest_Trailer)(nil),
+		(*HostFileTransferRequest_Error)(nil),
+	}
+	file_terminal_proto_msgTypes[23].OneofWrappers = []interface{}{
+		(*HostFileTransferResponse_Chunk)(nil),
+		(*HostFileTransferResponse_Trailer)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_terminal_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   14,
+			NumEnums:      3,
+			NumMessages:   42,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
 		GoTypes:           file_terminal_proto_goTypes,
 		DependencyIndexes: file_terminal_proto_depIdxs,
+		EnumInfos:         file_terminal_proto_enumTypes,
 		MessageInfos:      file_terminal_proto_msgTypes,
 	}.Build()
 	File_terminal_proto = out.File
@@ -19,6 +19,12 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type GuestServiceClient interface {
 	TerminalChannel(ctx context.Context, opts ...grpc.CallOption) (GuestService_TerminalChannelClient, error)
+	// Streams a finished session recording back with its original timing, only available when the server records sessions
+	Replay(ctx context.Context, in *ReplayRequest, opts ...grpc.CallOption) (GuestService_ReplayClient, error)
+	// Proxies a TCP connection to a port on the Host's loopback interface
+	TunnelChannel(ctx context.Context, opts ...grpc.CallOption) (GuestService_TunnelChannelClient, error)
+	// Downloads a file from the Host or uploads a file to the Host
+	FileTransferChannel(ctx context.Context, opts ...grpc.CallOption) (GuestService_FileTransferChannelClient, error)
 }
 
 type guestServiceClient struct {
@@ -60,11 +66,111 @@ func (x *guestServiceTerminalChannelClient) Recv() (*GuestTerminalResponse, erro
 	return m, nil
 }
 
+func (c *guestServiceClient) Replay(ctx context.Context, in *ReplayRequest, opts ...grpc.CallOption) (GuestService_ReplayClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GuestService_ServiceDesc.Streams[1], "/GuestService/Replay", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &guestServiceReplayClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GuestService_ReplayClient interface {
+	Recv() (*ReplayResponse, error)
+	grpc.ClientStream
+}
+
+type guestServiceReplayClient struct {
+	grpc.ClientStream
+}
+
+func (x *guestServiceReplayClient) Recv() (*ReplayResponse, error) {
+	m := new(ReplayResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *guestServiceClient) TunnelChannel(ctx context.Context, opts ...grpc.CallOption) (GuestService_TunnelChannelClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GuestService_ServiceDesc.Streams[2], "/GuestService/TunnelChannel", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &guestServiceTunnelChannelClient{stream}
+	return x, nil
+}
+
+type GuestService_TunnelChannelClient interface {
+	Send(*GuestTunnelRequest) error
+	Recv() (*GuestTunnelResponse, error)
+	grpc.ClientStream
+}
+
+type guestServiceTunnelChannelClient struct {
+	grpc.ClientStream
+}
+
+func (x *guestServiceTunnelChannelClient) Send(m *GuestTunnelRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *guestServiceTunnelChannelClient) Recv() (*GuestTunnelResponse, error) {
+	m := new(GuestTunnelResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *guestServiceClient) FileTransferChannel(ctx context.Context, opts ...grpc.CallOption) (GuestService_FileTransferChannelClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GuestService_ServiceDesc.Streams[3], "/GuestService/FileTransferChannel", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &guestServiceFileTransferChannelClient{stream}
+	return x, nil
+}
+
+type GuestService_FileTransferChannelClient interface {
+	Send(*GuestFileTransferRequest) error
+	Recv() (*GuestFileTransferResponse, error)
+	grpc.ClientStream
+}
+
+type guestServiceFileTransferChannelClient struct {
+	grpc.ClientStream
+}
+
+func (x *guestServiceFileTransferChannelClient) Send(m *GuestFileTransferRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *guestServiceFileTransferChannelClient) Recv() (*GuestFileTransferResponse, error) {
+	m := new(GuestFileTransferResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // GuestServiceServer is the server API for GuestService service.
 // All implementations must embed UnimplementedGuestServiceServer
 // for forward compatibility
 type GuestServiceServer interface {
 	TerminalChannel(GuestService_TerminalChannelServer) error
+	// Streams a finished session recording back with its original timing, only available when the server records sessions
+	Replay(*ReplayRequest, GuestService_ReplayServer) error
+	// Proxies a TCP connection to a port on the Host's loopback interface
+	TunnelChannel(GuestService_TunnelChannelServer) error
+	// Downloads a file from the Host or uploads a file to the Host
+	FileTransferChannel(GuestService_FileTransferChannelServer) error
 	mustEmbedUnimplementedGuestServiceServer()
 }
 
@@ -75,6 +181,15 @@ type UnimplementedGuestServiceServer struct {
 func (UnimplementedGuestServiceServer) TerminalChannel(GuestService_TerminalChannelServer) error {
 	return status.Errorf(codes.Unimplemented, "method TerminalChannel not implemented")
 }
+func (UnimplementedGuestServiceServer) Replay(*ReplayRequest, GuestService_ReplayServer) error {
+	return status.Errorf(codes.Unimplemented, "method Replay not implemented")
+}
+func (UnimplementedGuestServiceServer) TunnelChannel(GuestService_TunnelChannelServer) error {
+	return status.Errorf(codes.Unimplemented, "method TunnelChannel not implemented")
+}
+func (UnimplementedGuestServiceServer) FileTransferChannel(GuestService_FileTransferChannelServer) error {
+	return status.Errorf(codes.Unimplemented, "method FileTransferChannel not implemented")
+}
 func (UnimplementedGuestServiceServer) mustEmbedUnimplementedGuestServiceServer() {}
 
 // UnsafeGuestServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -114,6 +229,79 @@ func (x *guestServiceTerminalChannelServer) Recv() (*GuestTerminalRequest, error
 	return m, nil
 }
 
+func _GuestService_Replay_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReplayRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GuestServiceServer).Replay(m, &guestServiceReplayServer{stream})
+}
+
+type GuestService_ReplayServer interface {
+	Send(*ReplayResponse) error
+	grpc.ServerStream
+}
+
+type guestServiceReplayServer struct {
+	grpc.ServerStream
+}
+
+func (x *guestServiceReplayServer) Send(m *ReplayResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GuestService_TunnelChannel_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GuestServiceServer).TunnelChannel(&guestServiceTunnelChannelServer{stream})
+}
+
+type GuestService_TunnelChannelServer interface {
+	Send(*GuestTunnelResponse) error
+	Recv() (*GuestTunnelRequest, error)
+	grpc.ServerStream
+}
+
+type guestServiceTunnelChannelServer struct {
+	grpc.ServerStream
+}
+
+func (x *guestServiceTunnelChannelServer) Send(m *GuestTunnelResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *guestServiceTunnelChannelServer) Recv() (*GuestTunnelRequest, error) {
+	m := new(GuestTunnelRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _GuestService_FileTransferChannel_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GuestServiceServer).FileTransferChannel(&guestServiceFileTransferChannelServer{stream})
+}
+
+type GuestService_FileTransferChannelServer interface {
+	Send(*GuestFileTransferResponse) error
+	Recv() (*GuestFileTransferRequest, error)
+	grpc.ServerStream
+}
+
+type guestServiceFileTransferChannelServer struct {
+	grpc.ServerStream
+}
+
+func (x *guestServiceFileTransferChannelServer) Send(m *GuestFileTransferResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *guestServiceFileTransferChannelServer) Recv() (*GuestFileTransferRequest, error) {
+	m := new(GuestFileTransferRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // GuestService_ServiceDesc is the grpc.ServiceDesc for GuestService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -128,6 +316,23 @@ var GuestService_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Replay",
+			Handler:       _GuestService_Replay_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "TunnelChannel",
+			Handler:       _GuestService_TunnelChannel_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "FileTransferChannel",
+			Handler:       _GuestService_FileTransferChannel_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "terminal.proto",
 }
@@ -138,6 +343,10 @@ var GuestService_ServiceDesc = grpc.ServiceDesc{
 type HostServiceClient interface {
 	ControlChannel(ctx context.Context, opts ...grpc.CallOption) (HostService_ControlChannelClient, error)
 	DataChannel(ctx context.Context, opts ...grpc.CallOption) (HostService_DataChannelClient, error)
+	// Opened by the Host in response to the TunnelRequest to carry the tunneled TCP connection
+	TunnelDataChannel(ctx context.Context, opts ...grpc.CallOption) (HostService_TunnelDataChannelClient, error)
+	// Opened by the Host in response to the FileTransferRequest to carry the file contents
+	FileTransferDataChannel(ctx context.Context, opts ...grpc.CallOption) (HostService_FileTransferDataChannelClient, error)
 }
 
 type hostServiceClient struct {
@@ -210,12 +419,78 @@ func (x *hostServiceDataChannelClient) Recv() (*HostDataResponse, error) {
 	return m, nil
 }
 
+func (c *hostServiceClient) TunnelDataChannel(ctx context.Context, opts ...grpc.CallOption) (HostService_TunnelDataChannelClient, error) {
+	stream, err := c.cc.NewStream(ctx, &HostService_ServiceDesc.Streams[2], "/HostService/TunnelDataChannel", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &hostServiceTunnelDataChannelClient{stream}
+	return x, nil
+}
+
+type HostService_TunnelDataChannelClient interface {
+	Send(*HostTunnelRequest) error
+	Recv() (*HostTunnelResponse, error)
+	grpc.ClientStream
+}
+
+type hostServiceTunnelDataChannelClient struct {
+	grpc.ClientStream
+}
+
+func (x *hostServiceTunnelDataChannelClient) Send(m *HostTunnelRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *hostServiceTunnelDataChannelClient) Recv() (*HostTunnelResponse, error) {
+	m := new(HostTunnelResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *hostServiceClient) FileTransferDataChannel(ctx context.Context, opts ...grpc.CallOption) (HostService_FileTransferDataChannelClient, error) {
+	stream, err := c.cc.NewStream(ctx, &HostService_ServiceDesc.Streams[3], "/HostService/FileTransferDataChannel", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &hostServiceFileTransferDataChannelClient{stream}
+	return x, nil
+}
+
+type HostService_FileTransferDataChannelClient interface {
+	Send(*HostFileTransferRequest) error
+	Recv() (*HostFileTransferResponse, error)
+	grpc.ClientStream
+}
+
+type hostServiceFileTransferDataChannelClient struct {
+	grpc.ClientStream
+}
+
+func (x *hostServiceFileTransferDataChannelClient) Send(m *HostFileTransferRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *hostServiceFileTransferDataChannelClient) Recv() (*HostFileTransferResponse, error) {
+	m := new(HostFileTransferResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // HostServiceServer is the server API for HostService service.
 // All implementations must embed UnimplementedHostServiceServer
 // for forward compatibility
 type HostServiceServer interface {
 	ControlChannel(HostService_ControlChannelServer) error
 	DataChannel(HostService_DataChannelServer) error
+	// Opened by the Host in response to the TunnelRequest to carry the tunneled TCP connection
+	TunnelDataChannel(HostService_TunnelDataChannelServer) error
+	// Opened by the Host in response to the FileTransferRequest to carry the file contents
+	FileTransferDataChannel(HostService_FileTransferDataChannelServer) error
 	mustEmbedUnimplementedHostServiceServer()
 }
 
@@ -229,6 +504,12 @@ func (UnimplementedHostServiceServer) ControlChannel(HostService_ControlChannelS
 func (UnimplementedHostServiceServer) DataChannel(HostService_DataChannelServer) error {
 	return status.Errorf(codes.Unimplemented, "method DataChannel not implemented")
 }
+func (UnimplementedHostServiceServer) TunnelDataChannel(HostService_TunnelDataChannelServer) error {
+	return status.Errorf(codes.Unimplemented, "method TunnelDataChannel not implemented")
+}
+func (UnimplementedHostServiceServer) FileTransferDataChannel(HostService_FileTransferDataChannelServer) error {
+	return status.Errorf(codes.Unimplemented, "method FileTransferDataChannel not implemented")
+}
 func (UnimplementedHostServiceServer) mustEmbedUnimplementedHostServiceServer() {}
 
 // UnsafeHostServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -294,6 +575,58 @@ func (x *hostServiceDataChannelServer) Recv() (*HostDataRequest, error) {
 	return m, nil
 }
 
+func _HostService_TunnelDataChannel_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(HostServiceServer).TunnelDataChannel(&hostServiceTunnelDataChannelServer{stream})
+}
+
+type HostService_TunnelDataChannelServer interface {
+	Send(*HostTunnelResponse) error
+	Recv() (*HostTunnelRequest, error)
+	grpc.ServerStream
+}
+
+type hostServiceTunnelDataChannelServer struct {
+	grpc.ServerStream
+}
+
+func (x *hostServiceTunnelDataChannelServer) Send(m *HostTunnelResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *hostServiceTunnelDataChannelServer) Recv() (*HostTunnelRequest, error) {
+	m := new(HostTunnelRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _HostService_FileTransferDataChannel_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(HostServiceServer).FileTransferDataChannel(&hostServiceFileTransferDataChannelServer{stream})
+}
+
+type HostService_FileTransferDataChannelServer interface {
+	Send(*HostFileTransferResponse) error
+	Recv() (*HostFileTransferRequest, error)
+	grpc.ServerStream
+}
+
+type hostServiceFileTransferDataChannelServer struct {
+	grpc.ServerStream
+}
+
+func (x *hostServiceFileTransferDataChannelServer) Send(m *HostFileTransferResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *hostServiceFileTransferDataChannelServer) Recv() (*HostFileTransferRequest, error) {
+	m := new(HostFileTransferRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // HostService_ServiceDesc is the grpc.ServiceDesc for HostService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -314,6 +647,18 @@ var HostService_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "TunnelDataChannel",
+			Handler:       _HostService_TunnelDataChannel_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "FileTransferDataChannel",
+			Handler:       _HostService_FileTransferDataChannel_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "terminal.proto",
 }
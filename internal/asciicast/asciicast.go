@@ -0,0 +1,41 @@
+// Package asciicast writes and reads the terminal session recordings
+// in asciicast v2 format (https://docs.asciinema.org/manual/asciicast/v2/),
+// which are made by the Hosts and the server alike.
+package asciicast
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalid = errors.New("invalid asciicast recording")
+
+const (
+	version = 2
+
+	// Maximum size of a single line in the asciicast recording when reading it
+	maxLineSize = 1024 * 1024
+)
+
+// Codes of the asciicast events.
+const (
+	EventOutput = "o"
+	EventInput  = "i"
+	EventResize = "r"
+)
+
+// Header describes the recorded terminal session.
+type Header struct {
+	WidthColumns uint32
+	HeightRows   uint32
+	Timestamp    time.Time
+	Env          map[string]string
+}
+
+type fileHeader struct {
+	Version   int               `json:"version"`
+	Width     uint32            `json:"width"`
+	Height    uint32            `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
@@ -0,0 +1,78 @@
+package asciicast_test
+
+import (
+	"bytes"
+	"github.com/cirruslabs/terminal/internal/asciicast"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestRoundTrip(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+
+	header := asciicast.Header{
+		WidthColumns: 80,
+		HeightRows:   24,
+		Timestamp:    time.Unix(1700000000, 0),
+		Env:          map[string]string{"TERM": "xterm"},
+	}
+
+	writer, err := asciicast.NewWriter(nopWriteCloser{buf}, header, true)
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Output([]byte("hello")))
+	require.NoError(t, writer.Input([]byte("ls\r")))
+	require.NoError(t, writer.Resize(100, 50))
+	require.NoError(t, writer.Close())
+
+	reader, err := asciicast.NewReader(buf)
+	require.NoError(t, err)
+	require.Equal(t, header, reader.Header())
+
+	var events []asciicast.Event
+
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		events = append(events, *event)
+	}
+
+	require.Len(t, events, 3)
+	require.Equal(t, asciicast.EventOutput, events[0].Code)
+	require.Equal(t, "hello", events[0].Data)
+	require.Equal(t, asciicast.EventInput, events[1].Code)
+	require.Equal(t, "ls\r", events[1].Data)
+	require.Equal(t, asciicast.EventResize, events[2].Code)
+
+	widthColumns, heightRows, err := events[2].Resize()
+	require.NoError(t, err)
+	require.EqualValues(t, 100, widthColumns)
+	require.EqualValues(t, 50, heightRows)
+}
+
+func TestReaderRejectsInvalidRecordings(t *testing.T) {
+	_, err := asciicast.NewReader(strings.NewReader(`{"version": 1, "width": 80, "height": 24}` + "\n"))
+	require.ErrorIs(t, err, asciicast.ErrInvalid)
+
+	reader, err := asciicast.NewReader(strings.NewReader(`{"version": 2, "width": 80, "height": 24}` + "\n" +
+		`[0.5, "o"]` + "\n"))
+	require.NoError(t, err)
+
+	_, err = reader.Next()
+	require.ErrorIs(t, err, asciicast.ErrInvalid)
+}
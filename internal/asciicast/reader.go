@@ -0,0 +1,109 @@
+package asciicast
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Event is a single event of the asciicast v2 recording.
+type Event struct {
+	// Time since the beginning of the recording
+	Time time.Duration
+
+	// Event code, e.g. "o" for the terminal output
+	Code string
+
+	Data string
+}
+
+// Resize parses the data of the resize event.
+func (event *Event) Resize() (uint32, uint32, error) {
+	var widthColumns, heightRows uint32
+
+	if _, err := fmt.Sscanf(event.Data, "%dx%d", &widthColumns, &heightRows); err != nil {
+		return 0, 0, fmt.Errorf("%w: malformed resize event %q", ErrInvalid, event.Data)
+	}
+
+	return widthColumns, heightRows, nil
+}
+
+// Reader reads the recordings written by the Writer.
+type Reader struct {
+	scanner *bufio.Scanner
+	header  Header
+}
+
+func NewReader(reader io.Reader) (*Reader, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, maxLineSize)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%w: missing header", ErrInvalid)
+	}
+
+	var asciicastHeader fileHeader
+
+	if err := json.Unmarshal(scanner.Bytes(), &asciicastHeader); err != nil {
+		return nil, fmt.Errorf("%w: malformed header: %v", ErrInvalid, err)
+	}
+
+	if asciicastHeader.Version != version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalid, asciicastHeader.Version)
+	}
+
+	return &Reader{
+		scanner: scanner,
+		header: Header{
+			WidthColumns: asciicastHeader.Width,
+			HeightRows:   asciicastHeader.Height,
+			Timestamp:    time.Unix(asciicastHeader.Timestamp, 0),
+			Env:          asciicastHeader.Env,
+		},
+	}, nil
+}
+
+func (reader *Reader) Header() Header {
+	return reader.header
+}
+
+// Next returns the next event of the recording or io.EOF when there are no more events.
+func (reader *Reader) Next() (*Event, error) {
+	if !reader.scanner.Scan() {
+		if err := reader.scanner.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, io.EOF
+	}
+
+	var event []interface{}
+
+	if err := json.Unmarshal(reader.scanner.Bytes(), &event); err != nil {
+		return nil, fmt.Errorf("%w: malformed event: %v", ErrInvalid, err)
+	}
+
+	if len(event) != 3 {
+		return nil, fmt.Errorf("%w: event should have 3 elements, got %d", ErrInvalid, len(event))
+	}
+
+	seconds, ok1 := event[0].(float64)
+	code, ok2 := event[1].(string)
+	data, ok3 := event[2].(string)
+
+	if !ok1 || !ok2 || !ok3 {
+		return nil, fmt.Errorf("%w: event has elements of unexpected types", ErrInvalid)
+	}
+
+	return &Event{
+		Time: time.Duration(seconds * float64(time.Second)),
+		Code: code,
+		Data: data,
+	}, nil
+}
@@ -0,0 +1,149 @@
+package asciicast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Writer writes the session events into the underlying writer in asciicast v2 format,
+// its methods may be called concurrently.
+type Writer struct {
+	lock sync.Mutex
+
+	writer      io.WriteCloser
+	start       time.Time
+	recordInput bool
+
+	// Incomplete UTF-8 sequences at the end of the previous
+	// chunks, since the events can only contain valid strings
+	pendingOutput []byte
+	pendingInput  []byte
+}
+
+// NewWriter writes the header into the writer, the terminal input is only
+// written when recordInput is true, since it may contain sensitive data like passwords.
+func NewWriter(writer io.WriteCloser, header Header, recordInput bool) (*Writer, error) {
+	recording := &Writer{
+		writer:      writer,
+		start:       time.Now(),
+		recordInput: recordInput,
+	}
+
+	timestamp := header.Timestamp
+	if timestamp.IsZero() {
+		timestamp = recording.start
+	}
+
+	asciicastHeader := fileHeader{
+		Version:   version,
+		Width:     header.WidthColumns,
+		Height:    header.HeightRows,
+		Timestamp: timestamp.Unix(),
+		Env:       header.Env,
+	}
+
+	if err := recording.writeLine(asciicastHeader); err != nil {
+		return nil, err
+	}
+
+	return recording, nil
+}
+
+func (recording *Writer) Output(data []byte) error {
+	recording.lock.Lock()
+	defer recording.lock.Unlock()
+
+	return recording.writeData(EventOutput, &recording.pendingOutput, data)
+}
+
+func (recording *Writer) Input(data []byte) error {
+	if !recording.recordInput {
+		return nil
+	}
+
+	recording.lock.Lock()
+	defer recording.lock.Unlock()
+
+	return recording.writeData(EventInput, &recording.pendingInput, data)
+}
+
+func (recording *Writer) Resize(widthColumns, heightRows uint32) error {
+	recording.lock.Lock()
+	defer recording.lock.Unlock()
+
+	return recording.writeEvent(EventResize, fmt.Sprintf("%dx%d", widthColumns, heightRows))
+}
+
+func (recording *Writer) Close() error {
+	recording.lock.Lock()
+	defer recording.lock.Unlock()
+
+	var result error
+
+	// Flush whatever is left, even if it's not a valid UTF-8
+	if len(recording.pendingOutput) != 0 {
+		result = recording.writeEvent(EventOutput, string(recording.pendingOutput))
+	}
+	if len(recording.pendingInput) != 0 {
+		if err := recording.writeEvent(EventInput, string(recording.pendingInput)); err != nil && result == nil {
+			result = err
+		}
+	}
+
+	if err := recording.writer.Close(); err != nil && result == nil {
+		result = err
+	}
+
+	return result
+}
+
+func (recording *Writer) writeData(code string, pending *[]byte, data []byte) error {
+	data = append(*pending, data...)
+
+	// Hold back the trailing incomplete UTF-8 sequence (if any) until the next chunk
+	complete := len(data) - incompleteSuffixLen(data)
+	*pending = append([]byte{}, data[complete:]...)
+
+	if complete == 0 {
+		return nil
+	}
+
+	return recording.writeEvent(code, string(data[:complete]))
+}
+
+func (recording *Writer) writeEvent(code string, data string) error {
+	return recording.writeLine([]interface{}{time.Since(recording.start).Seconds(), code, data})
+}
+
+func (recording *Writer) writeLine(value interface{}) error {
+	line, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = recording.writer.Write(append(line, '\n'))
+
+	return err
+}
+
+// incompleteSuffixLen returns the length of the incomplete
+// UTF-8 sequence at the end of the data, if any.
+func incompleteSuffixLen(data []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		suffix := data[len(data)-i:]
+
+		if utf8.RuneStart(suffix[0]) {
+			if utf8.FullRune(suffix) {
+				return 0
+			}
+
+			return i
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,139 @@
+package command
+
+import (
+	"errors"
+	"github.com/cirruslabs/terminal/pkg/guest"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"os/signal"
+)
+
+var attachServerAddress string
+var attachLocator string
+var attachSecret string
+var attachSessionID string
+var attachShell string
+var attachWorkingDirectory string
+
+func runAttach(cmd *cobra.Command, args []string) error {
+	logger, err := getLogger()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	opts := []guest.Option{
+		guest.WithLogger(logger),
+		guest.WithServerAddress(attachServerAddress),
+		guest.WithLocator(attachLocator),
+		guest.WithSecret(attachSecret),
+		guest.WithSessionID(attachSessionID),
+	}
+
+	if attachShell != "" {
+		opts = append(opts, guest.WithShell(attachShell))
+	}
+	if attachWorkingDirectory != "" {
+		opts = append(opts, guest.WithWorkingDirectory(attachWorkingDirectory))
+	}
+
+	stdinFd := int(os.Stdin.Fd())
+
+	if term.IsTerminal(stdinFd) {
+		if width, height, err := term.GetSize(stdinFd); err == nil {
+			opts = append(opts, guest.WithDimensions(uint32(width), uint32(height)))
+		}
+	}
+
+	terminalGuest, err := guest.New(cmd.Context(), opts...)
+	if err != nil {
+		return err
+	}
+	defer terminalGuest.Close()
+
+	if terminalGuest.ReadOnly() {
+		logger.Sugar().Infof("attached to session %s in read-only mode", terminalGuest.SessionID())
+	} else {
+		logger.Sugar().Infof("attached to session %s, other guests can join it using --session-id %s",
+			terminalGuest.SessionID(), terminalGuest.SessionID())
+	}
+
+	// Put the local terminal into raw mode so that
+	// the control sequences are passed to the Host as is
+	if term.IsTerminal(stdinFd) {
+		oldState, err := term.MakeRaw(stdinFd)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = term.Restore(stdinFd, oldState)
+		}()
+
+		// Synchronize terminal dimensions on the Host
+		resizeChan := make(chan os.Signal, 1)
+		notifyResize(resizeChan)
+		defer signal.Stop(resizeChan)
+
+		go func() {
+			for range resizeChan {
+				if width, height, err := term.GetSize(stdinFd); err == nil {
+					_ = terminalGuest.Resize(uint32(width), uint32(height))
+				}
+			}
+		}()
+	}
+
+	go func() {
+		_, _ = io.Copy(terminalGuest, os.Stdin)
+	}()
+
+	errChan := make(chan error, 1)
+
+	go func() {
+		_, err := io.Copy(os.Stdout, terminalGuest)
+		errChan <- err
+	}()
+
+	select {
+	case err := <-errChan:
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		return err
+	case <-cmd.Context().Done():
+		return nil
+	}
+}
+
+func newAttachCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attach [flags]",
+		Short: "Attach to a terminal running on the host, acting as a guest",
+		RunE:  runAttach,
+	}
+
+	cmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debugging")
+
+	cmd.PersistentFlags().StringVar(&attachServerAddress, "server-address", "https://terminal.cirrus-ci.com:443",
+		"terminal server address")
+	cmd.PersistentFlags().StringVar(&attachLocator, "locator", "",
+		"locator of the terminal to attach to")
+	cmd.PersistentFlags().StringVar(&attachSecret, "secret", "",
+		"secret of the terminal to attach to")
+	cmd.PersistentFlags().StringVar(&attachSessionID, "session-id", "",
+		"join an existing session with the specified ID instead of creating a new one")
+	cmd.PersistentFlags().StringVar(&attachShell, "shell", "",
+		"ask the host to run the specified shell instead of its default one")
+	cmd.PersistentFlags().StringVar(&attachWorkingDirectory, "working-directory", "",
+		"ask the host to start the shell in the specified directory")
+
+	_ = cmd.MarkPersistentFlagRequired("locator")
+	_ = cmd.MarkPersistentFlagRequired("secret")
+
+	return cmd
+}
@@ -0,0 +1,140 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/terminal/pkg/guest"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Prefix of the path on the host, e.g. "host:/var/log/syslog".
+const cpHostPrefix = "host:"
+
+var ErrInvalidCopy = errors.New("invalid copy specification")
+
+var cpServerAddress string
+var cpLocator string
+var cpSecret string
+
+func runCp(cmd *cobra.Command, args []string) error {
+	logger, err := getLogger()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	opts := []guest.Option{
+		guest.WithLogger(logger),
+		guest.WithServerAddress(cpServerAddress),
+		guest.WithLocator(cpLocator),
+		guest.WithSecret(cpSecret),
+	}
+
+	source, sourceOnHost := strings.CutPrefix(args[0], cpHostPrefix)
+	destination, destinationOnHost := strings.CutPrefix(args[1], cpHostPrefix)
+
+	switch {
+	case sourceOnHost && !destinationOnHost:
+		return downloadFile(cmd, source, destination, opts)
+	case !sourceOnHost && destinationOnHost:
+		return uploadFile(cmd, source, destination, opts)
+	default:
+		return fmt.Errorf("%w: exactly one of the paths should be prefixed with %q", ErrInvalidCopy, cpHostPrefix)
+	}
+}
+
+func downloadFile(cmd *cobra.Command, remotePath string, localPath string, opts []guest.Option) error {
+	if localPath == "-" {
+		_, err := guest.Download(cmd.Context(), remotePath, os.Stdout, opts...)
+
+		return err
+	}
+
+	if fileInfo, err := os.Stat(localPath); err == nil && fileInfo.IsDir() {
+		localPath = filepath.Join(localPath, filepath.Base(remotePath))
+	}
+
+	// Download into a temporary file first to avoid
+	// leaving a partially written file behind on failure
+	file, err := os.CreateTemp(filepath.Dir(localPath), "."+filepath.Base(localPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	fileInfo, err := guest.Download(cmd.Context(), remotePath, file, opts...)
+	if err != nil {
+		return err
+	}
+
+	if err := file.Chmod(fileInfo.Mode); err != nil {
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), localPath)
+}
+
+func uploadFile(cmd *cobra.Command, localPath string, remotePath string, opts []guest.Option) error {
+	if localPath == "-" {
+		return fmt.Errorf("%w: uploading from the standard input is not supported, "+
+			"since the size of the file should be known in advance", ErrInvalidCopy)
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if !fileInfo.Mode().IsRegular() {
+		return fmt.Errorf("%w: %q is not a regular file", ErrInvalidCopy, localPath)
+	}
+
+	// Like cp(1), copy into the directory when the path ends with a slash
+	if strings.HasSuffix(remotePath, "/") {
+		remotePath += filepath.Base(localPath)
+	}
+
+	return guest.Upload(cmd.Context(), remotePath, file, uint64(fileInfo.Size()),
+		fileInfo.Mode(), opts...)
+}
+
+func newCpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cp [flags] SOURCE DESTINATION",
+		Short: "Copy a file from or to the host, the path on the host should be prefixed with \"host:\"",
+		Example: "  terminal cp --locator LOCATOR --secret SECRET host:/var/log/syslog syslog\n" +
+			"  terminal cp --locator LOCATOR --secret SECRET fixture.json host:/tmp/",
+		Args: cobra.ExactArgs(2),
+		RunE: runCp,
+	}
+
+	cmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debugging")
+
+	cmd.PersistentFlags().StringVar(&cpServerAddress, "server-address", "https://terminal.cirrus-ci.com:443",
+		"terminal server address")
+	cmd.PersistentFlags().StringVar(&cpLocator, "locator", "",
+		"locator of the terminal whose host to copy the file from or to")
+	cmd.PersistentFlags().StringVar(&cpSecret, "secret", "",
+		"trusted secret of the terminal whose host to copy the file from or to")
+
+	_ = cmd.MarkPersistentFlagRequired("locator")
+	_ = cmd.MarkPersistentFlagRequired("secret")
+
+	return cmd
+}
@@ -0,0 +1,142 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/terminal/pkg/guest"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"io"
+	"os"
+)
+
+var execServerAddress string
+var execLocator string
+var execSecret string
+var execPTY bool
+var execWorkingDirectory string
+
+// ExitCodeError is returned when the remote command exits with a non-zero exit code,
+// which should be propagated as the exit code of this process.
+type ExitCodeError struct {
+	Code int
+}
+
+func (err *ExitCodeError) Error() string {
+	return fmt.Sprintf("command exited with code %d", err.Code)
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	logger, err := getLogger()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	opts := []guest.Option{
+		guest.WithLogger(logger),
+		guest.WithServerAddress(execServerAddress),
+		guest.WithLocator(execLocator),
+		guest.WithSecret(execSecret),
+		guest.WithCommand(args[0], args[1:], execPTY),
+		guest.WithErrorOutput(os.Stderr),
+	}
+
+	if execWorkingDirectory != "" {
+		opts = append(opts, guest.WithWorkingDirectory(execWorkingDirectory))
+	}
+
+	stdinFd := int(os.Stdin.Fd())
+	interactive := execPTY && term.IsTerminal(stdinFd)
+
+	if interactive {
+		if width, height, err := term.GetSize(stdinFd); err == nil {
+			opts = append(opts, guest.WithDimensions(uint32(width), uint32(height)))
+		}
+	}
+
+	terminalGuest, err := guest.New(cmd.Context(), opts...)
+	if err != nil {
+		return err
+	}
+	defer terminalGuest.Close()
+
+	// Put the local terminal into raw mode so that
+	// the control sequences are passed to the Host as is
+	if interactive {
+		oldState, err := term.MakeRaw(stdinFd)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = term.Restore(stdinFd, oldState)
+		}()
+	}
+
+	go func() {
+		if _, err := io.Copy(terminalGuest, os.Stdin); err != nil {
+			return
+		}
+
+		_ = terminalGuest.CloseWrite()
+	}()
+
+	errChan := make(chan error, 1)
+
+	go func() {
+		_, err := io.Copy(os.Stdout, terminalGuest)
+		errChan <- err
+	}()
+
+	select {
+	case err := <-errChan:
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	case <-cmd.Context().Done():
+		return cmd.Context().Err()
+	}
+
+	exitCode, ok := terminalGuest.ExitCode()
+	if !ok {
+		return fmt.Errorf("%w: session ended without an exit status", guest.ErrProtocol)
+	}
+
+	if exitCode != 0 {
+		return &ExitCodeError{Code: exitCode}
+	}
+
+	return nil
+}
+
+func newExecCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec [flags] -- command [args...]",
+		Short: "Run a command on the host and exit with its exit code",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runExec,
+		// The remote command's exit code is reported through the exit code of this process
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	cmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debugging")
+
+	cmd.PersistentFlags().StringVar(&execServerAddress, "server-address", "https://terminal.cirrus-ci.com:443",
+		"terminal server address")
+	cmd.PersistentFlags().StringVar(&execLocator, "locator", "",
+		"locator of the terminal to run the command on")
+	cmd.PersistentFlags().StringVar(&execSecret, "secret", "",
+		"secret of the terminal to run the command on")
+	cmd.PersistentFlags().BoolVar(&execPTY, "pty", false,
+		"allocate a PTY for the command, merging its standard output and standard error")
+	cmd.PersistentFlags().StringVar(&execWorkingDirectory, "working-directory", "",
+		"ask the host to run the command in the specified directory")
+
+	_ = cmd.MarkPersistentFlagRequired("locator")
+	_ = cmd.MarkPersistentFlagRequired("secret")
+
+	return cmd
+}
@@ -0,0 +1,202 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/terminal/pkg/guest"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidForward = errors.New("invalid forward specification")
+
+var forwardServerAddress string
+var forwardLocator string
+var forwardSecret string
+var forwardLocal []string
+
+// localForward is a parsed "-L [bind_address:]port:host:hostport" specification.
+type localForward struct {
+	listenAddress string
+	remotePort    uint16
+}
+
+func parseLocalForward(spec string) (*localForward, error) {
+	parts := strings.Split(spec, ":")
+
+	var bindAddress string
+
+	switch len(parts) {
+	case 3:
+		bindAddress = "localhost"
+	case 4:
+		bindAddress = parts[0]
+		parts = parts[1:]
+	default:
+		return nil, fmt.Errorf("%w: %q should be in the [bind_address:]port:host:hostport format",
+			ErrInvalidForward, spec)
+	}
+
+	localPort, err := strconv.ParseUint(parts[0], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid local port %q", ErrInvalidForward, parts[0])
+	}
+
+	// The Host only connects to its own loopback interface
+	switch parts[1] {
+	case "localhost", "127.0.0.1":
+	default:
+		return nil, fmt.Errorf("%w: only the host's loopback interface (localhost) can be forwarded to, got %q",
+			ErrInvalidForward, parts[1])
+	}
+
+	remotePort, err := strconv.ParseUint(parts[2], 10, 16)
+	if err != nil || remotePort == 0 {
+		return nil, fmt.Errorf("%w: invalid host port %q", ErrInvalidForward, parts[2])
+	}
+
+	return &localForward{
+		listenAddress: net.JoinHostPort(bindAddress, strconv.FormatUint(localPort, 10)),
+		remotePort:    uint16(remotePort),
+	}, nil
+}
+
+func runForward(cmd *cobra.Command, args []string) error {
+	logger, err := getLogger()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	if len(forwardLocal) == 0 {
+		return fmt.Errorf("%w: at least one -L should be specified", ErrInvalidForward)
+	}
+
+	var forwards []*localForward
+
+	for _, spec := range forwardLocal {
+		forward, err := parseLocalForward(spec)
+		if err != nil {
+			return err
+		}
+
+		forwards = append(forwards, forward)
+	}
+
+	opts := []guest.Option{
+		guest.WithLogger(logger),
+		guest.WithServerAddress(forwardServerAddress),
+		guest.WithLocator(forwardLocator),
+		guest.WithSecret(forwardSecret),
+	}
+
+	errChan := make(chan error, len(forwards))
+
+	for _, forward := range forwards {
+		// Listeners are closed on return, which unblocks the serveLocalForward() calls
+		listener, err := net.Listen("tcp", forward.listenAddress)
+		if err != nil {
+			return err
+		}
+		defer listener.Close()
+
+		logger.Sugar().Infof("forwarding %s to port %d on the host", listener.Addr(), forward.remotePort)
+
+		go func() {
+			errChan <- serveLocalForward(cmd.Context(), logger, listener, forward.remotePort, opts)
+		}()
+	}
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-cmd.Context().Done():
+		return cmd.Context().Err()
+	}
+}
+
+// serveLocalForward opens a new tunnel to the remote port for each accepted connection.
+func serveLocalForward(
+	ctx context.Context,
+	logger *zap.Logger,
+	listener net.Listener,
+	remotePort uint16,
+	opts []guest.Option,
+) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			defer conn.Close()
+
+			tunnel, err := guest.NewTunnel(ctx, remotePort, opts...)
+			if err != nil {
+				logger.Warn("failed to open a tunnel", zap.Uint16("port", remotePort), zap.Error(err))
+				return
+			}
+			defer tunnel.Close()
+
+			proxyTunnel(conn, tunnel)
+		}()
+	}
+}
+
+// proxyTunnel copies the data in both directions until both sides close their connections for writing.
+func proxyTunnel(conn net.Conn, tunnel *guest.Tunnel) {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		// Tear down the connection if the tunnel has failed
+		if _, err := io.Copy(conn, tunnel); err != nil {
+			_ = conn.Close()
+
+			return
+		}
+
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			_ = tcpConn.CloseWrite()
+		}
+	}()
+
+	_, _ = io.Copy(tunnel, conn)
+	_ = tunnel.CloseWrite()
+
+	<-done
+}
+
+func newForwardCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "forward [flags] -L [bind_address:]port:localhost:hostport",
+		Short: "Forward local TCP ports to the ports on the host's loopback interface",
+		Args:  cobra.NoArgs,
+		RunE:  runForward,
+	}
+
+	cmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debugging")
+
+	cmd.PersistentFlags().StringVar(&forwardServerAddress, "server-address", "https://terminal.cirrus-ci.com:443",
+		"terminal server address")
+	cmd.PersistentFlags().StringVar(&forwardLocator, "locator", "",
+		"locator of the terminal whose host to forward the ports to")
+	cmd.PersistentFlags().StringVar(&forwardSecret, "secret", "",
+		"trusted secret of the terminal whose host to forward the ports to")
+	cmd.PersistentFlags().StringArrayVarP(&forwardLocal, "local", "L", nil,
+		"forward the local port to the port on the host's loopback interface, can be specified multiple times")
+
+	_ = cmd.MarkPersistentFlagRequired("locator")
+	_ = cmd.MarkPersistentFlagRequired("secret")
+
+	return cmd
+}
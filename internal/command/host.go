@@ -1,13 +1,39 @@
 package command
 
 import (
+	"crypto/tls"
+	"fmt"
 	"github.com/cirruslabs/terminal/pkg/host"
+	"github.com/cirruslabs/terminal/pkg/host/recording"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"math"
+	"time"
 )
 
 var hostServerAddress string
 var hostTrustedSecret string
+var hostReadOnlySecret string
+var hostClientCertFile, hostClientKeyFile string
+var hostRootCAFile string
+var hostRecordingDir string
+var hostRecordInput bool
+var hostIdleTimeout time.Duration
+var hostMaxSessionDuration time.Duration
+var hostTimeoutWarning time.Duration
+var hostShell string
+var hostShellArgs []string
+var hostLoginShell bool
+var hostWorkingDirectory string
+var hostTerm string
+var hostAllowedShells []string
+var hostAllowedCommands []string
+var hostAllowedWorkingDirectories []string
+var hostAllowedTunnelPorts []uint
+var hostFileTransferRoots []string
+var hostSFTP bool
+var hostOutputCoalescingDelay time.Duration
+var hostCompression bool
 
 func runHost(cmd *cobra.Command, args []string) error {
 	logger, err := getLogger()
@@ -23,15 +49,65 @@ func runHost(cmd *cobra.Command, args []string) error {
 		logger.Sugar().Infof("genereated trusted secret: %s", hostTrustedSecret)
 	}
 
-	terminalHost, err := host.New(
+	var allowedTunnelPorts []uint16
+
+	for _, port := range hostAllowedTunnelPorts {
+		if port == 0 || port > math.MaxUint16 {
+			return fmt.Errorf("invalid tunnel port %d", port)
+		}
+
+		allowedTunnelPorts = append(allowedTunnelPorts, uint16(port))
+	}
+
+	opts := []host.Option{
 		host.WithLogger(logger),
 		host.WithServerAddress(hostServerAddress),
 		host.WithTrustedSecret(hostTrustedSecret),
+		host.WithReadOnlySecret(hostReadOnlySecret),
 		host.WithLocatorCallback(func(locator string) error {
 			logger.Sugar().Infof("received locator: %s", locator)
 			return nil
 		}),
-	)
+		host.WithIdleTimeout(hostIdleTimeout),
+		host.WithMaxSessionDuration(hostMaxSessionDuration),
+		host.WithTimeoutWarning(hostTimeoutWarning),
+		host.WithShell(hostShell, hostShellArgs...),
+		host.WithLoginShell(hostLoginShell),
+		host.WithWorkingDirectory(hostWorkingDirectory),
+		host.WithTerm(hostTerm),
+		host.WithAllowedShells(hostAllowedShells...),
+		host.WithAllowedCommands(hostAllowedCommands...),
+		host.WithAllowedWorkingDirectories(hostAllowedWorkingDirectories...),
+		host.WithAllowedTunnelPorts(allowedTunnelPorts...),
+		host.WithFileTransferRoots(hostFileTransferRoots...),
+		host.WithSFTP(hostSFTP),
+		host.WithOutputCoalescingDelay(hostOutputCoalescingDelay),
+		host.WithCompression(hostCompression),
+	}
+
+	if hostClientCertFile != "" || hostClientKeyFile != "" {
+		clientCertificate, err := tls.LoadX509KeyPair(hostClientCertFile, hostClientKeyFile)
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, host.WithClientCertificate(clientCertificate))
+	}
+
+	if hostRootCAFile != "" {
+		rootCAs, err := loadCertPool(hostRootCAFile)
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, host.WithRootCAs(rootCAs))
+	}
+
+	if hostRecordingDir != "" {
+		opts = append(opts, host.WithRecorder(recording.NewAsciicastRecorder(hostRecordingDir, hostRecordInput)))
+	}
+
+	terminalHost, err := host.New(opts...)
 	if err != nil {
 		return err
 	}
@@ -52,6 +128,56 @@ func newHostCmd() *cobra.Command {
 		"terminal server address")
 	cmd.PersistentFlags().StringVar(&hostTrustedSecret, "trusted-secret", "",
 		"trusted secret, a secure one is auto-generated by default")
+	cmd.PersistentFlags().StringVar(&hostReadOnlySecret, "read-only-secret", "",
+		"read-only secret that only allows observing the existing sessions, disabled by default")
+	cmd.PersistentFlags().StringVar(&hostClientCertFile, "client-cert-file", "",
+		"present the specified client certificate file to the server (must also specify --client-key-file)")
+	cmd.PersistentFlags().StringVar(&hostClientKeyFile, "client-key-file", "",
+		"present the client certificate with the specified key file (must also specify --client-cert-file)")
+	cmd.PersistentFlags().StringVar(&hostRootCAFile, "root-ca-file", "",
+		"verify the server's certificate against the CAs from the specified PEM bundle instead of the system ones")
+	cmd.PersistentFlags().StringVar(&hostRecordingDir, "recording-dir", "",
+		"record each session into an asciicast v2 file in the specified directory, disabled by default")
+	cmd.PersistentFlags().BoolVar(&hostRecordInput, "record-input", false,
+		"additionally record the terminal input, which may contain sensitive data like passwords")
+	cmd.PersistentFlags().DurationVar(&hostIdleTimeout, "idle-timeout", 0,
+		"close the sessions that haven't received any input for the specified duration, disabled by default")
+	cmd.PersistentFlags().DurationVar(&hostMaxSessionDuration, "max-session-duration", 0,
+		"close the sessions once they have been running for the specified duration, disabled by default")
+	cmd.PersistentFlags().DurationVar(&hostTimeoutWarning, "timeout-warning", time.Minute,
+		"warn about the session being closed due to one of the timeouts the specified duration in advance")
+	cmd.PersistentFlags().StringVar(&hostShell, "shell", "",
+		"shell to run, auto-detected by default")
+	cmd.PersistentFlags().StringArrayVar(&hostShellArgs, "shell-arg", nil,
+		"argument to pass to the shell, can be specified multiple times")
+	cmd.PersistentFlags().BoolVar(&hostLoginShell, "login-shell", false,
+		"start the shell as a login shell")
+	cmd.PersistentFlags().StringVar(&hostWorkingDirectory, "working-directory", "",
+		"directory to start the shell and the commands in, the current directory by default")
+	cmd.PersistentFlags().StringVar(&hostTerm, "term", "xterm",
+		"TERM value for the shell and the commands attached to a PTY")
+	cmd.PersistentFlags().StringSliceVar(&hostAllowedShells, "allowed-shell", nil,
+		"shell that the guests are allowed to request instead of the default one, can be specified multiple times")
+	cmd.PersistentFlags().StringSliceVar(&hostAllowedCommands, "allowed-command", nil,
+		"restrict the commands that the guests can run instead of the shell (see \"terminal exec\") to the "+
+			"specified one (matched by the exact name or path), can be specified multiple times, all the commands "+
+			"are allowed by default, note that allowing a shell (e.g. \"/bin/sh\") allows any command")
+	cmd.PersistentFlags().StringSliceVar(&hostAllowedWorkingDirectories, "allowed-working-directory", nil,
+		"directory (including its subdirectories) that the guests are allowed to request as a working directory, "+
+			"can be specified multiple times")
+	cmd.PersistentFlags().UintSliceVar(&hostAllowedTunnelPorts, "allowed-tunnel-port", nil,
+		"port on the loopback interface that the guests are allowed to open tunnels to "+
+			"(see \"terminal forward\"), can be specified multiple times")
+	cmd.PersistentFlags().StringSliceVar(&hostFileTransferRoots, "file-transfer-root", nil,
+		"directory (including its subdirectories) that the guests are allowed to download the files from "+
+			"and upload the files to (see \"terminal cp\"), can be specified multiple times")
+	cmd.PersistentFlags().BoolVar(&hostSFTP, "sftp", false,
+		"let the guests browse the --file-transfer-root directories, which are required, over SFTP "+
+			"with the permissions of the current user")
+	cmd.PersistentFlags().DurationVar(&hostOutputCoalescingDelay, "output-coalescing-delay", 5*time.Millisecond,
+		"how long to wait for more terminal output before sending it to the server, 0 to send it right away")
+	cmd.PersistentFlags().BoolVar(&hostCompression, "compression", true,
+		"compress the terminal output sent to the server when the server supports it")
 
 	return cmd
 }
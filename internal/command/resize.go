@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package command
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func notifyResize(resizeChan chan<- os.Signal) {
+	signal.Notify(resizeChan, syscall.SIGWINCH)
+}
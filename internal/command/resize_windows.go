@@ -0,0 +1,6 @@
+package command
+
+import "os"
+
+// There's no SIGWINCH on Windows.
+func notifyResize(resizeChan chan<- os.Signal) {}
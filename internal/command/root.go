@@ -11,6 +11,10 @@ func NewRootCmd() *cobra.Command {
 	cmd.AddCommand(
 		newServeCmd(),
 		newHostCmd(),
+		newAttachCmd(),
+		newExecCmd(),
+		newForwardCmd(),
+		newCpCmd(),
 	)
 
 	return cmd
@@ -7,19 +7,59 @@ import (
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"github.com/blendle/zapdriver"
 	"github.com/cirruslabs/terminal/internal/server"
+	"github.com/cirruslabs/terminal/internal/server/auth"
+	"github.com/cirruslabs/terminal/internal/server/registry"
+	"github.com/cirruslabs/terminal/internal/server/session"
+	"github.com/cirruslabs/terminal/internal/server/storage"
+	"github.com/redis/go-redis/v9"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
 	"math/big"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+var ErrInvalidFlags = errors.New("invalid flags")
+
 var debug bool
 var serverAddresses []string
 var tlsEphemeral bool
 var tlsCertFile, tlsKeyFile string
+var hostClientCAFile string
+var hostAddresses []string
+var locatorGracePeriod time.Duration
+var sessionGracePeriod time.Duration
+var outputBufferSize int
+var flowControlWindow int
+var guestBufferSize int
+var slowGuestPolicy string
+var observerSlowGuestPolicy string
+var minProtocolVersion uint32
+var drainTimeout time.Duration
+var guestHelloRate float64
+var guestHelloBurst int
+var lockoutThreshold int
+var clientIPHeader string
+var clientIPTrustedProxies int
+var maxSessionsPerTerminal int
+var guestJWKSFile string
+var sshAddress string
+var sshHostKeyFile string
+var metricsAddress string
+var registryRedisURL string
+var peerAddress string
+var peerSecret string
+var serverRecordingDir string
+var serverRecordInput bool
+var serverPreview bool
+var serverPreviewDomain string
 
 func getLogger() (*zap.Logger, error) {
 	if debug {
@@ -29,6 +69,22 @@ func getLogger() (*zap.Logger, error) {
 	return zap.NewProduction()
 }
 
+// loadCertPool loads the PEM-encoded CA certificates bundle.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	certPool := x509.NewCertPool()
+
+	if !certPool.AppendCertsFromPEM(pemBytes) {
+		return nil, fmt.Errorf("%w: no certificates found in %s", ErrInvalidFlags, path)
+	}
+
+	return certPool, nil
+}
+
 func runServe(cmd *cobra.Command, args []string) error {
 	var opts []server.Option
 
@@ -90,13 +146,128 @@ func runServe(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	opts = append(opts, server.WithTLSConfig(tlsConfig), server.WithAddresses(serverAddresses))
+	if outputBufferSize < 0 {
+		return fmt.Errorf("%w: --output-buffer-size can't be negative", ErrInvalidFlags)
+	}
+
+	parsedSlowGuestPolicy, err := session.ParseSlowGuestPolicy(slowGuestPolicy)
+	if err != nil {
+		return err
+	}
+
+	parsedObserverSlowGuestPolicy, err := session.ParseSlowGuestPolicy(observerSlowGuestPolicy)
+	if err != nil {
+		return err
+	}
+
+	if serverPreviewDomain != "" && !serverPreview {
+		return fmt.Errorf("%w: --preview-domain requires --preview", ErrInvalidFlags)
+	}
+
+	opts = append(opts, server.WithTLSConfig(tlsConfig), server.WithAddresses(serverAddresses),
+		server.WithLocatorGracePeriod(locatorGracePeriod), server.WithSessionGracePeriod(sessionGracePeriod),
+		server.WithOutputBufferSize(outputBufferSize), server.WithPreview(serverPreview),
+		server.WithPreviewDomain(serverPreviewDomain),
+		server.WithFlowControlWindow(flowControlWindow), server.WithGuestBufferSize(guestBufferSize),
+		server.WithSlowGuestPolicy(parsedSlowGuestPolicy),
+		server.WithObserverSlowGuestPolicy(parsedObserverSlowGuestPolicy),
+		server.WithMinProtocolVersion(minProtocolVersion),
+		server.WithDrainTimeout(drainTimeout), server.WithGuestHelloRateLimit(guestHelloRate, guestHelloBurst),
+		server.WithLockoutThreshold(lockoutThreshold), server.WithClientIPHeader(clientIPHeader, clientIPTrustedProxies),
+		server.WithMaxSessionsPerTerminal(maxSessionsPerTerminal))
+
+	if hostClientCAFile != "" {
+		if tlsConfig == nil {
+			return fmt.Errorf("%w: --host-client-ca-file requires TLS to be enabled", ErrInvalidFlags)
+		}
+
+		if len(hostAddresses) == 0 {
+			return fmt.Errorf("%w: --host-client-ca-file requires --host-listen to be specified", ErrInvalidFlags)
+		}
+
+		hostClientCAs, err := loadCertPool(hostClientCAFile)
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, server.WithHostClientCAs(hostClientCAs), server.WithHostAddresses(hostAddresses))
+	}
+
+	if guestJWKSFile != "" {
+		authenticator, err := auth.NewJWTFromFile(guestJWKSFile)
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, server.WithAuthenticator(authenticator))
+	}
+
+	if metricsAddress != "" {
+		opts = append(opts, server.WithMetricsAddress(metricsAddress))
+	}
+
+	if registryRedisURL != "" {
+		if peerAddress == "" {
+			return fmt.Errorf("%w: --registry-redis-url requires --peer-address to be specified", ErrInvalidFlags)
+		}
+
+		if peerSecret == "" {
+			return fmt.Errorf("%w: --registry-redis-url requires --peer-secret to be specified", ErrInvalidFlags)
+		}
+
+		redisOptions, err := redis.ParseURL(registryRedisURL)
+		if err != nil {
+			return err
+		}
+
+		redisClient := redis.NewClient(redisOptions)
+		defer redisClient.Close()
+
+		opts = append(opts, server.WithRegistry(registry.NewRedis(redisClient), peerAddress),
+			server.WithPeerSecret(peerSecret))
+	}
+
+	if sshAddress != "" {
+		opts = append(opts, server.WithSSHAddress(sshAddress))
+
+		if sshHostKeyFile != "" {
+			sshHostKeyBytes, err := os.ReadFile(sshHostKeyFile)
+			if err != nil {
+				return err
+			}
+
+			sshHostKey, err := ssh.ParsePrivateKey(sshHostKeyBytes)
+			if err != nil {
+				return err
+			}
+
+			opts = append(opts, server.WithSSHHostKey(sshHostKey))
+		}
+	}
+
+	if serverRecordingDir != "" {
+		opts = append(opts, server.WithRecordingStorage(storage.NewLocal(serverRecordingDir), serverRecordInput))
+	}
 
 	terminalServer, err := server.New(opts...)
 	if err != nil {
 		return err
 	}
 
+	// Drain the server on SIGTERM (e.g. when it's being re-deployed)
+	// instead of disconnecting all the hosts and the guests at once
+	sigtermChan := make(chan os.Signal, 1)
+	signal.Notify(sigtermChan, syscall.SIGTERM)
+	defer signal.Stop(sigtermChan)
+
+	go func() {
+		select {
+		case <-sigtermChan:
+			terminalServer.Drain()
+		case <-cmd.Context().Done():
+		}
+	}()
+
 	return terminalServer.Run(cmd.Context())
 }
 
@@ -123,6 +294,88 @@ func newServeCmd() *cobra.Command {
 		"enable TLS and use the specified certificate file (must also specify --tls-key-file)")
 	cmd.PersistentFlags().StringVar(&tlsKeyFile, "tls-key-file", "",
 		"enable TLS and use the specified key file (must also specify --tls-cert-file)")
+	cmd.PersistentFlags().StringVar(&hostClientCAFile, "host-client-ca-file", "",
+		"require the hosts to present a client certificate issued by one of the CAs from the specified "+
+			"PEM bundle (requires TLS and --host-listen)")
+	cmd.PersistentFlags().StringSliceVar(&hostAddresses, "host-listen", nil,
+		"addresses to listen on for the hosts, which are asked for the client certificates there, "+
+			"while the guests (e.g. the browsers) are served on the --listen addresses without being asked for one")
+
+	cmd.PersistentFlags().DurationVar(&locatorGracePeriod, "locator-grace-period", time.Minute,
+		"for how long to reserve the locator of a disconnected host in case it reconnects")
+	cmd.PersistentFlags().DurationVar(&sessionGracePeriod, "session-grace-period", 30*time.Second,
+		"for how long to keep the session of a disconnected guest alive in case it resumes")
+	cmd.PersistentFlags().IntVar(&outputBufferSize, "output-buffer-size", 64*1024,
+		"how many bytes of the most recent terminal output to keep for the resuming guests, 0 disables the replay")
+	cmd.PersistentFlags().IntVar(&flowControlWindow, "flow-control-window", 256*1024,
+		"how many bytes of the terminal output the hosts can send for each session before waiting "+
+			"for the server to pass them to the guests, 0 disables the flow control")
+	cmd.PersistentFlags().IntVar(&guestBufferSize, "guest-buffer-size", 1024*1024,
+		"how many bytes of the terminal output to queue for each guest before applying the --slow-guest-policy")
+	cmd.PersistentFlags().StringVar(&slowGuestPolicy, "slow-guest-policy", "block",
+		"what to do when the guest in control of the session can't keep up with the terminal output: "+
+			"\"block\" pauses the output for the whole session, \"drop-oldest\" drops the oldest output "+
+			"queued for that guest")
+	cmd.PersistentFlags().StringVar(&observerSlowGuestPolicy, "observer-slow-guest-policy", "drop-oldest",
+		"like --slow-guest-policy, but for the read-only guests, which shouldn't be able to pause the output "+
+			"for everyone by default")
+	cmd.PersistentFlags().Uint32Var(&minProtocolVersion, "min-protocol-version", 0,
+		"refuse the hosts and the guests implementing an older protocol version, "+
+			"0 accepts the ones predating the versioning too")
+	cmd.PersistentFlags().DurationVar(&drainTimeout, "drain-timeout", 20*time.Second,
+		"for how long to wait for the sessions to finish after receiving SIGTERM before terminating them")
+
+	cmd.PersistentFlags().Float64Var(&guestHelloRate, "guest-hello-rate", 50,
+		"how many guests per second can attempt to authenticate across all the terminals, 0 disables the limit")
+	cmd.PersistentFlags().IntVar(&guestHelloBurst, "guest-hello-burst", 100,
+		"how many guests can attempt to authenticate at once in excess of the --guest-hello-rate")
+	cmd.PersistentFlags().IntVar(&lockoutThreshold, "lockout-threshold", 5,
+		"after how many consecutive failed attempts to lock out the guest's IP address and the terminal "+
+			"it's trying to access for an exponentially growing period, 0 disables the lockout")
+	cmd.PersistentFlags().StringVar(&clientIPHeader, "client-ip-header", "",
+		"take the guests' IP addresses from the specified header (e.g. \"X-Forwarded-For\") "+
+			"set by the load balancer in front of the server")
+	cmd.PersistentFlags().IntVar(&clientIPTrustedProxies, "client-ip-trusted-proxies", 1,
+		"how many trusted proxies in front of the server append to the --client-ip-header, the guest's "+
+			"IP address is the one appended by the outermost of them, counting from the right")
+	cmd.PersistentFlags().IntVar(&maxSessionsPerTerminal, "max-sessions-per-terminal", 0,
+		"how many concurrent sessions each terminal can have, 0 means no limit")
+	cmd.PersistentFlags().StringVar(&guestJWKSFile, "guest-jwks-file", "",
+		"let the guests authenticate with the JWTs signed by the keys from the specified JWKS file "+
+			"instead of the terminal's secret")
+
+	cmd.PersistentFlags().StringVar(&metricsAddress, "metrics-listen", "",
+		"expose the Prometheus metrics on /metrics on the specified address (e.g. \":9090\"), disabled by default")
+
+	cmd.PersistentFlags().StringVar(&registryRedisURL, "registry-redis-url", "",
+		"share the locators between the replicas of the server through the specified Redis "+
+			"(e.g. \"redis://redis:6379/0\"), so that each replica can proxy the channels to the one owning "+
+			"the terminal, requires --peer-address and --peer-secret")
+	cmd.PersistentFlags().StringVar(&peerAddress, "peer-address", "",
+		"address on which the other replicas of the server can reach this one (e.g. \"http://10.0.0.1:8080\")")
+	cmd.PersistentFlags().StringVar(&peerSecret, "peer-secret", "",
+		"secret shared by all the replicas of the server to sign the guests' IP addresses passed along "+
+			"with the proxied channels, so that the guests are locked out by their own IP addresses")
+
+	cmd.PersistentFlags().StringVar(&sshAddress, "ssh-listen", "",
+		"enable SSH gateway for the guests on the specified address (e.g. \":2222\")")
+	cmd.PersistentFlags().StringVar(&sshHostKeyFile, "ssh-host-key-file", "",
+		"use the specified private key file as an SSH host key, an ephemeral one is generated by default")
+
+	cmd.PersistentFlags().StringVar(&serverRecordingDir, "recording-dir", "",
+		"record each session in the specified directory and allow replaying it, disabled by default")
+	cmd.PersistentFlags().BoolVar(&serverRecordInput, "record-input", false,
+		"additionally record the terminal input, which may contain sensitive data like passwords, "+
+			"and only replay it to the guests using the trusted secret")
+
+	cmd.PersistentFlags().BoolVar(&serverPreview, "preview", false,
+		"enable the HTTP preview proxy on /preview/{locator}/{port}/..., which forwards the requests "+
+			"to the allowed tunnel ports on the hosts, the previews share the server's origin and are "+
+			"therefore sandboxed, which some web applications don't work with (see --preview-domain)")
+	cmd.PersistentFlags().StringVar(&serverPreviewDomain, "preview-domain", "",
+		"serve the previews on {locator}.DOMAIN/{port}/... instead, so that each terminal's previews have "+
+			"their own origin, the domain and its subdomains should resolve to the server and only be used "+
+			"for the previews (requires --preview)")
 
 	return cmd
 }
@@ -0,0 +1,185 @@
+// Package compression compresses the Data message payloads with the
+// algorithm negotiated between the Host, the server and the Guest.
+//
+// Each message is compressed independently, so that the messages
+// can be dropped or replayed without breaking the decompression.
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// Smaller payloads rarely get any smaller, so they are sent as is
+	minCompressibleSize = 64
+
+	// Upper bound on the decompressed payload size, which matches
+	// the default maximum gRPC message size
+	maxDecompressedSize = 4 * 1024 * 1024
+)
+
+var (
+	ErrUnsupported = errors.New("unsupported compression")
+	ErrTooLarge    = errors.New("decompressed data is too large")
+)
+
+// Supported lists the supported compressions in the order of preference.
+var Supported = []api.Compression{api.Compression_ZSTD, api.Compression_DEFLATE}
+
+var zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+	return zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1))
+})
+
+var zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+	return zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(maxDecompressedSize))
+})
+
+var flateWriters = sync.Pool{
+	New: func() any {
+		// Only fails on an invalid level
+		writer, _ := flate.NewWriter(nil, flate.BestSpeed)
+
+		return writer
+	},
+}
+
+// Negotiate picks the first of the offered compressions that is supported,
+// falling back to COMPRESSION_NONE when there's none.
+func Negotiate(offered []api.Compression) api.Compression {
+	for _, compression := range offered {
+		if slices.Contains(Supported, compression) {
+			return compression
+		}
+	}
+
+	return api.Compression_COMPRESSION_NONE
+}
+
+// Compress compresses the data with the specified compression, the data is
+// left uncompressed when it's too small or when the compression doesn't help.
+func Compress(compression api.Compression, data []byte) *api.Data {
+	if compression == api.Compression_COMPRESSION_NONE || len(data) < minCompressibleSize {
+		return &api.Data{Data: data}
+	}
+
+	compressed, err := compress(compression, data)
+	if err != nil || len(compressed) >= len(data) {
+		return &api.Data{Data: data}
+	}
+
+	return &api.Data{
+		Data:        compressed,
+		Compression: compression,
+	}
+}
+
+func compress(compression api.Compression, data []byte) ([]byte, error) {
+	switch compression {
+	case api.Compression_ZSTD:
+		encoder, err := zstdEncoder()
+		if err != nil {
+			return nil, err
+		}
+
+		return encoder.EncodeAll(data, nil), nil
+	case api.Compression_DEFLATE:
+		var buf bytes.Buffer
+
+		writer := flateWriters.Get().(*flate.Writer)
+		defer flateWriters.Put(writer)
+
+		writer.Reset(&buf)
+
+		if _, err := writer.Write(data); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, compression)
+	}
+}
+
+// Decompress returns the decompressed payload of the Data message.
+func Decompress(data *api.Data) ([]byte, error) {
+	switch data.Compression {
+	case api.Compression_COMPRESSION_NONE:
+		return data.Data, nil
+	case api.Compression_ZSTD:
+		decoder, err := zstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+
+		decompressed, err := decoder.DecodeAll(data.Data, nil)
+		if err != nil {
+			if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+				return nil, ErrTooLarge
+			}
+
+			return nil, err
+		}
+
+		return decompressed, nil
+	case api.Compression_DEFLATE:
+		reader := flate.NewReader(bytes.NewReader(data.Data))
+		defer reader.Close()
+
+		decompressed, err := io.ReadAll(io.LimitReader(reader, maxDecompressedSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(decompressed) > maxDecompressedSize {
+			return nil, ErrTooLarge
+		}
+
+		return decompressed, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, data.Compression)
+	}
+}
+
+// Stats keeps track of how much the compression has saved.
+type Stats struct {
+	uncompressed atomic.Uint64
+	transferred  atomic.Uint64
+}
+
+// Record accounts for the payload that was uncompressedSize bytes long
+// before compression and transferredSize bytes long after it.
+func (stats *Stats) Record(uncompressedSize int, transferredSize int) {
+	stats.uncompressed.Add(uint64(uncompressedSize))
+	stats.transferred.Add(uint64(transferredSize))
+}
+
+// Uncompressed returns the total size of the payloads before compression.
+func (stats *Stats) Uncompressed() uint64 {
+	return stats.uncompressed.Load()
+}
+
+// Transferred returns the total size of the payloads after compression.
+func (stats *Stats) Transferred() uint64 {
+	return stats.transferred.Load()
+}
+
+// Saved returns the number of bytes the compression has saved.
+func (stats *Stats) Saved() uint64 {
+	uncompressed, transferred := stats.Uncompressed(), stats.Transferred()
+	if transferred > uncompressed {
+		return 0
+	}
+
+	return uncompressed - transferred
+}
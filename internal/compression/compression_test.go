@@ -0,0 +1,67 @@
+package compression_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/compression"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("$ ls -la\r\n"), 1000)
+
+	for _, algorithm := range compression.Supported {
+		t.Run(algorithm.String(), func(t *testing.T) {
+			compressed := compression.Compress(algorithm, data)
+			require.Equal(t, algorithm, compressed.Compression)
+			require.Less(t, len(compressed.Data), len(data))
+
+			decompressed, err := compression.Decompress(compressed)
+			require.NoError(t, err)
+			require.Equal(t, data, decompressed)
+		})
+	}
+}
+
+func TestIncompressibleDataIsSentAsIs(t *testing.T) {
+	random := make([]byte, 4096)
+	_, err := rand.Read(random)
+	require.NoError(t, err)
+
+	for _, data := range [][]byte{[]byte("a"), random} {
+		compressed := compression.Compress(api.Compression_ZSTD, data)
+		require.Equal(t, api.Compression_COMPRESSION_NONE, compressed.Compression)
+		require.Equal(t, data, compressed.Data)
+	}
+}
+
+func TestDecompressionIsBounded(t *testing.T) {
+	data := make([]byte, 8*1024*1024)
+
+	for _, algorithm := range compression.Supported {
+		t.Run(algorithm.String(), func(t *testing.T) {
+			_, err := compression.Decompress(compression.Compress(algorithm, data))
+			require.ErrorIs(t, err, compression.ErrTooLarge)
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	require.Equal(t, api.Compression_DEFLATE, compression.Negotiate([]api.Compression{
+		api.Compression(42), api.Compression_DEFLATE, api.Compression_ZSTD,
+	}))
+	require.Equal(t, api.Compression_COMPRESSION_NONE, compression.Negotiate(nil))
+}
+
+func TestStats(t *testing.T) {
+	var stats compression.Stats
+
+	stats.Record(100, 40)
+	stats.Record(10, 10)
+
+	require.EqualValues(t, 110, stats.Uncompressed())
+	require.EqualValues(t, 50, stats.Transferred())
+	require.EqualValues(t, 60, stats.Saved())
+}
@@ -0,0 +1,76 @@
+// Package protocol describes the protocol version and the optional features
+// (capabilities) that the Host, the server and the Guest announce in their
+// Hello messages, so that the peers of different ages can work together.
+package protocol
+
+import (
+	"slices"
+)
+
+// Version is the current protocol version. The peers predating
+// the versioning don't announce it, so it's zero for them.
+const Version = 1
+
+const (
+	// The Host runs the commands instead of the shell (see Command)
+	CapabilityCommand = "command"
+
+	// The Host runs the shell requested by the Guest (see ShellOverride)
+	CapabilityShellOverride = "shell-override"
+
+	// The Host honors the flow control window and the window updates
+	CapabilityFlowControl = "flow-control"
+
+	// The Host compresses the output, the Guest decompresses it
+	CapabilityCompression = "compression"
+
+	// The Host opens the tunnels to its loopback interface
+	CapabilityTunnel = "tunnel"
+
+	// The Host serves SFTP through a tunnel
+	CapabilitySFTP = "sftp"
+
+	// The Host transfers the files to and from the Guest
+	CapabilityFileTransfer = "file-transfer"
+
+	// The Host re-connects to another server when asked to (see Drain)
+	CapabilityDrain = "drain"
+
+	// The Guest understands the OutputTruncated messages
+	CapabilityOutputTruncated = "output-truncated"
+)
+
+// HostCapabilities are the capabilities that pkg/host supports.
+var HostCapabilities = []string{
+	CapabilityCommand,
+	CapabilityShellOverride,
+	CapabilityFlowControl,
+	CapabilityCompression,
+	CapabilityTunnel,
+	CapabilitySFTP,
+	CapabilityFileTransfer,
+	CapabilityDrain,
+}
+
+// GuestCapabilities are the capabilities that pkg/guest supports.
+var GuestCapabilities = []string{
+	CapabilityCompression,
+	CapabilityOutputTruncated,
+}
+
+// ServerCapabilities are the capabilities that the server supports.
+var ServerCapabilities = append(slices.Clone(HostCapabilities), CapabilityOutputTruncated)
+
+// Intersect returns the capabilities that are present in both lists,
+// ignoring the unknown ones and the duplicates.
+func Intersect(ours []string, theirs []string) []string {
+	var result []string
+
+	for _, capability := range ours {
+		if slices.Contains(theirs, capability) && !slices.Contains(result, capability) {
+			result = append(result, capability)
+		}
+	}
+
+	return result
+}
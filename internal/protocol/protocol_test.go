@@ -0,0 +1,17 @@
+package protocol_test
+
+import (
+	"github.com/cirruslabs/terminal/internal/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestIntersect(t *testing.T) {
+	require.Equal(t, []string{"a", "c"}, protocol.Intersect([]string{"a", "b", "c"}, []string{"c", "a", "a", "d"}))
+	require.Empty(t, protocol.Intersect([]string{"a"}, nil))
+}
+
+func TestServerSupportsAllCapabilities(t *testing.T) {
+	require.Subset(t, protocol.ServerCapabilities, protocol.HostCapabilities)
+	require.Subset(t, protocol.ServerCapabilities, protocol.GuestCapabilities)
+}
@@ -0,0 +1,27 @@
+// Package auth provides the authenticators that validate the alternative
+// credentials the Guests can present instead of the Host's secrets.
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Identity describes the Guest authenticated by an Authenticator.
+type Identity struct {
+	// Who the credentials were issued to, logged for auditing
+	Subject string
+
+	// Whether the Guest is only allowed to join the existing sessions
+	ReadOnly bool
+}
+
+// Authenticator validates the credentials presented by the Guests.
+type Authenticator interface {
+	// Authenticate returns the identity of the Guest presenting the credentials to access
+	// the terminal with the locator, or an error wrapping ErrInvalidCredentials if they're
+	// not valid for this terminal.
+	Authenticate(ctx context.Context, locator string, credentials string) (*Identity, error)
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Values of the "scope" claim, which is a space-separated list
+const (
+	ScopeReadOnly  = "read-only"
+	ScopeReadWrite = "read-write"
+)
+
+var ErrInvalidJWKS = errors.New("invalid JWKS")
+
+// Claims are the claims expected in the JWTs presented by the Guests.
+type Claims struct {
+	jwt.RegisteredClaims
+
+	// Locator of the terminal the token grants access to
+	Locator string `json:"locator"`
+
+	// ScopeReadWrite or ScopeReadOnly
+	Scope string `json:"scope"`
+}
+
+// JWT authenticates the Guests presenting the JWTs signed with HS256 or RS256 using one
+// of the keys from the JWKS. The tokens must carry the "locator" of the terminal, the expiry
+// ("exp"), the subject ("sub") and the "scope".
+type JWT struct {
+	keys map[string]interface{}
+}
+
+// NewJWTFromFile reads the JWKS with the symmetric ("oct") keys for HS256
+// and the public RSA ("RSA") keys for RS256 from the file.
+func NewJWTFromFile(path string) (*JWT, error) {
+	jwksBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewJWT(jwksBytes)
+}
+
+// NewJWT is like NewJWTFromFile, but takes the JWKS contents.
+func NewJWT(jwksBytes []byte) (*JWT, error) {
+	var jwks struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+
+			// Symmetric keys
+			K string `json:"k"`
+
+			// RSA keys
+			N string `json:"n"`
+			E string `json:"e"`
+		} `json:"keys"`
+	}
+
+	if err := json.Unmarshal(jwksBytes, &jwks); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWKS, err)
+	}
+
+	if len(jwks.Keys) == 0 {
+		return nil, fmt.Errorf("%w: no keys found", ErrInvalidJWKS)
+	}
+
+	keys := make(map[string]interface{})
+
+	for _, jwk := range jwks.Keys {
+		if _, ok := keys[jwk.KeyID]; ok {
+			return nil, fmt.Errorf("%w: duplicate key ID %q", ErrInvalidJWKS, jwk.KeyID)
+		}
+
+		switch jwk.KeyType {
+		case "oct":
+			key, err := base64.RawURLEncoding.DecodeString(jwk.K)
+			if err != nil || len(key) == 0 {
+				return nil, fmt.Errorf("%w: invalid symmetric key %q", ErrInvalidJWKS, jwk.KeyID)
+			}
+
+			keys[jwk.KeyID] = key
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+			if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+				return nil, fmt.Errorf("%w: invalid RSA key %q", ErrInvalidJWKS, jwk.KeyID)
+			}
+
+			keys[jwk.KeyID] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported key type %q", ErrInvalidJWKS, jwk.KeyType)
+		}
+	}
+
+	return &JWT{
+		keys: keys,
+	}, nil
+}
+
+func (authenticator *JWT) Authenticate(ctx context.Context, locator string, credentials string) (*Identity, error) {
+	var claims Claims
+
+	_, err := jwt.ParseWithClaims(credentials, &claims, authenticator.key,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	// The expiry is only validated when present, but the tokens should never be valid forever
+	if claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("%w: token has no expiry", ErrInvalidCredentials)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+
+	if claims.Locator != locator {
+		return nil, fmt.Errorf("%w: token was issued for another terminal", ErrInvalidCredentials)
+	}
+
+	scopes := strings.Fields(claims.Scope)
+
+	switch {
+	case slices.Contains(scopes, ScopeReadWrite):
+		return &Identity{Subject: claims.Subject}, nil
+	case slices.Contains(scopes, ScopeReadOnly):
+		return &Identity{Subject: claims.Subject, ReadOnly: true}, nil
+	default:
+		return nil, fmt.Errorf("%w: token grants neither %q nor %q scope", ErrInvalidCredentials,
+			ScopeReadWrite, ScopeReadOnly)
+	}
+}
+
+// key finds the key the token is signed with by its ID, which
+// can be omitted if the JWKS only contains a single key.
+func (authenticator *JWT) key(token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+
+	key, ok := authenticator.keys[keyID]
+	if !ok {
+		if keyID != "" || len(authenticator.keys) != 1 {
+			return nil, fmt.Errorf("unknown key ID %q", keyID)
+		}
+
+		for _, onlyKey := range authenticator.keys {
+			key = onlyKey
+		}
+	}
+
+	// Prevent the algorithm confusion, e.g. verifying HS256 using the RSA public key as the secret
+	switch key.(type) {
+	case []byte:
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("key %q can only be used with HS256", keyID)
+		}
+	case *rsa.PublicKey:
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("key %q can only be used with RS256", keyID)
+		}
+	}
+
+	return key, nil
+}
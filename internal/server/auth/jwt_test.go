@@ -0,0 +1,141 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"github.com/cirruslabs/terminal/internal/server/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+	"time"
+)
+
+const locator = "locator"
+
+var hmacKey = []byte("fixed HMAC key used in tests")
+
+func newAuthenticator(t *testing.T, rsaKey *rsa.PrivateKey) *auth.JWT {
+	jwks := fmt.Sprintf(`{"keys": [{"kty": "oct", "kid": "hmac", "k": %q},
+		{"kty": "RSA", "kid": "rsa", "n": %q, "e": %q}]}`,
+		base64.RawURLEncoding.EncodeToString(hmacKey),
+		base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()))
+
+	authenticator, err := auth.NewJWT([]byte(jwks))
+	require.NoError(t, err)
+
+	return authenticator
+}
+
+func validClaims() *auth.Claims {
+	return &auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "someone@example.com",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Locator: locator,
+		Scope:   auth.ScopeReadWrite,
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, keyID string, key interface{}, claims *auth.Claims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = keyID
+
+	signedToken, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signedToken
+}
+
+func TestJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	authenticator := newAuthenticator(t, rsaKey)
+
+	// Both algorithms are supported
+	for _, token := range []string{
+		sign(t, jwt.SigningMethodHS256, "hmac", hmacKey, validClaims()),
+		sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, validClaims()),
+	} {
+		identity, err := authenticator.Authenticate(context.Background(), locator, token)
+		require.NoError(t, err)
+		require.Equal(t, &auth.Identity{Subject: "someone@example.com"}, identity)
+	}
+
+	// Read-only scope
+	readOnlyClaims := validClaims()
+	readOnlyClaims.Scope = auth.ScopeReadOnly
+
+	identity, err := authenticator.Authenticate(context.Background(), locator,
+		sign(t, jwt.SigningMethodHS256, "hmac", hmacKey, readOnlyClaims))
+	require.NoError(t, err)
+	require.True(t, identity.ReadOnly)
+}
+
+func TestJWTIsRejected(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	authenticator := newAuthenticator(t, rsaKey)
+
+	withClaims := func(modify func(claims *auth.Claims)) *auth.Claims {
+		claims := validClaims()
+		modify(claims)
+
+		return claims
+	}
+
+	testCases := map[string]string{
+		"expired": sign(t, jwt.SigningMethodHS256, "hmac", hmacKey, withClaims(func(claims *auth.Claims) {
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		})),
+		"no expiry": sign(t, jwt.SigningMethodHS256, "hmac", hmacKey, withClaims(func(claims *auth.Claims) {
+			claims.ExpiresAt = nil
+		})),
+		"no subject": sign(t, jwt.SigningMethodHS256, "hmac", hmacKey, withClaims(func(claims *auth.Claims) {
+			claims.Subject = ""
+		})),
+		"another locator": sign(t, jwt.SigningMethodHS256, "hmac", hmacKey, withClaims(func(claims *auth.Claims) {
+			claims.Locator = "another locator"
+		})),
+		"no scope": sign(t, jwt.SigningMethodHS256, "hmac", hmacKey, withClaims(func(claims *auth.Claims) {
+			claims.Scope = ""
+		})),
+		"invalid signature": sign(t, jwt.SigningMethodRS256, "rsa", otherRSAKey, validClaims()),
+		"unknown key":       sign(t, jwt.SigningMethodHS256, "unknown", hmacKey, validClaims()),
+		"algorithm confusion": sign(t, jwt.SigningMethodHS256, "rsa",
+			[]byte(base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes())), validClaims()),
+		"unsupported algorithm": sign(t, jwt.SigningMethodHS512, "hmac", hmacKey, validClaims()),
+		"unsigned":              sign(t, jwt.SigningMethodNone, "hmac", jwt.UnsafeAllowNoneSignatureType, validClaims()),
+		"garbage":               "garbage",
+	}
+
+	for name, token := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := authenticator.Authenticate(context.Background(), locator, token)
+			require.ErrorIs(t, err, auth.ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestInvalidJWKS(t *testing.T) {
+	for _, jwks := range []string{
+		`garbage`,
+		`{"keys": []}`,
+		`{"keys": [{"kty": "EC", "kid": "ec"}]}`,
+		`{"keys": [{"kty": "oct", "kid": "hmac", "k": ""}]}`,
+		`{"keys": [{"kty": "oct", "kid": "hmac", "k": "a2V5"}, {"kty": "oct", "kid": "hmac", "k": "a2V5"}]}`,
+	} {
+		_, err := auth.NewJWT([]byte(jwks))
+		require.ErrorIs(t, err, auth.ErrInvalidJWKS, jwks)
+	}
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"time"
+)
+
+const (
+	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+
+	// How often to check whether the sessions have finished while draining
+	drainPollInterval = 250 * time.Millisecond
+
+	// How long to wait for the in-flight RPCs to finish before stopping the gRPC server
+	rpcsFinishTimeout = 5 * time.Second
+)
+
+// Drain puts the server into the drain mode: the readiness probe starts failing,
+// new hosts are refused and the connected ones are asked to re-connect (presumably
+// to another server), while the existing sessions keep running. Run returns once
+// the sessions have finished or the drain timeout has passed, whichever comes first.
+func (ts *TerminalServer) Drain() {
+	ts.drainOnce.Do(func() {
+		ts.logger.Info("draining the server", zap.Duration("drain-timeout", ts.drainTimeout))
+
+		close(ts.drainingChan)
+	})
+}
+
+// IsDraining returns true once Drain was called.
+func (ts *TerminalServer) IsDraining() bool {
+	select {
+	case <-ts.drainingChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitForSessions waits for the sessions on all terminals to finish, but no longer
+// than the drain timeout, or until the ctx is cancelled.
+func (ts *TerminalServer) waitForSessions(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, ts.drainTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		numSessions := ts.numSessions()
+		if numSessions == 0 {
+			ts.logger.Info("all sessions have finished")
+
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			ts.logger.Warn("timed out waiting for the sessions to finish", zap.Int("sessions", numSessions))
+
+			return
+		}
+	}
+}
+
+func (ts *TerminalServer) numSessions() int {
+	ts.terminalsLock.RLock()
+	defer ts.terminalsLock.RUnlock()
+
+	var result int
+
+	for _, terminal := range ts.terminals {
+		result += terminal.NumSessions()
+	}
+
+	return result
+}
+
+// trackRPC is a stream interceptor that tracks the in-flight RPCs
+// and refuses the new ones once the server is stopping.
+func (ts *TerminalServer) trackRPC(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ts.rpcsLock.Lock()
+	if ts.rpcsStopping {
+		ts.rpcsLock.Unlock()
+
+		return status.Errorf(codes.Unavailable, "terminal server is shutting down")
+	}
+	ts.rpcsWG.Add(1)
+	ts.rpcsLock.Unlock()
+
+	defer ts.rpcsWG.Done()
+
+	return handler(srv, stream)
+}
+
+// waitForRPCs refuses the new RPCs and gives the in-flight ones some time to finish.
+//
+// grpc.Server's GracefulStop() can't be used for this, because the gRPC server is
+// served through ServeHTTP(), whose transport doesn't support the graceful draining.
+func (ts *TerminalServer) waitForRPCs() {
+	ts.rpcsLock.Lock()
+	ts.rpcsStopping = true
+	ts.rpcsLock.Unlock()
+
+	rpcsDone := make(chan struct{})
+
+	go func() {
+		ts.rpcsWG.Wait()
+		close(rpcsDone)
+	}()
+
+	select {
+	case <-rpcsDone:
+	case <-time.After(rpcsFinishTimeout):
+		ts.logger.Warn("timed out waiting for the in-flight RPCs to finish")
+	}
+}
+
+// serveHealthz is a liveness probe, it succeeds as long as the server is running.
+func (ts *TerminalServer) serveHealthz(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, "ok")
+}
+
+// serveReadyz is a readiness probe, it fails once the server starts draining
+// to let the load balancer route the new hosts and guests to other servers.
+func (ts *TerminalServer) serveReadyz(w http.ResponseWriter, r *http.Request) {
+	if ts.IsDraining() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, "draining")
+
+		return
+	}
+
+	fmt.Fprint(w, "ok")
+}
@@ -3,17 +3,51 @@ package server_test
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/cenkalti/backoff/v4"
 	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/protocol"
 	"github.com/cirruslabs/terminal/internal/server"
+	"github.com/cirruslabs/terminal/internal/server/auth"
+	"github.com/cirruslabs/terminal/internal/server/registry"
+	"github.com/cirruslabs/terminal/internal/server/storage"
 	"github.com/cirruslabs/terminal/pkg/host"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/sftp"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestTerminalDimensionsCanBeChanged(t *testing.T) {
@@ -112,6 +146,14 @@ func TestTerminalDimensionsCanBeChanged(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// Server replies with a Hello message describing the session
+	helloFromServer, err := terminalChannel.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.NotNil(t, helloFromServer.GetHello())
+	require.False(t, helloFromServer.GetHello().ReadOnly)
+
 	// A couple of helper functions that will be re-used below
 	queryTerminalSize := func() {
 		if err := terminalChannel.Send(&api.GuestTerminalRequest{
@@ -178,3 +220,1735 @@ func TestTerminalDimensionsCanBeChanged(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestHostFailsOnPermanentErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	// Server refuses the Host's protocol version, which won't change by re-connecting
+	terminalServer, err := server.New(server.WithLogger(logger),
+		server.WithMinProtocolVersion(protocol.Version+1))
+	require.NoError(t, err)
+
+	go func() {
+		_ = terminalServer.Run(ctx)
+	}()
+
+	// Host uses the default reconnect policy, which retries forever
+	terminalHost, err := host.New(
+		host.WithLogger(logger),
+		host.WithServerAddress("http://"+terminalServer.Addresses()[0]),
+		host.WithTrustedSecret("fixed secret used in tests"),
+	)
+	require.NoError(t, err)
+
+	terminalHostErrChan := make(chan error, 1)
+	go func() {
+		terminalHostErrChan <- terminalHost.Run(ctx)
+	}()
+
+	select {
+	case err := <-terminalHostErrChan:
+		require.Equal(t, codes.FailedPrecondition, status.Code(err), err)
+	case <-time.After(30 * time.Second):
+		t.Fatal("terminal host should've failed instead of re-connecting")
+	}
+}
+
+func TestHostRetriesUntilServerIsAvailable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	// Reserve an address that nobody listens on yet
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverAddress := listener.Addr().String()
+	if err := listener.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run terminal host, it will fail to connect at first
+	locatorChan := make(chan string, 1)
+
+	terminalHost, err := host.New(
+		host.WithLogger(logger),
+		host.WithServerAddress("http://"+serverAddress),
+		host.WithTrustedSecret("fixed secret used in tests"),
+		host.WithLocatorCallback(func(locator string) error {
+			locatorChan <- locator
+			return nil
+		}),
+		host.WithReconnectBackOff(backoff.NewConstantBackOff(100*time.Millisecond)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminalHostErrChan := make(chan error)
+	go func() {
+		terminalHostErrChan <- terminalHost.Run(ctx)
+	}()
+
+	time.Sleep(time.Second)
+	require.True(t, terminalHost.LastConnection().IsZero(), "terminal host shouldn't be connected yet")
+
+	// Now bring the terminal server up
+	terminalServer, err := server.New(server.WithLogger(logger), server.WithAddresses([]string{serverAddress}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminalServerErrChan := make(chan error)
+	go func() {
+		terminalServerErrChan <- terminalServer.Run(ctx)
+	}()
+
+	select {
+	case <-locatorChan:
+	case err := <-terminalHostErrChan:
+		t.Fatal(err)
+	case <-time.After(30 * time.Second):
+		t.Fatal("terminal host failed to connect to the terminal server in time")
+	}
+
+	require.False(t, terminalHost.LastConnection().IsZero(), "terminal host should be connected")
+
+	cancel()
+
+	if err := <-terminalHostErrChan; err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+
+	if err := <-terminalServerErrChan; err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+}
+
+func TestSSHGateway(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	// Run terminal server with an SSH gateway enabled
+	terminalServer, err := server.New(server.WithLogger(logger), server.WithSSHAddress("127.0.0.1:0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminalServerErrChan := make(chan error)
+	go func() {
+		terminalServerErrChan <- terminalServer.Run(ctx)
+	}()
+
+	// Run terminal host
+	const secret = "fixed secret used in tests"
+
+	locatorChan := make(chan string, 1)
+	sftpRoot := t.TempDir()
+
+	terminalHost, err := host.New(
+		host.WithLogger(logger),
+		host.WithServerAddress("http://"+terminalServer.Addresses()[0]),
+		host.WithTrustedSecret(secret),
+		host.WithSFTP(true),
+		host.WithFileTransferRoots(sftpRoot),
+		host.WithLocatorCallback(func(locator string) error {
+			locatorChan <- locator
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminalHostErrChan := make(chan error)
+	go func() {
+		terminalHostErrChan <- terminalHost.Run(ctx)
+	}()
+
+	var locator string
+	select {
+	case locator = <-locatorChan:
+	case err := <-terminalHostErrChan:
+		t.Fatal(err)
+	}
+
+	// Invalid secret should be rejected
+	_, err = ssh.Dial("tcp", terminalServer.SSHAddress(), &ssh.ClientConfig{
+		User:            locator,
+		Auth:            []ssh.AuthMethod{ssh.Password("invalid secret")},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec // it's OK to not to verify host key in tests
+	})
+	require.Error(t, err)
+
+	// Emulate guest: connect using an SSH client, just like a regular user would do
+	sshClient, err := ssh.Dial("tcp", terminalServer.SSHAddress(), &ssh.ClientConfig{
+		User:            locator,
+		Auth:            []ssh.AuthMethod{ssh.Password(secret)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec // it's OK to not to verify host key in tests
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sshClient.Close()
+
+	sshSession, err := sshClient.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sshSession.Close()
+
+	stdin, err := sshSession.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := sshSession.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.NoError(t, sshSession.RequestPty("xterm", 45, 123, ssh.TerminalModes{}))
+	require.NoError(t, sshSession.Shell())
+
+	waitForCanary := func(canary string) {
+		buf := bytes.NewBuffer([]byte{})
+		chunk := make([]byte, 4096)
+
+		for !strings.Contains(buf.String(), canary) {
+			n, err := stdout.Read(chunk)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			buf.Write(chunk[:n])
+		}
+	}
+
+	_, err = fmt.Fprintln(stdin, "echo -e \"cols\\nlines\" | tput -S")
+	require.NoError(t, err)
+	waitForCanary("123\r\n45")
+
+	require.NoError(t, sshSession.WindowChange(22, 111))
+
+	_, err = fmt.Fprintln(stdin, "echo -e \"cols\\nlines\" | tput -S")
+	require.NoError(t, err)
+	waitForCanary("111\r\n22")
+
+	// SFTP subsystem should be served by the host
+	sftpClient, err := sftp.NewClient(sshClient)
+	require.NoError(t, err)
+	defer sftpClient.Close()
+
+	path := filepath.Join(sftpRoot, "sftp.txt")
+
+	file, err := sftpClient.Create(path)
+	require.NoError(t, err)
+	_, err = fmt.Fprint(file, "hello over SFTP")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	onDisk, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "hello over SFTP", string(onDisk))
+
+	require.NoError(t, sftpClient.Close())
+
+	cancel()
+
+	if err := <-terminalHostErrChan; err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+
+	if err := <-terminalServerErrChan; err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordingCanBeReplayed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	// Run terminal server with the session recording enabled
+	terminalServer, err := server.New(server.WithLogger(logger),
+		server.WithRecordingStorage(storage.NewLocal(t.TempDir()), false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminalServerErrChan := make(chan error)
+	go func() {
+		terminalServerErrChan <- terminalServer.Run(ctx)
+	}()
+
+	// Run terminal host
+	const secret = "fixed secret used in tests"
+
+	locatorChan := make(chan string, 1)
+
+	terminalHost, err := host.New(
+		host.WithLogger(logger),
+		host.WithServerAddress("http://"+terminalServer.Addresses()[0]),
+		host.WithTrustedSecret(secret),
+		host.WithLocatorCallback(func(locator string) error {
+			locatorChan <- locator
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminalHostErrChan := make(chan error)
+	go func() {
+		terminalHostErrChan <- terminalHost.Run(ctx)
+	}()
+
+	var locator string
+	select {
+	case locator = <-locatorChan:
+	case err := <-terminalHostErrChan:
+		t.Fatal(err)
+	}
+
+	// Emulate guest: run a session till the shell exits
+	clientConn, err := grpc.Dial(terminalServer.Addresses()[0], grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientConn.Close()
+
+	guestService := api.NewGuestServiceClient(clientConn)
+
+	terminalChannel, err := guestService.TerminalChannel(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.NoError(t, terminalChannel.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Hello_{
+			Hello: &api.GuestTerminalRequest_Hello{
+				Locator: locator,
+				Secret:  secret,
+				RequestedDimensions: &api.TerminalDimensions{
+					WidthColumns: 123,
+					HeightRows:   45,
+				},
+			},
+		},
+	}))
+
+	helloFromServer, err := terminalChannel.Recv()
+	require.NoError(t, err)
+	recordingID := helloFromServer.GetHello().GetRecordingId()
+	require.NotEmpty(t, recordingID)
+
+	require.NoError(t, terminalChannel.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Input{
+			Input: &api.Data{
+				Data: []byte("echo replayed-$((6*7)); exit\n"),
+			},
+		},
+	}))
+
+	for {
+		responseFromServer, err := terminalChannel.Recv()
+		require.NoError(t, err)
+
+		if responseFromServer.GetTermination() != nil {
+			break
+		}
+	}
+
+	replay := func(secret string) ([]*api.ReplayResponse, error) {
+		replayStream, err := guestService.Replay(ctx, &api.ReplayRequest{
+			Locator:     locator,
+			Secret:      secret,
+			RecordingId: recordingID,
+			Speed:       100,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var result []*api.ReplayResponse
+
+		for {
+			response, err := replayStream.Recv()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return result, nil
+				}
+
+				return nil, err
+			}
+
+			result = append(result, response)
+		}
+	}
+
+	// The recording becomes available shortly after the session ends
+	var responses []*api.ReplayResponse
+
+	require.Eventually(t, func() bool {
+		responses, err = replay(secret)
+
+		return status.Code(err) != codes.NotFound
+	}, 5*time.Second, 100*time.Millisecond)
+	require.NoError(t, err)
+
+	require.Equal(t, &api.TerminalDimensions{WidthColumns: 123, HeightRows: 45},
+		responses[0].GetChangeDimensions())
+
+	var output []byte
+
+	for _, response := range responses {
+		output = append(output, response.GetOutput().GetData()...)
+	}
+
+	require.Contains(t, string(output), "replayed-42")
+
+	// Recording is not available without a valid secret
+	_, err = replay("invalid secret")
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	cancel()
+
+	if err := <-terminalHostErrChan; err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+
+	if err := <-terminalServerErrChan; err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+}
+
+func TestPreviewProxy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	// Run a web server on the Host's side, which also supports upgrading to an echo protocol
+	backendListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	backendServer := &http.Server{
+		ReadHeaderTimeout: 5 * time.Second,
+		Handler: http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.Header.Get("Upgrade") != "echo" {
+				_, hasCookie := request.Header["Cookie"]
+
+				fmt.Fprintf(writer, "path=%s query=%s host=%s prefix=%s cookie=%t", request.URL.Path,
+					request.URL.RawQuery, request.Host, request.Header.Get("X-Forwarded-Prefix"), hasCookie)
+
+				return
+			}
+
+			conn, bufrw, err := writer.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			_, _ = fmt.Fprint(bufrw, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+			_ = bufrw.Flush()
+
+			_, _ = io.Copy(conn, bufrw)
+		}),
+	}
+	go func() {
+		_ = backendServer.Serve(backendListener)
+	}()
+	defer backendServer.Close()
+
+	backendPort := backendListener.Addr().(*net.TCPAddr).Port
+
+	// Run terminal server with the preview proxy enabled
+	terminalServer, err := server.New(server.WithLogger(logger), server.WithPreview(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminalServerErrChan := make(chan error)
+	go func() {
+		terminalServerErrChan <- terminalServer.Run(ctx)
+	}()
+
+	// Run terminal host
+	const secret = "fixed secret used in tests"
+
+	locatorChan := make(chan string, 1)
+
+	terminalHost, err := host.New(
+		host.WithLogger(logger),
+		host.WithServerAddress("http://"+terminalServer.Addresses()[0]),
+		host.WithTrustedSecret(secret),
+		host.WithAllowedTunnelPorts(uint16(backendPort)), //nolint:gosec // TCP ports always fit
+		host.WithLocatorCallback(func(locator string) error {
+			locatorChan <- locator
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminalHostErrChan := make(chan error)
+	go func() {
+		terminalHostErrChan <- terminalHost.Run(ctx)
+	}()
+
+	var locator string
+	select {
+	case locator = <-locatorChan:
+	case err := <-terminalHostErrChan:
+		t.Fatal(err)
+	}
+
+	previewURL := fmt.Sprintf("http://%s/preview/%s/%d", terminalServer.Addresses()[0], locator, backendPort)
+
+	get := func(client *http.Client, url string) (int, string) {
+		response, err := client.Get(url)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+
+		return response.StatusCode, string(body)
+	}
+
+	// Unauthenticated requests are rejected
+	statusCode, _ := get(http.DefaultClient, previewURL+"/hello")
+	require.Equal(t, http.StatusUnauthorized, statusCode)
+
+	statusCode, _ = get(http.DefaultClient, previewURL+"/hello?secret=invalid")
+	require.Equal(t, http.StatusForbidden, statusCode)
+
+	// The secret is exchanged for a cookie, which is not passed to the Host
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	client := &http.Client{Jar: jar}
+
+	statusCode, body := get(client, previewURL+"/hello?secret="+url.QueryEscape(secret)+"&x=1")
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, fmt.Sprintf("path=/hello query=x=1 host=localhost:%d prefix=/preview/%s/%d cookie=false",
+		backendPort, locator, backendPort), body)
+
+	statusCode, body = get(client, previewURL+"/")
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Contains(t, body, "path=/ ")
+
+	// The previews sharing the server's origin are sandboxed
+	response, err := client.Get(previewURL + "/")
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Contains(t, response.Header.Get("Content-Security-Policy"), "sandbox")
+	require.NotContains(t, response.Header.Get("Content-Security-Policy"), "allow-same-origin")
+
+	// Upgraded connections (e.g. WebSockets) are proxied too
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, previewURL+"/echo", nil)
+	require.NoError(t, err)
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Upgrade", "echo")
+
+	response, err = client.Do(request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, response.StatusCode)
+
+	upgradedConn := response.Body.(io.ReadWriteCloser)
+
+	_, err = fmt.Fprint(upgradedConn, "ping")
+	require.NoError(t, err)
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(upgradedConn, buf)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(buf))
+	require.NoError(t, upgradedConn.Close())
+
+	cancel()
+
+	if err := <-terminalHostErrChan; err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+
+	if err := <-terminalServerErrChan; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPreviewDomain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	backendListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	backendServer := &http.Server{
+		ReadHeaderTimeout: 5 * time.Second,
+		Handler: http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			fmt.Fprintf(writer, "path=%s prefix=%s", request.URL.Path, request.Header.Get("X-Forwarded-Prefix"))
+		}),
+	}
+	go func() {
+		_ = backendServer.Serve(backendListener)
+	}()
+	defer backendServer.Close()
+
+	backendPort := backendListener.Addr().(*net.TCPAddr).Port
+
+	terminalServer, err := server.New(server.WithLogger(logger), server.WithPreview(true),
+		server.WithPreviewDomain("preview.test"))
+	require.NoError(t, err)
+
+	go func() {
+		_ = terminalServer.Run(ctx)
+	}()
+
+	serverAddress := terminalServer.Addresses()[0]
+
+	const secret = "fixed secret used in tests"
+
+	locatorChan := make(chan string, 1)
+
+	terminalHost, err := host.New(
+		host.WithLogger(logger),
+		host.WithServerAddress("http://"+serverAddress),
+		host.WithTrustedSecret(secret),
+		host.WithAllowedTunnelPorts(uint16(backendPort)), //nolint:gosec // TCP ports always fit
+		host.WithLocatorCallback(func(locator string) error {
+			locatorChan <- locator
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	go func() {
+		_ = terminalHost.Run(ctx)
+	}()
+
+	locator := <-locatorChan
+
+	// Resolve all the preview hosts to the server
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	client := &http.Client{
+		Jar: jar,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network string, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, serverAddress)
+			},
+		},
+	}
+
+	get := func(url string) (*http.Response, string) {
+		response, err := client.Get(url)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+
+		return response, string(body)
+	}
+
+	previewURL := fmt.Sprintf("http://%s.preview.test/%d", locator, backendPort)
+
+	response, body := get(previewURL + "/hello?secret=" + url.QueryEscape(secret))
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, fmt.Sprintf("path=/hello prefix=/%d", backendPort), body)
+
+	// The previews have their own origin, so they don't need to be sandboxed
+	require.Empty(t, response.Header.Get("Content-Security-Policy"))
+
+	// Nothing but the previews is served on the preview hosts
+	response, _ = get(fmt.Sprintf("http://%s.preview.test/healthz", locator))
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// The previews are not served under the server's origin
+	response, body = get(fmt.Sprintf("http://%s/preview/%s/%d/hello", serverAddress, locator, backendPort))
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.NotContains(t, body, "path=")
+}
+
+func TestMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	terminalServer, err := server.New(server.WithLogger(logger), server.WithMetricsAddress("127.0.0.1:0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		_ = terminalServer.Run(ctx)
+	}()
+
+	serverAddress := terminalServer.Addresses()[0]
+
+	const secret = "fixed secret used in tests"
+
+	locatorChan := make(chan string, 1)
+
+	terminalHost, err := host.New(
+		host.WithLogger(logger),
+		host.WithServerAddress("http://"+serverAddress),
+		host.WithTrustedSecret(secret),
+		host.WithLocatorCallback(func(locator string) error {
+			locatorChan <- locator
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		_ = terminalHost.Run(ctx)
+	}()
+
+	locator := <-locatorChan
+
+	clientConn, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientConn.Close()
+
+	guestService := api.NewGuestServiceClient(clientConn)
+
+	openTerminalChannel := func(secret string) (api.GuestService_TerminalChannelClient, error) {
+		terminalChannel, err := guestService.TerminalChannel(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := terminalChannel.Send(&api.GuestTerminalRequest{
+			Operation: &api.GuestTerminalRequest_Hello_{
+				Hello: &api.GuestTerminalRequest_Hello{
+					Locator: locator,
+					Secret:  secret,
+				},
+			},
+		}); err != nil {
+			return nil, err
+		}
+
+		if _, err := terminalChannel.Recv(); err != nil {
+			return nil, err
+		}
+
+		return terminalChannel, nil
+	}
+
+	// Guest with an invalid secret is refused
+	_, err = openTerminalChannel("invalid secret")
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Guest with a valid secret starts a session and types something
+	terminalChannel, err := openTerminalChannel(secret)
+	require.NoError(t, err)
+
+	require.NoError(t, terminalChannel.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Input{
+			Input: &api.Data{Data: []byte("echo hello\n")},
+		},
+	}))
+
+	expectedMetrics := []string{
+		"terminal_registered_terminals 1",
+		fmt.Sprintf("terminal_active_sessions{locator=%q} 1", locator),
+		`terminal_hello_failures_total{channel="terminal",code="PermissionDenied"} 1`,
+		`terminal_relayed_bytes_total{direction="guest_to_host"} 11`,
+	}
+
+	require.Eventually(t, func() bool {
+		response, err := http.Get("http://" + terminalServer.MetricsAddress() + "/metrics")
+		if err != nil {
+			return false
+		}
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			return false
+		}
+
+		for _, expectedMetric := range expectedMetrics {
+			if !strings.Contains(string(body), expectedMetric) {
+				return false
+			}
+		}
+
+		return true
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	terminalServer, err := server.New(server.WithLogger(logger), server.WithDrainTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminalServerErrChan := make(chan error, 1)
+	go func() {
+		terminalServerErrChan <- terminalServer.Run(ctx)
+	}()
+
+	serverAddress := terminalServer.Addresses()[0]
+
+	// Run terminal host
+	const secret = "fixed secret used in tests"
+
+	locatorChan := make(chan string, 1)
+
+	terminalHost, err := host.New(
+		host.WithLogger(logger),
+		host.WithServerAddress("http://"+serverAddress),
+		host.WithTrustedSecret(secret),
+		host.WithLocatorCallback(func(locator string) error {
+			locatorChan <- locator
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		_ = terminalHost.Run(ctx)
+	}()
+
+	locator := <-locatorChan
+
+	probe := func(path string) int {
+		response, err := http.Get("http://" + serverAddress + path)
+		require.NoError(t, err)
+		_ = response.Body.Close()
+
+		return response.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, probe("/healthz"))
+	require.Equal(t, http.StatusOK, probe("/readyz"))
+
+	// Emulate guest: start a session
+	clientConn, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientConn.Close()
+
+	terminalChannel, err := api.NewGuestServiceClient(clientConn).TerminalChannel(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, terminalChannel.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Hello_{
+			Hello: &api.GuestTerminalRequest_Hello{
+				Locator: locator,
+				Secret:  secret,
+			},
+		},
+	}))
+
+	_, err = terminalChannel.Recv()
+	require.NoError(t, err)
+
+	terminalServer.Drain()
+
+	// The server is no longer ready, but still alive
+	require.Equal(t, http.StatusServiceUnavailable, probe("/readyz"))
+	require.Equal(t, http.StatusOK, probe("/healthz"))
+
+	// New hosts are refused
+	controlChannel, err := api.NewHostServiceClient(clientConn).ControlChannel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, controlChannel.Send(&api.HostControlRequest{
+		Operation: &api.HostControlRequest_Hello_{
+			Hello: &api.HostControlRequest_Hello{
+				TrustedSecret: secret,
+			},
+		},
+	}))
+	_, err = controlChannel.Recv()
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	// The existing session keeps running until it finishes on its own
+	require.NoError(t, terminalChannel.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Input{
+			Input: &api.Data{
+				Data: []byte("exit\n"),
+			},
+		},
+	}))
+
+	for {
+		responseFromServer, err := terminalChannel.Recv()
+		require.NoError(t, err)
+
+		if termination := responseFromServer.GetTermination(); termination != nil {
+			require.Equal(t, api.Termination_SHELL_EXITED, termination.Reason)
+
+			break
+		}
+	}
+
+	// The server stops once the last session has finished
+	select {
+	case err := <-terminalServerErrChan:
+		require.NoError(t, err)
+	case <-time.After(30 * time.Second):
+		t.Fatal("server didn't stop after the last session has finished")
+	}
+}
+
+func TestClusterProxiesChannelsToTheOwner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	// Run two replicas of the terminal server sharing the same registry
+	locatorRegistry := registry.NewRedis(redis.NewClient(&redis.Options{
+		Addr: miniredis.RunT(t).Addr(),
+	}))
+
+	runReplica := func() string {
+		// Find out the address in advance to be able to advertise it to the other replica
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().String()
+		require.NoError(t, listener.Close())
+
+		terminalServer, err := server.New(server.WithLogger(logger), server.WithAddresses([]string{address}),
+			server.WithRegistry(locatorRegistry, "http://"+address), server.WithPeerSecret("fixed peer secret"))
+		require.NoError(t, err)
+
+		go func() {
+			_ = terminalServer.Run(ctx)
+		}()
+
+		return address
+	}
+
+	ownerAddress := runReplica()
+	proxyAddress := runReplica()
+
+	ownerConn, err := grpc.Dial(ownerAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer ownerConn.Close()
+
+	proxyConn, err := grpc.Dial(proxyAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer proxyConn.Close()
+
+	// Emulate host: register on the owner replica
+	const secret = "fixed secret used in tests"
+
+	controlChannel, err := api.NewHostServiceClient(ownerConn).ControlChannel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, controlChannel.Send(&api.HostControlRequest{
+		Operation: &api.HostControlRequest_Hello_{
+			Hello: &api.HostControlRequest_Hello{
+				TrustedSecret:   secret,
+				ProtocolVersion: protocol.Version,
+				Capabilities:    protocol.HostCapabilities,
+			},
+		},
+	}))
+
+	controlFromServer, err := controlChannel.Recv()
+	require.NoError(t, err)
+	locator := controlFromServer.GetHello().GetLocator()
+	require.NotEmpty(t, locator)
+
+	// Emulate guest: start a session through the other replica
+	terminalChannel, err := api.NewGuestServiceClient(proxyConn).TerminalChannel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, terminalChannel.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Hello_{
+			Hello: &api.GuestTerminalRequest_Hello{
+				Locator: locator,
+				Secret:  secret,
+			},
+		},
+	}))
+
+	// Emulate host: open the requested data channel through the other replica too
+	controlFromServer, err = controlChannel.Recv()
+	require.NoError(t, err)
+	dataChannelRequest := controlFromServer.GetDataChannelRequest()
+	require.NotNil(t, dataChannelRequest)
+
+	dataChannel, err := api.NewHostServiceClient(proxyConn).DataChannel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, dataChannel.Send(&api.HostDataRequest{
+		Operation: &api.HostDataRequest_Hello_{
+			Hello: &api.HostDataRequest_Hello{
+				Locator: locator,
+				Token:   dataChannelRequest.Token,
+			},
+		},
+	}))
+	require.NoError(t, dataChannel.Send(&api.HostDataRequest{
+		Operation: &api.HostDataRequest_Output{
+			Output: &api.Data{Data: []byte("output from the host")},
+		},
+	}))
+
+	for {
+		responseFromServer, err := terminalChannel.Recv()
+		require.NoError(t, err)
+
+		if output := responseFromServer.GetOutput(); output != nil {
+			require.Equal(t, "output from the host", string(output.Data))
+
+			break
+		}
+	}
+
+	require.NoError(t, terminalChannel.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Input{
+			Input: &api.Data{Data: []byte("input from the guest")},
+		},
+	}))
+
+	for {
+		responseFromServer, err := dataChannel.Recv()
+		require.NoError(t, err)
+
+		if input := responseFromServer.GetInput(); input != nil {
+			require.Equal(t, "input from the guest", string(input.Data))
+
+			break
+		}
+	}
+
+	// Unknown locators are still refused
+	unknownTerminalChannel, err := api.NewGuestServiceClient(proxyConn).TerminalChannel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, unknownTerminalChannel.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Hello_{
+			Hello: &api.GuestTerminalRequest_Hello{
+				Locator: "unknown",
+				Secret:  secret,
+			},
+		},
+	}))
+	_, err = unknownTerminalChannel.Recv()
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// runServerWithHost runs the terminal server with the specified options and a terminal host
+// registered on it, returning the server's address and the locator. The host additionally
+// accepts the "read-only " + secret as a read-only secret.
+func runServerWithHost(
+	ctx context.Context,
+	t *testing.T,
+	logger *zap.Logger,
+	secret string,
+	opts ...server.Option,
+) (string, string) {
+	terminalServer, err := server.New(append([]server.Option{server.WithLogger(logger)}, opts...)...)
+	require.NoError(t, err)
+
+	go func() {
+		_ = terminalServer.Run(ctx)
+	}()
+
+	serverAddress := terminalServer.Addresses()[0]
+
+	locatorChan := make(chan string, 1)
+
+	terminalHost, err := host.New(
+		host.WithLogger(logger),
+		host.WithServerAddress("http://"+serverAddress),
+		host.WithTrustedSecret(secret),
+		host.WithReadOnlySecret("read-only "+secret),
+		host.WithLocatorCallback(func(locator string) error {
+			locatorChan <- locator
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	go func() {
+		_ = terminalHost.Run(ctx)
+	}()
+
+	return serverAddress, <-locatorChan
+}
+
+// openTerminalChannel starts a new session and returns the error from the server, if any.
+func openTerminalChannel(
+	ctx context.Context,
+	guestService api.GuestServiceClient,
+	locator string,
+	secret string,
+) (api.GuestService_TerminalChannelClient, error) {
+	return openTerminalChannelWithHello(ctx, guestService, &api.GuestTerminalRequest_Hello{
+		Locator: locator,
+		Secret:  secret,
+	})
+}
+
+func openTerminalChannelWithHello(
+	ctx context.Context,
+	guestService api.GuestServiceClient,
+	hello *api.GuestTerminalRequest_Hello,
+) (api.GuestService_TerminalChannelClient, error) {
+	terminalChannel, err := guestService.TerminalChannel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := terminalChannel.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Hello_{
+			Hello: hello,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	if _, err := terminalChannel.Recv(); err != nil {
+		return nil, err
+	}
+
+	return terminalChannel, nil
+}
+
+func requireResourceExhausted(t *testing.T, err error) {
+	require.Equal(t, codes.ResourceExhausted, status.Code(err), err)
+
+	for _, detail := range status.Convert(err).Details() {
+		if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+			require.Positive(t, retryInfo.RetryDelay.AsDuration())
+
+			return
+		}
+	}
+
+	t.Fatal("expected the status to include the retry info")
+}
+
+func TestGuestIsLockedOutAfterFailedAttempts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	const secret = "fixed secret used in tests"
+
+	serverAddress, locator := runServerWithHost(ctx, t, logger, secret, server.WithLockoutThreshold(2))
+
+	clientConn, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	guestService := api.NewGuestServiceClient(clientConn)
+
+	for range 2 {
+		_, err = openTerminalChannel(ctx, guestService, locator, "invalid secret")
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	}
+
+	// Even the valid secret is refused during the lockout
+	_, err = openTerminalChannel(ctx, guestService, locator, secret)
+	requireResourceExhausted(t, err)
+
+	// Other locators are still locked out for this client IP
+	_, err = openTerminalChannel(ctx, guestService, "unknown", secret)
+	requireResourceExhausted(t, err)
+}
+
+func TestSpoofedForwardedAddressDoesNotEscapeLockout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	const secret = "fixed secret used in tests"
+
+	serverAddress, _ := runServerWithHost(ctx, t, logger, secret, server.WithLockoutThreshold(2),
+		server.WithClientIPHeader("x-forwarded-for", 1))
+
+	clientConn, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	guestService := api.NewGuestServiceClient(clientConn)
+
+	// The client controls everything to the left of the address appended by the load balancer
+	forwardedFor := func(spoofed string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "x-forwarded-for", spoofed+", 10.0.0.1")
+	}
+
+	for i := range 2 {
+		_, err = openTerminalChannel(forwardedFor(fmt.Sprintf("192.0.2.%d", i)), guestService, "unknown", secret)
+		require.Equal(t, codes.NotFound, status.Code(err))
+	}
+
+	_, err = openTerminalChannel(forwardedFor("192.0.2.100"), guestService, "unknown", secret)
+	requireResourceExhausted(t, err)
+
+	// Other clients behind the same load balancer are not affected
+	_, err = openTerminalChannel(metadata.AppendToOutgoingContext(ctx, "x-forwarded-for", "10.0.0.2"),
+		guestService, "unknown", secret)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGuestHelloIsRateLimited(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	const secret = "fixed secret used in tests"
+
+	serverAddress, locator := runServerWithHost(ctx, t, logger, secret,
+		server.WithGuestHelloRateLimit(0.001, 1))
+
+	clientConn, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	guestService := api.NewGuestServiceClient(clientConn)
+
+	_, err = openTerminalChannel(ctx, guestService, locator, secret)
+	require.NoError(t, err)
+
+	_, err = openTerminalChannel(ctx, guestService, locator, secret)
+	requireResourceExhausted(t, err)
+}
+
+func TestMaxSessionsPerTerminal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	const secret = "fixed secret used in tests"
+
+	serverAddress, locator := runServerWithHost(ctx, t, logger, secret, server.WithMaxSessionsPerTerminal(1))
+
+	clientConn, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	guestService := api.NewGuestServiceClient(clientConn)
+
+	_, err = openTerminalChannel(ctx, guestService, locator, secret)
+	require.NoError(t, err)
+
+	_, err = openTerminalChannel(ctx, guestService, locator, secret)
+	requireResourceExhausted(t, err)
+}
+
+func TestGuestCanAuthenticateWithJWT(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	const secret = "fixed secret used in tests"
+
+	hmacKey := []byte("fixed HMAC key used in tests")
+
+	authenticator, err := auth.NewJWT([]byte(fmt.Sprintf(`{"keys": [{"kty": "oct", "kid": "hmac", "k": %q}]}`,
+		base64.RawURLEncoding.EncodeToString(hmacKey))))
+	require.NoError(t, err)
+
+	serverAddress, locator := runServerWithHost(ctx, t, logger, secret, server.WithAuthenticator(authenticator),
+		server.WithLockoutThreshold(0))
+
+	clientConn, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	guestService := api.NewGuestServiceClient(clientConn)
+
+	signToken := func(locator string, key []byte) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, &auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "someone@example.com",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			Locator: locator,
+			Scope:   auth.ScopeReadWrite,
+		})
+		token.Header["kid"] = "hmac"
+
+		signedToken, err := token.SignedString(key)
+		require.NoError(t, err)
+
+		return signedToken
+	}
+
+	// Valid token is accepted instead of the secret
+	_, err = openTerminalChannelWithHello(ctx, guestService, &api.GuestTerminalRequest_Hello{
+		Locator: locator,
+		Token:   signToken(locator, hmacKey),
+	})
+	require.NoError(t, err)
+
+	// Token issued for another terminal is refused
+	_, err = openTerminalChannelWithHello(ctx, guestService, &api.GuestTerminalRequest_Hello{
+		Locator: locator,
+		Token:   signToken("another locator", hmacKey),
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err), err)
+
+	// Token signed with an unknown key is refused
+	_, err = openTerminalChannelWithHello(ctx, guestService, &api.GuestTerminalRequest_Hello{
+		Locator: locator,
+		Token:   signToken(locator, []byte("unknown HMAC key")),
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err), err)
+}
+
+func TestGuestTokenIsRefusedWithoutAuthenticator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	const secret = "fixed secret used in tests"
+
+	serverAddress, locator := runServerWithHost(ctx, t, logger, secret)
+
+	clientConn, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	_, err = openTerminalChannelWithHello(ctx, api.NewGuestServiceClient(clientConn), &api.GuestTerminalRequest_Hello{
+		Locator: locator,
+		Token:   "token",
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err), err)
+}
+
+func TestHostClientCertificateIsRequired(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	const secret = "fixed secret used in tests"
+
+	caCertificate := issueCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	serverCertificate := issueCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "server"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &caCertificate)
+	hostCertificate := issueCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "host"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &caCertificate)
+	untrustedHostCertificate := issueCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "untrusted host"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, nil)
+
+	caPool := x509.NewCertPool()
+	caPool.AddCert(caCertificate.Leaf)
+
+	serverTLSConfig := &tls.Config{
+		Certificates: []tls.Certificate{serverCertificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	// The Hosts need to connect to the separate host addresses
+	_, err = server.New(
+		server.WithAddresses([]string{"127.0.0.1:0"}),
+		server.WithTLSConfig(serverTLSConfig),
+		server.WithHostClientCAs(caPool),
+	)
+	require.ErrorIs(t, err, server.ErrHostClientCAsRequireHostAddresses)
+
+	terminalServer, err := server.New(
+		server.WithLogger(logger),
+		server.WithAddresses([]string{"127.0.0.1:0"}),
+		server.WithHostAddresses([]string{"127.0.0.1:0"}),
+		server.WithTLSConfig(serverTLSConfig),
+		server.WithHostClientCAs(caPool),
+	)
+	require.NoError(t, err)
+
+	go func() {
+		_ = terminalServer.Run(ctx)
+	}()
+
+	serverAddress := terminalServer.Addresses()[0]
+	hostAddress := terminalServer.HostAddresses()[0]
+
+	runHost := func(address string, opts ...host.Option) (string, error) {
+		locatorChan := make(chan string, 1)
+
+		terminalHost, err := host.New(append([]host.Option{
+			host.WithLogger(logger),
+			host.WithServerAddress("https://" + address),
+			host.WithTrustedSecret(secret),
+			host.WithRootCAs(caPool),
+			host.WithLocatorCallback(func(locator string) error {
+				locatorChan <- locator
+				return nil
+			}),
+			host.WithReconnectBackOff(&backoff.StopBackOff{}),
+		}, opts...)...)
+		require.NoError(t, err)
+
+		terminalHostErrChan := make(chan error, 1)
+		go func() {
+			terminalHostErrChan <- terminalHost.Run(ctx)
+		}()
+
+		select {
+		case locator := <-locatorChan:
+			return locator, nil
+		case err := <-terminalHostErrChan:
+			return "", err
+		}
+	}
+
+	// Hosts without a client certificate are refused
+	_, err = runHost(hostAddress)
+	require.Equal(t, codes.Unauthenticated, status.Code(err), err)
+
+	// Hosts with a client certificate issued by an untrusted CA are refused too
+	_, err = runHost(hostAddress, host.WithClientCertificate(untrustedHostCertificate))
+	require.Equal(t, codes.Unauthenticated, status.Code(err), err)
+
+	// Hosts with a trusted client certificate are refused on the Guests' addresses,
+	// where the client certificates are never asked for
+	_, err = runHost(serverAddress, host.WithClientCertificate(hostCertificate))
+	require.Equal(t, codes.Unauthenticated, status.Code(err), err)
+
+	// Hosts with a trusted client certificate are accepted
+	locator, err := runHost(hostAddress, host.WithClientCertificate(hostCertificate))
+	require.NoError(t, err)
+
+	// Guests (e.g. the browsers) are not asked for a client certificate, which would prompt the users to pick one
+	tlsConn, err := tls.Dial("tcp", serverAddress, &tls.Config{
+		RootCAs:    caPool,
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			t.Error("guest was asked for a client certificate")
+
+			return &tls.Certificate{}, nil
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, tlsConn.Close())
+
+	// Guests are served without a client certificate
+	clientConn, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		RootCAs:    caPool,
+		MinVersion: tls.VersionTLS12,
+	})))
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	_, err = openTerminalChannel(ctx, api.NewGuestServiceClient(clientConn), locator, secret)
+	require.NoError(t, err)
+}
+
+// issueCertificate issues a certificate using the template, which
+// is self-signed when the issuer's certificate is not specified.
+func issueCertificate(t *testing.T, template *x509.Certificate, issuer *tls.Certificate) tls.Certificate {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parent, parentPrivateKey := template, crypto.Signer(privateKey)
+	if issuer != nil {
+		parent, parentPrivateKey = issuer.Leaf, issuer.PrivateKey.(crypto.Signer)
+	}
+
+	certificateBytes, err := x509.CreateCertificate(rand.Reader, template, parent, &privateKey.PublicKey,
+		parentPrivateKey)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(certificateBytes)
+	require.NoError(t, err)
+
+	return tls.Certificate{
+		Certificate: [][]byte{certificateBytes},
+		PrivateKey:  privateKey,
+		Leaf:        leaf,
+	}
+}
+
+func TestReadOnlyGuestIsToldInputIsIgnored(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	const secret = "fixed secret used in tests"
+
+	serverAddress, locator := runServerWithHost(ctx, t, logger, secret)
+
+	clientConn, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	guestService := api.NewGuestServiceClient(clientConn)
+
+	// Start a new session
+	driver, err := guestService.TerminalChannel(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, driver.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Hello_{
+			Hello: &api.GuestTerminalRequest_Hello{
+				Locator: locator,
+				Secret:  secret,
+			},
+		},
+	}))
+
+	helloToDriver, err := driver.Recv()
+	require.NoError(t, err)
+
+	// Join the session as an observer
+	observer, err := openTerminalChannelWithHello(ctx, guestService, &api.GuestTerminalRequest_Hello{
+		Locator:   locator,
+		Secret:    "read-only " + secret,
+		SessionId: helloToDriver.GetHello().SessionId,
+	})
+	require.NoError(t, err)
+
+	// Observer's input is ignored, which the server tells it about
+	for range 2 {
+		require.NoError(t, observer.Send(&api.GuestTerminalRequest{
+			Operation: &api.GuestTerminalRequest_Input{
+				Input: &api.Data{Data: []byte("echo ignored\n")},
+			},
+		}))
+	}
+
+	for {
+		responseFromServer, err := observer.Recv()
+		require.NoError(t, err)
+
+		if inputIgnored := responseFromServer.GetInputIgnored(); inputIgnored != nil {
+			require.NotEmpty(t, inputIgnored.Reason)
+
+			break
+		}
+	}
+}
+
+func TestSSHConnectionsDoNotLeakGoroutines(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	const secret = "fixed secret used in tests"
+
+	// Reserve an address for the SSH gateway
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	sshAddress := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	_, locator := runServerWithHost(ctx, t, logger, secret, server.WithSSHAddress(sshAddress))
+
+	connectAndDisconnect := func() {
+		sshClient, err := ssh.Dial("tcp", sshAddress, &ssh.ClientConfig{
+			User:            locator,
+			Auth:            []ssh.AuthMethod{ssh.Password(secret)},
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec // it's OK to not to verify host key in tests
+		})
+		require.NoError(t, err)
+		require.NoError(t, sshClient.Close())
+	}
+
+	// Let the lazily started Goroutines (if any) start before taking the baseline
+	connectAndDisconnect()
+
+	numGoroutinesBefore := runtime.NumGoroutine()
+
+	const numConnections = 20
+
+	for range numConnections {
+		connectAndDisconnect()
+	}
+
+	// Each leaked connection would've left at least one Goroutine behind
+	require.Eventually(t, func() bool {
+		return runtime.NumGoroutine() < numGoroutinesBefore+numConnections/2
+	}, 10*time.Second, 50*time.Millisecond, "goroutines have leaked")
+}
+
+func TestRecordedInputIsOnlyReplayedWithTrustedSecret(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	const secret = "fixed secret used in tests"
+
+	serverAddress, locator := runServerWithHost(ctx, t, logger, secret,
+		server.WithRecordingStorage(storage.NewLocal(t.TempDir()), true))
+
+	clientConn, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	guestService := api.NewGuestServiceClient(clientConn)
+
+	// Run a session till the shell exits
+	terminalChannel, err := guestService.TerminalChannel(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, terminalChannel.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Hello_{
+			Hello: &api.GuestTerminalRequest_Hello{
+				Locator: locator,
+				Secret:  secret,
+			},
+		},
+	}))
+
+	helloFromServer, err := terminalChannel.Recv()
+	require.NoError(t, err)
+	recordingID := helloFromServer.GetHello().GetRecordingId()
+
+	require.NoError(t, terminalChannel.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Input{
+			Input: &api.Data{
+				Data: []byte("exit\n"),
+			},
+		},
+	}))
+
+	for {
+		responseFromServer, err := terminalChannel.Recv()
+		require.NoError(t, err)
+
+		if responseFromServer.GetTermination() != nil {
+			break
+		}
+	}
+
+	replayedInput := func(secret string) string {
+		var input []byte
+
+		// The recording becomes available shortly after the session ends
+		require.Eventually(t, func() bool {
+			replayStream, err := guestService.Replay(ctx, &api.ReplayRequest{
+				Locator:     locator,
+				Secret:      secret,
+				RecordingId: recordingID,
+				Speed:       100,
+			})
+			require.NoError(t, err)
+
+			input = nil
+
+			for {
+				response, err := replayStream.Recv()
+				if errors.Is(err, io.EOF) {
+					return true
+				}
+				if status.Code(err) == codes.NotFound {
+					return false
+				}
+				require.NoError(t, err)
+
+				input = append(input, response.GetInput().GetData()...)
+			}
+		}, 5*time.Second, 100*time.Millisecond)
+
+		return string(input)
+	}
+
+	require.Equal(t, "exit\n", replayedInput(secret))
+	require.Empty(t, replayedInput("read-only "+secret))
+}
@@ -0,0 +1,89 @@
+package filetransfer
+
+import (
+	"context"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/google/uuid"
+)
+
+// FileTransfer is a file download or upload requested by the Guest, which is carried
+// by the Guest's file transfer channel and the Host's file transfer data channel.
+type FileTransfer struct {
+	token   string
+	request *api.FileTransfer
+
+	subCtx context.Context
+	cancel context.CancelFunc
+
+	hostChan chan *HostEnd
+}
+
+// HostEnd is the Host's side of the file transfer.
+type HostEnd struct {
+	Channel api.HostService_FileTransferDataChannelServer
+
+	// Set when the Host has refused the file transfer
+	Error *api.Error
+}
+
+func New(ctx context.Context, request *api.FileTransfer) *FileTransfer {
+	subCtx, cancel := context.WithCancel(ctx)
+
+	return &FileTransfer{
+		token:    uuid.New().String(),
+		request:  request,
+		subCtx:   subCtx,
+		cancel:   cancel,
+		hostChan: make(chan *HostEnd),
+	}
+}
+
+func (fileTransfer *FileTransfer) Token() string {
+	return fileTransfer.token
+}
+
+func (fileTransfer *FileTransfer) Request() *api.FileTransfer {
+	return fileTransfer.request
+}
+
+func (fileTransfer *FileTransfer) Context() context.Context {
+	return fileTransfer.subCtx
+}
+
+// AttachHost hands the Host's side of the file transfer over to the Guest's side
+// and waits for the file transfer to finish, since the Host's channel is only
+// usable until its handler returns.
+func (fileTransfer *FileTransfer) AttachHost(ctx context.Context, hostEnd *HostEnd) bool {
+	select {
+	case fileTransfer.hostChan <- hostEnd:
+	case <-ctx.Done():
+		return false
+	case <-fileTransfer.subCtx.Done():
+		return false
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-fileTransfer.subCtx.Done():
+	}
+
+	return true
+}
+
+// WaitForHost waits for the Host to attach its side of the file transfer.
+func (fileTransfer *FileTransfer) WaitForHost(ctx context.Context) (*HostEnd, error) {
+	select {
+	case hostEnd := <-fileTransfer.hostChan:
+		return hostEnd, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-fileTransfer.subCtx.Done():
+		return nil, fileTransfer.subCtx.Err()
+	}
+}
+
+func (fileTransfer *FileTransfer) Close() error {
+	fileTransfer.cancel()
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// hostCertSubject returns the subject of the client certificate presented by the Host, which
+// is required once the host client CAs are configured. The certificate itself is verified
+// against these CAs during the TLS handshake on the host addresses, the Guests (e.g. the
+// browsers using gRPC-Web) are served on the other addresses and never asked for one.
+func (ts *TerminalServer) hostCertSubject(ctx context.Context) (string, error) {
+	if ts.hostClientCAs == nil {
+		return "", nil
+	}
+
+	if peerInfo, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := peerInfo.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) != 0 {
+			return tlsInfo.State.VerifiedChains[0][0].Subject.String(), nil
+		}
+	}
+
+	return "", status.Errorf(codes.Unauthenticated, "host must connect to the server's host address "+
+		"and present a client certificate issued by one of the CAs trusted by the server")
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/cirruslabs/terminal/internal/server/terminal"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	lockoutBaseDuration = 2 * time.Second
+	lockoutMaxDuration  = 15 * time.Minute
+
+	// There's no telling when the sessions will finish, so
+	// suggest the Guest to retry after some reasonable delay
+	tooManySessionsRetryDelay = 10 * time.Second
+)
+
+// admitGuest protects the Guests' authentication from the brute-force attempts: it limits the rate
+// of the authentication attempts across all the Guests and refuses the Guests while their client IP
+// or the locator they're trying to access is locked out after too many failed attempts.
+func (ts *TerminalServer) admitGuest(clientIP string, locator string) error {
+	if ts.helloLimiter != nil {
+		reservation := ts.helloLimiter.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+
+			return resourceExhausted(delay, "too many guests are connecting right now, please retry later")
+		}
+	}
+
+	if ts.clientIPLockout != nil {
+		if lockedOut := ts.clientIPLockout.LockedOut(clientIP); lockedOut > 0 {
+			return resourceExhausted(lockedOut, "too many failed attempts from this address, please retry later")
+		}
+	}
+
+	if ts.locatorLockout != nil {
+		if lockedOut := ts.locatorLockout.LockedOut(locator); lockedOut > 0 {
+			return resourceExhausted(lockedOut, "too many failed attempts to access this terminal, "+
+				"please retry later")
+		}
+	}
+
+	return nil
+}
+
+// guestFailed records the Guest's failed authentication attempt,
+// the locator is empty when the Guest has requested an unknown one.
+func (ts *TerminalServer) guestFailed(logger *zap.Logger, clientIP string, locator string) {
+	if ts.clientIPLockout != nil {
+		if lockedOut := ts.clientIPLockout.Failed(clientIP); lockedOut > 0 {
+			logger.Warn("locking out the client IP after too many failed attempts",
+				zap.String("client-ip", clientIP), zap.Duration("duration", lockedOut))
+		}
+	}
+
+	if ts.locatorLockout != nil && locator != "" {
+		if lockedOut := ts.locatorLockout.Failed(locator); lockedOut > 0 {
+			logger.Warn("locking out the locator after too many failed attempts",
+				zap.Duration("duration", lockedOut))
+		}
+	}
+}
+
+// guestSucceeded forgets the failed authentication attempts
+// of the Guest's client IP and the locator it has accessed.
+func (ts *TerminalServer) guestSucceeded(clientIP string, locator string) {
+	if ts.clientIPLockout != nil {
+		ts.clientIPLockout.Succeeded(clientIP)
+	}
+
+	if ts.locatorLockout != nil {
+		ts.locatorLockout.Succeeded(locator)
+	}
+}
+
+// clientIP returns the IP address of the Guest connected over gRPC.
+func (ts *TerminalServer) clientIP(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if clientIP := ts.proxiedClientIP(md); clientIP != "" {
+			return clientIP
+		}
+
+		if ts.clientIPHeader != "" {
+			if clientIP := forwardedAddress(md.Get(ts.clientIPHeader), ts.trustedProxies); clientIP != "" {
+				return clientIP
+			}
+		}
+	}
+
+	if peerInfo, ok := peer.FromContext(ctx); ok {
+		return hostOnly(peerInfo.Addr.String())
+	}
+
+	return ""
+}
+
+// httpClientIP returns the IP address of the Guest connected over HTTP.
+func (ts *TerminalServer) httpClientIP(request *http.Request) string {
+	if ts.clientIPHeader != "" {
+		if clientIP := forwardedAddress(request.Header.Values(ts.clientIPHeader), ts.trustedProxies); clientIP != "" {
+			return clientIP
+		}
+	}
+
+	return hostOnly(request.RemoteAddr)
+}
+
+// forwardedAddress returns the client's address from the X-Forwarded-For-like header values,
+// which is the one appended by the outermost of the trusted proxies, since the addresses to the
+// left of it are controlled by the client. Returns an empty string if there are no addresses.
+func forwardedAddress(values []string, trustedProxies int) string {
+	var addresses []string
+
+	// The header may be split across multiple fields, which are then joined in order
+	for _, value := range values {
+		for _, address := range strings.Split(value, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+
+	if len(addresses) == 0 {
+		return ""
+	}
+
+	// When there are fewer addresses than the trusted proxies, the
+	// client has connected through the inner proxies directly
+	return addresses[max(len(addresses)-max(trustedProxies, 1), 0)]
+}
+
+func hostOnly(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+
+	return host
+}
+
+// refusedSessionError tells the Guest to retry later when the terminal
+// has too many sessions, other errors are returned as is.
+func refusedSessionError(err error) error {
+	if errors.Is(err, terminal.ErrTooManySessions) {
+		return resourceExhausted(tooManySessionsRetryDelay,
+			"terminal has too many sessions, please retry once some of them finish")
+	}
+
+	return err
+}
+
+// retryDelay returns the retry delay from the status returned by resourceExhausted.
+func retryDelay(err error) time.Duration {
+	for _, detail := range status.Convert(err).Details() {
+		if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+			return retryInfo.RetryDelay.AsDuration()
+		}
+	}
+
+	return 0
+}
+
+// resourceExhausted returns a ResourceExhausted status that
+// tells the client how long to wait before retrying.
+func resourceExhausted(retryDelay time.Duration, message string) error {
+	st := status.New(codes.ResourceExhausted, message)
+
+	if detailedStatus, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryDelay),
+	}); err == nil {
+		st = detailedStatus
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,115 @@
+// Package lockout tracks the failed authentication attempts and temporarily
+// locks out the keys (e.g. the locators or the client IPs) with too many of them.
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+// How often to forget the keys whose failed attempts are no longer relevant
+const sweepInterval = time.Minute
+
+// Lockout locks the key out once it reaches the threshold of the consecutive failed attempts:
+// first for the base duration, then for twice as long after each subsequent failure, but no
+// longer than the max duration. The failed attempts are forgotten after a successful one or
+// once the max duration has passed since the last failed attempt.
+type Lockout struct {
+	threshold    int
+	baseDuration time.Duration
+	maxDuration  time.Duration
+
+	lock      sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+type entry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+func New(threshold int, baseDuration time.Duration, maxDuration time.Duration) *Lockout {
+	return &Lockout{
+		threshold:    threshold,
+		baseDuration: baseDuration,
+		maxDuration:  maxDuration,
+		entries:      make(map[string]*entry),
+		lastSweep:    time.Now(),
+	}
+}
+
+// LockedOut returns for how long the key remains locked out, or zero if it isn't.
+func (lockout *Lockout) LockedOut(key string) time.Duration {
+	lockout.lock.Lock()
+	defer lockout.lock.Unlock()
+
+	entry, ok := lockout.entries[key]
+	if !ok {
+		return 0
+	}
+
+	return max(time.Until(entry.lockedUntil), 0)
+}
+
+// Failed records a failed attempt and returns for how long
+// the key is now locked out, or zero if it isn't.
+func (lockout *Lockout) Failed(key string) time.Duration {
+	lockout.lock.Lock()
+	defer lockout.lock.Unlock()
+
+	now := time.Now()
+
+	lockout.sweep(now)
+
+	failedAttempts, ok := lockout.entries[key]
+	if !ok || lockout.isStale(failedAttempts, now) {
+		failedAttempts = &entry{}
+		lockout.entries[key] = failedAttempts
+	}
+
+	failedAttempts.failures++
+	failedAttempts.lastFailure = now
+
+	if failedAttempts.failures < lockout.threshold {
+		return 0
+	}
+
+	duration := lockout.maxDuration
+
+	// Prevent the overflow when doubling the duration too many times
+	if exponent := failedAttempts.failures - lockout.threshold; exponent < 32 {
+		duration = min(lockout.baseDuration<<exponent, lockout.maxDuration)
+	}
+
+	failedAttempts.lockedUntil = now.Add(duration)
+
+	return duration
+}
+
+// Succeeded forgets the failed attempts of the key.
+func (lockout *Lockout) Succeeded(key string) {
+	lockout.lock.Lock()
+	defer lockout.lock.Unlock()
+
+	delete(lockout.entries, key)
+}
+
+func (lockout *Lockout) isStale(failedAttempts *entry, now time.Time) bool {
+	return now.After(failedAttempts.lockedUntil) && now.Sub(failedAttempts.lastFailure) > lockout.maxDuration
+}
+
+func (lockout *Lockout) sweep(now time.Time) {
+	if now.Sub(lockout.lastSweep) < sweepInterval {
+		return
+	}
+
+	lockout.lastSweep = now
+
+	for key, failedAttempts := range lockout.entries {
+		if lockout.isStale(failedAttempts, now) {
+			delete(lockout.entries, key)
+		}
+	}
+}
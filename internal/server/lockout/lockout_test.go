@@ -0,0 +1,62 @@
+package lockout_test
+
+import (
+	"github.com/cirruslabs/terminal/internal/server/lockout"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLockoutGrowsExponentially(t *testing.T) {
+	keyLockout := lockout.New(3, time.Minute, 5*time.Minute)
+
+	require.Zero(t, keyLockout.Failed("key"))
+	require.Zero(t, keyLockout.Failed("key"))
+	require.Zero(t, keyLockout.LockedOut("key"))
+
+	require.Equal(t, time.Minute, keyLockout.Failed("key"))
+	require.Equal(t, 2*time.Minute, keyLockout.Failed("key"))
+	require.Equal(t, 4*time.Minute, keyLockout.Failed("key"))
+
+	// Capped at the max duration
+	require.Equal(t, 5*time.Minute, keyLockout.Failed("key"))
+
+	for range 100 {
+		keyLockout.Failed("key")
+	}
+
+	remaining := keyLockout.LockedOut("key")
+	require.Greater(t, remaining, 4*time.Minute)
+	require.LessOrEqual(t, remaining, 5*time.Minute)
+
+	// Other keys are not affected
+	require.Zero(t, keyLockout.LockedOut("other key"))
+}
+
+func TestLockoutIsLiftedOnSuccess(t *testing.T) {
+	keyLockout := lockout.New(1, time.Minute, time.Hour)
+
+	require.Equal(t, time.Minute, keyLockout.Failed("key"))
+	require.NotZero(t, keyLockout.LockedOut("key"))
+
+	keyLockout.Succeeded("key")
+	require.Zero(t, keyLockout.LockedOut("key"))
+
+	// Start counting from the beginning
+	require.Equal(t, time.Minute, keyLockout.Failed("key"))
+}
+
+func TestLockoutExpires(t *testing.T) {
+	keyLockout := lockout.New(1, 10*time.Millisecond, 20*time.Millisecond)
+
+	require.Equal(t, 10*time.Millisecond, keyLockout.Failed("key"))
+	require.Equal(t, 20*time.Millisecond, keyLockout.Failed("key"))
+
+	require.Eventually(t, func() bool {
+		return keyLockout.LockedOut("key") == 0
+	}, time.Second, time.Millisecond)
+
+	// The failed attempts are forgotten after the max duration
+	time.Sleep(30 * time.Millisecond)
+	require.Equal(t, 10*time.Millisecond, keyLockout.Failed("key"))
+}
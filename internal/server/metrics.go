@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/status"
+	"net"
+	"net/http"
+	"time"
+)
+
+const metricsNamespace = "terminal"
+
+// Values of the "channel" label.
+const (
+	channelControl  = "control"
+	channelData     = "data"
+	channelTerminal = "terminal"
+)
+
+// Values of the "direction" label.
+const (
+	directionHostToGuest = "host_to_guest"
+	directionGuestToHost = "guest_to_host"
+)
+
+var (
+	registeredTerminalsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "registered_terminals"),
+		"Number of the terminals registered on the server, including the ones reserved for the reconnecting hosts.",
+		nil, nil,
+	)
+	activeSessionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "active_sessions"),
+		"Number of the active sessions on the terminal.",
+		[]string{"locator"}, nil,
+	)
+	compressionSavedBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "compression_saved_bytes_total"),
+		"Number of the terminal output bytes saved by the compression on the links with the hosts and the guests.",
+		[]string{"link"}, nil,
+	)
+)
+
+// metrics are exposed in the Prometheus format on the metrics listener, if any.
+type metrics struct {
+	registry *prometheus.Registry
+
+	sessionDuration prometheus.Histogram
+	relayedBytes    *prometheus.CounterVec
+	helloFailures   *prometheus.CounterVec
+	sendFailures    *prometheus.CounterVec
+}
+
+func newMetrics(ts *TerminalServer) *metrics {
+	metrics := &metrics{
+		registry: prometheus.NewRegistry(),
+		sessionDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "session_duration_seconds",
+			Help:      "Duration of the finished sessions.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+		}),
+		relayedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "relayed_bytes_total",
+			Help:      "Number of the terminal output and input bytes relayed between the hosts and the guests.",
+		}, []string{"direction"}),
+		helloFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "hello_failures_total",
+			Help:      "Number of the channels refused during the Hello exchange by the gRPC status code.",
+		}, []string{"channel", "code"}),
+		sendFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "send_failures_total",
+			Help:      "Number of the messages that the server has failed to send on a channel.",
+		}, []string{"channel"}),
+	}
+
+	metrics.registry.MustRegister(
+		metrics.sessionDuration,
+		metrics.relayedBytes,
+		metrics.helloFailures,
+		metrics.sendFailures,
+		&terminalsCollector{ts: ts},
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return metrics
+}
+
+// helloFailed accounts for the channel refused during the Hello exchange and returns the error as is.
+func (metrics *metrics) helloFailed(channel string, err error) error {
+	metrics.helloFailures.WithLabelValues(channel, status.Code(err).String()).Inc()
+
+	return err
+}
+
+func (metrics *metrics) sendFailed(channel string) {
+	metrics.sendFailures.WithLabelValues(channel).Inc()
+}
+
+func (metrics *metrics) relayed(direction string, n int) {
+	metrics.relayedBytes.WithLabelValues(direction).Add(float64(n))
+}
+
+func (metrics *metrics) sessionFinished(duration time.Duration) {
+	metrics.sessionDuration.Observe(duration.Seconds())
+}
+
+// serve exposes the metrics on /metrics until the ctx is cancelled.
+func (metrics *metrics) serve(ctx context.Context, listener net.Listener) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(metrics.registry, promhttp.HandlerOpts{}))
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// terminalsCollector reports the terminals and their sessions as they are at
+// the scrape time, so that the series of the unregistered terminals disappear.
+type terminalsCollector struct {
+	ts *TerminalServer
+}
+
+func (collector *terminalsCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- registeredTerminalsDesc
+	descs <- activeSessionsDesc
+	descs <- compressionSavedBytesDesc
+}
+
+func (collector *terminalsCollector) Collect(metrics chan<- prometheus.Metric) {
+	ts := collector.ts
+
+	ts.terminalsLock.RLock()
+	defer ts.terminalsLock.RUnlock()
+
+	metrics <- prometheus.MustNewConstMetric(registeredTerminalsDesc, prometheus.GaugeValue,
+		float64(len(ts.terminals)))
+
+	for locator, terminal := range ts.terminals {
+		metrics <- prometheus.MustNewConstMetric(activeSessionsDesc, prometheus.GaugeValue,
+			float64(terminal.NumSessions()), locator)
+	}
+
+	metrics <- prometheus.MustNewConstMetric(compressionSavedBytesDesc, prometheus.CounterValue,
+		float64(ts.hostCompressionStats.Saved()), "host")
+	metrics <- prometheus.MustNewConstMetric(compressionSavedBytesDesc, prometheus.CounterValue,
+		float64(ts.guestCompressionStats.Saved()), "guest")
+}
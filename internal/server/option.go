@@ -2,7 +2,14 @@ package server
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"github.com/cirruslabs/terminal/internal/server/auth"
+	"github.com/cirruslabs/terminal/internal/server/registry"
+	"github.com/cirruslabs/terminal/internal/server/session"
+	"github.com/cirruslabs/terminal/internal/server/storage"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"time"
 )
 
 type Option func(*TerminalServer)
@@ -33,8 +40,230 @@ func WithTLSConfig(tlsConfig *tls.Config) Option {
 	}
 }
 
+// WithHostAddresses makes the server additionally listen for the Hosts on the specified addresses,
+// which is where the Hosts are asked for the client certificates (see WithHostClientCAs).
+func WithHostAddresses(hostAddresses []string) Option {
+	return func(ts *TerminalServer) {
+		ts.hostAddresses = hostAddresses
+	}
+}
+
+// WithHostClientCAs requires the Hosts to present a client certificate issued
+// by one of the specified CAs, which requires the TLS to be enabled and the
+// Hosts to connect to the separate host addresses (see WithHostAddresses).
+// The Guests are still served on the other addresses without being asked
+// for the client certificates, which the browsers would prompt the users for.
+func WithHostClientCAs(hostClientCAs *x509.CertPool) Option {
+	return func(ts *TerminalServer) {
+		ts.hostClientCAs = hostClientCAs
+	}
+}
+
 func WithGCPProjectID(gcpProjectID string) Option {
 	return func(ts *TerminalServer) {
 		ts.gcpProjectID = gcpProjectID
 	}
 }
+
+// WithLocatorGracePeriod specifies for how long the locator is reserved
+// after the host disconnects, so that it can be re-claimed by the same host
+// when it reconnects. Zero disables the reservation completely.
+func WithLocatorGracePeriod(locatorGracePeriod time.Duration) Option {
+	return func(ts *TerminalServer) {
+		ts.locatorGracePeriod = locatorGracePeriod
+	}
+}
+
+// WithSessionGracePeriod specifies for how long to keep the session alive
+// after the Guest in control of it disconnects, so that it can be resumed.
+// Zero disables the session resumption completely.
+func WithSessionGracePeriod(sessionGracePeriod time.Duration) Option {
+	return func(ts *TerminalServer) {
+		ts.sessionGracePeriod = sessionGracePeriod
+	}
+}
+
+// WithOutputBufferSize specifies how many bytes of the most recent terminal output
+// to keep for each session for replaying it to the Guests that resume or join it,
+// zero or a negative size disables the replay.
+func WithOutputBufferSize(outputBufferSize int) Option {
+	return func(ts *TerminalServer) {
+		ts.outputBufferSize = outputBufferSize
+	}
+}
+
+// WithFlowControlWindow specifies how many bytes of the terminal output the Host
+// can send for each session before waiting for the server to queue them for the
+// Guests, which limits the amount of output buffered by the server. Zero disables
+// the flow control.
+func WithFlowControlWindow(flowControlWindow int) Option {
+	return func(ts *TerminalServer) {
+		ts.flowControlWindow = flowControlWindow
+	}
+}
+
+// WithGuestBufferSize specifies how many bytes of the terminal output to queue
+// for each Guest before applying the slow Guest policy, see WithSlowGuestPolicy().
+func WithGuestBufferSize(guestBufferSize int) Option {
+	return func(ts *TerminalServer) {
+		ts.guestBufferSize = guestBufferSize
+	}
+}
+
+// WithSlowGuestPolicy specifies what to do when the Guest in control of the session can't keep
+// up with the terminal output: either pause the output for the whole session (the default) or drop
+// the oldest output queued for that Guest and let it know that the output was truncated.
+func WithSlowGuestPolicy(slowGuestPolicy session.SlowGuestPolicy) Option {
+	return func(ts *TerminalServer) {
+		ts.slowGuestPolicy = slowGuestPolicy
+	}
+}
+
+// WithObserverSlowGuestPolicy is like WithSlowGuestPolicy, but for the read-only Guests, which
+// by default have their oldest output dropped so that they can't pause the output for everyone.
+func WithObserverSlowGuestPolicy(observerSlowGuestPolicy session.SlowGuestPolicy) Option {
+	return func(ts *TerminalServer) {
+		ts.observerSlowGuestPolicy = observerSlowGuestPolicy
+	}
+}
+
+// WithMinProtocolVersion refuses the Hosts and the Guests that implement
+// an older protocol version. By default, all the versions are accepted,
+// including the Hosts and the Guests predating the versioning (version 0).
+func WithMinProtocolVersion(minProtocolVersion uint32) Option {
+	return func(ts *TerminalServer) {
+		ts.minProtocolVersion = minProtocolVersion
+	}
+}
+
+// WithSSHAddress enables the SSH gateway for the Guests on the specified address.
+func WithSSHAddress(sshAddress string) Option {
+	return func(ts *TerminalServer) {
+		ts.sshAddress = sshAddress
+	}
+}
+
+// WithMetricsAddress exposes the Prometheus metrics on /metrics on the specified address.
+func WithMetricsAddress(metricsAddress string) Option {
+	return func(ts *TerminalServer) {
+		ts.metricsAddress = metricsAddress
+	}
+}
+
+// WithRegistry lets multiple replicas of the server work behind a load balancer: the registry records
+// which replica owns each locator, and the replicas proxy the channels for the locators they don't own
+// to the owner. The peerAddress is where the other replicas can reach this one (e.g. "http://10.0.0.1:8080").
+func WithRegistry(registry registry.Registry, peerAddress string) Option {
+	return func(ts *TerminalServer) {
+		ts.registry = registry
+		ts.peerAddress = peerAddress
+	}
+}
+
+// WithPeerSecret makes the replicas pass the Guests' client IPs along with the proxied channels, so that
+// the owner locks out the Guest and not the proxying replica. The client IPs are signed with the secret
+// shared by all the replicas and are only trusted when the signature matches.
+func WithPeerSecret(peerSecret string) Option {
+	return func(ts *TerminalServer) {
+		ts.peerSecret = []byte(peerSecret)
+	}
+}
+
+// WithAuthenticator lets the Guests authenticate using the alternative credentials
+// (see GuestTerminalRequest.Hello's token) validated by the authenticator instead
+// of the Host's secrets.
+func WithAuthenticator(authenticator auth.Authenticator) Option {
+	return func(ts *TerminalServer) {
+		ts.authenticator = authenticator
+	}
+}
+
+// WithGuestHelloRateLimit limits the rate of the Guests' authentication attempts across all
+// the Guests using a token bucket with the specified rate (per second) and burst, 0 rate disables
+// the limit.
+func WithGuestHelloRateLimit(guestHelloRate float64, guestHelloBurst int) Option {
+	return func(ts *TerminalServer) {
+		ts.guestHelloRate = guestHelloRate
+		ts.guestHelloBurst = guestHelloBurst
+	}
+}
+
+// WithLockoutThreshold specifies after how many consecutive failed authentication attempts
+// the Guest's client IP and the locator it's trying to access are locked out for an exponentially
+// growing period, 0 disables the lockout.
+func WithLockoutThreshold(lockoutThreshold int) Option {
+	return func(ts *TerminalServer) {
+		ts.lockoutThreshold = lockoutThreshold
+	}
+}
+
+// WithClientIPHeader makes the server take the Guests' client IPs from the specified
+// header (e.g. "X-Forwarded-For") set by the load balancer in front of the server.
+//
+// The header's leftmost addresses are controlled by the clients, so the address is taken
+// from the right, skipping the addresses appended by all but the outermost of the specified
+// number of the trusted proxies (e.g. 1 when there's only the load balancer).
+func WithClientIPHeader(clientIPHeader string, trustedProxies int) Option {
+	return func(ts *TerminalServer) {
+		ts.clientIPHeader = clientIPHeader
+		ts.trustedProxies = trustedProxies
+	}
+}
+
+// WithMaxSessionsPerTerminal limits the number of the concurrent sessions on each terminal, 0 means no limit.
+func WithMaxSessionsPerTerminal(maxSessionsPerTerminal int) Option {
+	return func(ts *TerminalServer) {
+		ts.maxSessionsPerTerminal = maxSessionsPerTerminal
+	}
+}
+
+// WithDrainTimeout specifies for how long the draining server waits
+// for the existing sessions to finish before terminating them.
+func WithDrainTimeout(drainTimeout time.Duration) Option {
+	return func(ts *TerminalServer) {
+		ts.drainTimeout = drainTimeout
+	}
+}
+
+// WithSSHHostKey specifies the SSH gateway's host key,
+// an ephemeral one is generated by default.
+func WithSSHHostKey(sshHostKey ssh.Signer) Option {
+	return func(ts *TerminalServer) {
+		ts.sshHostKey = sshHostKey
+	}
+}
+
+// WithRecordingStorage records every session passing through the server into
+// the storage, the terminal input is only recorded when recordInput is true,
+// since it may contain sensitive data like passwords. The recorded input is only
+// replayed to the Guests using the trusted secret, not the read-only one.
+func WithRecordingStorage(recordingStorage storage.Storage, recordInput bool) Option {
+	return func(ts *TerminalServer) {
+		ts.recordingStorage = recordingStorage
+		ts.recordInput = recordInput
+	}
+}
+
+// WithPreview enables the HTTP preview proxy, which forwards the requests to
+// /preview/{locator}/{port}/... to the port on the Host's loopback interface.
+//
+// These previews share the origin with each other and with the server, so they're served
+// with a sandboxing Content-Security-Policy, which puts them into an opaque origin. This keeps
+// them from scripting the other previews and the server, but not from navigating to them, and
+// breaks the previews that rely on their origin (e.g. to use the cookies or the local storage).
+// See WithPreviewDomain for serving each terminal's previews from their own origin instead.
+func WithPreview(previewEnabled bool) Option {
+	return func(ts *TerminalServer) {
+		ts.previewEnabled = previewEnabled
+	}
+}
+
+// WithPreviewDomain serves the previews from the {locator}.{domain}/{port}/... URLs instead, so that each
+// terminal's previews have their own origin and don't need to be sandboxed. The domain should resolve to the
+// server (including its subdomains) and should only be used for the previews, since it's the {locator}.{domain}
+// host alone that tells apart the preview requests from the other requests.
+func WithPreviewDomain(previewDomain string) Option {
+	return func(ts *TerminalServer) {
+		ts.previewDomain = previewDomain
+	}
+}
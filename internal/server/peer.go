@@ -0,0 +1,263 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/cirruslabs/cirrus-ci-agent/pkg/grpchelper"
+	"github.com/cirruslabs/terminal/internal/server/registry"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"io"
+	"slices"
+	"time"
+)
+
+const (
+	// Set on the channels proxied from another replica of the server
+	proxiedMetadataKey = "x-terminal-proxied"
+
+	// Set on the channels proxied from another replica of the server that shares the peer secret
+	clientIPMetadataKey          = "x-terminal-client-ip"
+	clientIPSignatureMetadataKey = "x-terminal-client-ip-signature"
+
+	// How often to refresh the registrations of the locators owned by this replica
+	registryRefreshInterval = 20 * time.Second
+
+	// How long to wait for the registry when (un)registering the locators outside of an RPC
+	registryTimeout = 5 * time.Second
+)
+
+// Passed along with the proxied channels to the replica owning the terminal
+var forwardedMetadataKeys = []string{"x-cloud-trace-context"}
+
+// registerLocator records this replica as the owner of the locator in the registry.
+func (ts *TerminalServer) registerLocator(ctx context.Context, locator string) error {
+	return ts.registry.Register(ctx, locator, ts.peerAddress)
+}
+
+// unregisterLocator forgets the locator in the registry
+// if it's still owned by this replica.
+func (ts *TerminalServer) unregisterLocator(locator string) {
+	ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+	defer cancel()
+
+	if err := ts.registry.Unregister(ctx, locator, ts.peerAddress); err != nil {
+		ts.logger.Warn("failed to unregister the locator", LocatorField(locator), zap.Error(err))
+	}
+}
+
+// refreshRegistry periodically re-registers the locators owned by this replica,
+// so that they don't expire in the registries that expire them (e.g. Redis).
+func (ts *TerminalServer) refreshRegistry(ctx context.Context) {
+	ticker := time.NewTicker(registryRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		ts.terminalsLock.RLock()
+		locators := make([]string, 0, len(ts.terminals))
+		for locator := range ts.terminals {
+			locators = append(locators, locator)
+		}
+		ts.terminalsLock.RUnlock()
+
+		for _, locator := range locators {
+			registerCtx, cancel := context.WithTimeout(ctx, registryTimeout)
+			err := ts.registerLocator(registerCtx, locator)
+			cancel()
+
+			if err != nil {
+				ts.logger.Warn("failed to refresh the locator registration", LocatorField(locator), zap.Error(err))
+			}
+		}
+	}
+}
+
+// findOwner returns the peer link to the replica owning the locator,
+// or nil if the locator is unknown or this replica is the owner.
+func (ts *TerminalServer) findOwner(ctx context.Context, logger *zap.Logger, locator string) *grpc.ClientConn {
+	// Prevent the proxying loops when the registry is out of sync
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(proxiedMetadataKey)) != 0 {
+		return nil
+	}
+
+	address, err := ts.registry.Lookup(ctx, locator)
+	if err != nil {
+		if !errors.Is(err, registry.ErrNotFound) {
+			logger.Warn("failed to look up the replica owning the terminal", zap.Error(err))
+		}
+
+		return nil
+	}
+
+	if address == ts.peerAddress {
+		return nil
+	}
+
+	peerConn, err := ts.peerConn(address)
+	if err != nil {
+		logger.Warn("failed to connect to the replica owning the terminal", zap.String("peer-address", address),
+			zap.Error(err))
+
+		return nil
+	}
+
+	return peerConn
+}
+
+// peerConn returns the peer link to the replica reachable on the address,
+// the links are kept open and re-used for all the proxied channels.
+func (ts *TerminalServer) peerConn(address string) (*grpc.ClientConn, error) {
+	ts.peersLock.Lock()
+	defer ts.peersLock.Unlock()
+
+	if peerConn, ok := ts.peers[address]; ok {
+		return peerConn, nil
+	}
+
+	target, transportSecurity := grpchelper.TransportSettingsAsDialOption(address)
+
+	peerConn, err := grpc.Dial(target, transportSecurity)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.peers[address] = peerConn
+
+	return peerConn, nil
+}
+
+func (ts *TerminalServer) closePeerConns() {
+	ts.peersLock.Lock()
+	defer ts.peersLock.Unlock()
+
+	for address, peerConn := range ts.peers {
+		_ = peerConn.Close()
+
+		delete(ts.peers, address)
+	}
+}
+
+// peerContext marks the channel as proxied and passes
+// along the relevant metadata of the original channel.
+func (ts *TerminalServer) peerContext(ctx context.Context) context.Context {
+	outgoingMD := metadata.Pairs(proxiedMetadataKey, "true")
+
+	// Let the owner know the client IP of the Guest instead of this replica's
+	forwardedKeys := forwardedMetadataKeys
+	if ts.clientIPHeader != "" {
+		forwardedKeys = append(slices.Clip(forwardedKeys), ts.clientIPHeader)
+	}
+
+	if incomingMD, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range forwardedKeys {
+			if values := incomingMD.Get(key); len(values) != 0 {
+				outgoingMD.Set(key, values...)
+			}
+		}
+	}
+
+	if len(ts.peerSecret) != 0 {
+		clientIP := ts.clientIP(ctx)
+
+		outgoingMD.Set(clientIPMetadataKey, clientIP)
+		outgoingMD.Set(clientIPSignatureMetadataKey, hex.EncodeToString(ts.signClientIP(clientIP)))
+	}
+
+	return metadata.NewOutgoingContext(ctx, outgoingMD)
+}
+
+// proxiedClientIP returns the Guest's client IP passed along by the replica
+// that has proxied the channel, or an empty string if the signature doesn't match.
+func (ts *TerminalServer) proxiedClientIP(md metadata.MD) string {
+	if len(ts.peerSecret) == 0 {
+		return ""
+	}
+
+	clientIPs := md.Get(clientIPMetadataKey)
+	signatures := md.Get(clientIPSignatureMetadataKey)
+	if len(clientIPs) != 1 || len(signatures) != 1 {
+		return ""
+	}
+
+	signature, err := hex.DecodeString(signatures[0])
+	if err != nil || !hmac.Equal(signature, ts.signClientIP(clientIPs[0])) {
+		return ""
+	}
+
+	return clientIPs[0]
+}
+
+func (ts *TerminalServer) signClientIP(clientIP string) []byte {
+	mac := hmac.New(sha256.New, ts.peerSecret)
+	mac.Write([]byte(clientIP))
+
+	return mac.Sum(nil)
+}
+
+type downstreamChannel[Request any, Response any] interface {
+	Recv() (*Request, error)
+	Send(*Response) error
+}
+
+type upstreamChannel[Request any, Response any] interface {
+	Send(*Request) error
+	Recv() (*Response, error)
+	CloseSend() error
+}
+
+// proxyChannel relays the messages between the channel accepted by this replica and the channel opened
+// to the replica owning the terminal, starting with the first request that was already received.
+// The owner's status is returned as is, so that the peer on the other end can't tell the difference.
+func proxyChannel[Request any, Response any](
+	firstRequest *Request,
+	downstream downstreamChannel[Request, Response],
+	upstream upstreamChannel[Request, Response],
+) error {
+	if err := upstream.Send(firstRequest); err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			request, err := downstream.Recv()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					_ = upstream.CloseSend()
+				}
+
+				// Otherwise the upstream is cancelled along with the downstream
+				return
+			}
+
+			// The reason of the failure is returned by the upstream's Recv()
+			if err := upstream.Send(request); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		response, err := upstream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		if err := downstream.Send(response); err != nil {
+			return err
+		}
+	}
+}
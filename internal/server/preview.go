@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/terminal/internal/server/terminal"
+	"github.com/cirruslabs/terminal/internal/server/tunnel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	previewPathPrefix   = "/preview/"
+	previewSecretQuery  = "secret"
+	previewCookiePrefix = "terminal-preview-"
+	previewCookieMaxAge = 24 * time.Hour
+
+	previewIdleConnTimeout = 30 * time.Second
+
+	// Previews served under /preview/ share the origin with each other and with the server itself,
+	// so they're put into an opaque origin, which keeps them from scripting each other and the server
+	previewSandboxPolicy = "sandbox allow-scripts allow-forms allow-popups allow-modals allow-downloads"
+)
+
+var ErrPreviewTerminalNotFound = errors.New("terminal not found")
+
+// newPreviewProxy creates a reverse proxy that forwards the requests to /preview/{locator}/{port}/...
+// to the specified port on the Host's loopback interface through a tunnel.
+func (ts *TerminalServer) newPreviewProxy() *httputil.ReverseProxy {
+	transport := &http.Transport{
+		// The target's host is "{locator}:{port}", see servePreview()
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			locator, rawPort, err := net.SplitHostPort(address)
+			if err != nil {
+				return nil, err
+			}
+
+			port, err := strconv.ParseUint(rawPort, 10, 16)
+			if err != nil {
+				return nil, err
+			}
+
+			terminal := ts.findTerminal(locator)
+			if terminal == nil {
+				return nil, ErrPreviewTerminalNotFound
+			}
+
+			logger := ts.logger.With(LocatorField(locator), zap.Uint64("port", port))
+
+			// The connection outlives the request that has dialed
+			// it, it's closed by the transport once it's idle
+			newTunnel, hostEnd, err := ts.openTunnel(context.WithoutCancel(ctx), logger, terminal, uint32(port), "")
+			if err != nil {
+				return nil, errors.New(status.Convert(err).Message())
+			}
+
+			return tunnel.NewConn(newTunnel, hostEnd), nil
+		},
+		IdleConnTimeout: previewIdleConnTimeout,
+	}
+
+	return &httputil.ReverseProxy{
+		Rewrite: func(request *httputil.ProxyRequest) {
+			request.Out.URL.Scheme = "http"
+			request.SetXForwarded()
+		},
+		Transport: transport,
+		ModifyResponse: func(response *http.Response) error {
+			if ts.previewDomain == "" {
+				response.Header.Set("Content-Security-Policy", previewSandboxPolicy)
+			}
+
+			return nil
+		},
+		ErrorHandler: func(writer http.ResponseWriter, request *http.Request, err error) {
+			ts.logger.Debug("failed to proxy the preview request", zap.Error(err))
+			http.Error(writer, fmt.Sprintf("failed to reach the host: %v", err), http.StatusBadGateway)
+		},
+	}
+}
+
+// servePreview authenticates the request to /preview/{locator}/{port}/... (or to {locator}.{domain}/{port}/...
+// when the preview domain is configured) and passes it to the preview proxy. The first request should contain
+// the terminal's trusted secret in the "secret" query parameter, which is then exchanged for a cookie.
+func (ts *TerminalServer) servePreview(writer http.ResponseWriter, request *http.Request) {
+	locator, rawPort, path, prefix, ok := ts.parsePreviewRequest(request)
+	if !ok {
+		http.Error(writer, fmt.Sprintf("preview path should be in the %s{port}/... format", prefix),
+			http.StatusNotFound)
+		return
+	}
+
+	port, err := strconv.ParseUint(rawPort, 10, 16)
+	if err != nil || port == 0 {
+		http.Error(writer, fmt.Sprintf("invalid port %q", rawPort), http.StatusBadRequest)
+		return
+	}
+	rawPort = strconv.FormatUint(port, 10)
+
+	terminal := ts.findTerminal(locator)
+	if terminal == nil {
+		http.Error(writer, "terminal not found", http.StatusNotFound)
+		return
+	}
+
+	cookieName := previewCookiePrefix + hashed(locator)[:16]
+
+	// Exchange the secret for a cookie and redirect to the same
+	// URL without the secret, so that it doesn't end up in the history
+	if secret := request.URL.Query().Get(previewSecretQuery); secret != "" {
+		clientIP := ts.httpClientIP(request)
+		if err := ts.admitGuest(clientIP, locator); err != nil {
+			ts.logger.Warn("refusing the preview request", LocatorField(locator),
+				zap.String("client-ip", clientIP), zap.Error(err))
+			writer.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryDelay(err).Seconds()))))
+			http.Error(writer, status.Convert(err).Message(), http.StatusTooManyRequests)
+			return
+		}
+
+		if !terminal.IsSecretValid(secret) {
+			ts.guestFailed(ts.logger.With(LocatorField(locator)), clientIP, locator)
+			ts.logger.Warn("preview request with an invalid secret", LocatorField(locator),
+				HashedSecretField(secret))
+			http.Error(writer, "invalid secret", http.StatusForbidden)
+			return
+		}
+
+		ts.guestSucceeded(clientIP, locator)
+
+		http.SetCookie(writer, &http.Cookie{
+			Name:     cookieName,
+			Value:    ts.previewCookieValue(locator, secret),
+			Path:     prefix,
+			MaxAge:   int(previewCookieMaxAge.Seconds()),
+			Secure:   ts.tlsConfig != nil,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		query := request.URL.Query()
+		query.Del(previewSecretQuery)
+
+		redirectURL := *request.URL
+		redirectURL.RawQuery = query.Encode()
+
+		http.Redirect(writer, request, redirectURL.String(), http.StatusSeeOther)
+
+		return
+	}
+
+	cookie, err := request.Cookie(cookieName)
+	if err != nil || !ts.isPreviewCookieValid(terminal, cookie.Value) {
+		http.Error(writer, fmt.Sprintf("please authenticate first by appending ?%s=SECRET to the URL",
+			previewSecretQuery), http.StatusUnauthorized)
+		return
+	}
+
+	// Don't pass the preview cookie to the Host
+	removeCookie(request, cookieName)
+
+	outRequest := request.Clone(request.Context())
+	outRequest.URL.Path = path
+	outRequest.URL.RawPath = ""
+	outRequest.URL.Host = net.JoinHostPort(locator, rawPort)
+	outRequest.Host = net.JoinHostPort("localhost", rawPort)
+	outRequest.Header.Set("X-Forwarded-Prefix", prefix+rawPort)
+
+	ts.previewProxy.ServeHTTP(writer, outRequest)
+}
+
+// previewCookieValue derives the cookie value from the secret, so that
+// the cookie is invalidated once the Host starts using another secret.
+func (ts *TerminalServer) previewCookieValue(locator string, secret string) string {
+	mac := hmac.New(sha256.New, ts.previewCookieKey)
+	mac.Write([]byte(locator))
+	mac.Write([]byte{0})
+	mac.Write([]byte(secret))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (ts *TerminalServer) isPreviewCookieValid(terminal *terminal.Terminal, value string) bool {
+	for _, secret := range terminal.Secrets() {
+		// Only the trusted secret grants access to the previews
+		if !terminal.IsSecretValid(secret) {
+			continue
+		}
+
+		if hmac.Equal([]byte(ts.previewCookieValue(terminal.Locator(), secret)), []byte(value)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// previewLocator returns the locator of the terminal whose previews are served on the
+// request's host, or an empty string if it's not a {locator}.{domain} preview host.
+func (ts *TerminalServer) previewLocator(request *http.Request) string {
+	if ts.previewDomain == "" {
+		return ""
+	}
+
+	host := request.Host
+	if hostOnly, _, err := net.SplitHostPort(host); err == nil {
+		host = hostOnly
+	}
+
+	locator, ok := strings.CutSuffix(host, "."+ts.previewDomain)
+	if !ok || locator == "" || strings.Contains(locator, ".") {
+		return ""
+	}
+
+	return locator
+}
+
+// parsePreviewRequest splits the request's path into the locator, the port and the path
+// on the Host, and returns the prefix preceding the port, under which the preview is served.
+func (ts *TerminalServer) parsePreviewRequest(request *http.Request) (string, string, string, string, bool) {
+	if ts.previewDomain != "" {
+		port, path, ok := parsePreviewPath(request.URL.Path, "/")
+
+		return ts.previewLocator(request), port, path, "/", ok
+	}
+
+	rest, ok := strings.CutPrefix(request.URL.Path, previewPathPrefix)
+	if !ok {
+		return "", "", "", previewPathPrefix + "{locator}/", false
+	}
+
+	locator, _, ok := strings.Cut(rest, "/")
+	if !ok || locator == "" {
+		return "", "", "", previewPathPrefix + "{locator}/", false
+	}
+
+	prefix := previewPathPrefix + locator + "/"
+	port, path, ok := parsePreviewPath(request.URL.Path, prefix)
+
+	return locator, port, path, prefix, ok
+}
+
+// parsePreviewPath splits the {prefix}{port}/... path into the port and the path on the Host.
+func parsePreviewPath(path string, prefix string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(path, prefix)
+	if !ok {
+		return "", "", false
+	}
+
+	port, rest, _ := strings.Cut(rest, "/")
+	if port == "" {
+		return "", "", false
+	}
+
+	return port, "/" + rest, true
+}
+
+func removeCookie(request *http.Request, name string) {
+	cookies := request.Cookies()
+
+	request.Header.Del("Cookie")
+
+	for _, cookie := range cookies {
+		if cookie.Name != name {
+			request.AddCookie(cookie)
+		}
+	}
+}
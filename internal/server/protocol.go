@@ -0,0 +1,34 @@
+package server
+
+import (
+	"github.com/cirruslabs/terminal/internal/protocol"
+	"github.com/cirruslabs/terminal/internal/server/terminal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// checkProtocolVersion refuses the peers implementing a protocol version older than the configured one.
+func (ts *TerminalServer) checkProtocolVersion(peer string, version uint32) error {
+	if version < ts.minProtocolVersion {
+		return status.Errorf(codes.FailedPrecondition, "%s protocol version %d is no longer supported, "+
+			"please upgrade to the protocol version %d or newer", peer, version, ts.minProtocolVersion)
+	}
+
+	return nil
+}
+
+// requireHostCapability refuses the Guest's request for the feature when the Host
+// doesn't support it (e.g. because it's an older version baked into a CI image).
+func requireHostCapability(terminal *terminal.Terminal, capability string, feature string) error {
+	if !terminal.HasHostCapability(capability) {
+		return status.Errorf(codes.FailedPrecondition, "the host doesn't support %s (capability %q), "+
+			"it needs to be upgraded first", feature, capability)
+	}
+
+	return nil
+}
+
+// negotiateCapabilities returns the peer's capabilities that the server supports too.
+func negotiateCapabilities(peerCapabilities []string) []string {
+	return protocol.Intersect(protocol.ServerCapabilities, peerCapabilities)
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/asciicast"
+	"github.com/cirruslabs/terminal/internal/server/session"
+	"github.com/cirruslabs/terminal/internal/server/storage"
+	"github.com/cirruslabs/terminal/internal/server/terminal"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// Dimensions that the Host uses when the Guest hasn't requested any.
+const (
+	defaultWidthColumns = 80
+	defaultHeightRows   = 24
+)
+
+// sessionRecording records the session passing through the DataChannel,
+// recording failures shouldn't affect the session, so they are only logged once.
+//
+// All methods are no-op on a nil sessionRecording, which is used when the recording is disabled.
+type sessionRecording struct {
+	logger    *zap.Logger
+	recording *asciicast.Writer
+	errOnce   sync.Once
+}
+
+// startRecording starts recording the session into the recording storage,
+// returns nil if the recording is disabled or has failed to start.
+func (ts *TerminalServer) startRecording(
+	logger *zap.Logger,
+	terminal *terminal.Terminal,
+	session *session.Session,
+) *sessionRecording {
+	if ts.recordingStorage == nil {
+		return nil
+	}
+
+	var hashedSecrets, hashedInputSecrets []string
+
+	for _, secret := range terminal.Secrets() {
+		hashedSecrets = append(hashedSecrets, hashed(secret))
+
+		// The input may contain passwords typed by the Guest in control of the
+		// session, so it's not meant for the read-only Guests that can't see it live
+		if terminal.IsSecretValid(secret) {
+			hashedInputSecrets = append(hashedInputSecrets, hashed(secret))
+		}
+	}
+
+	writer, err := ts.recordingStorage.Create(storage.Key{
+		Locator:     terminal.Locator(),
+		HashedToken: hashed(session.Token()),
+	}, &storage.Metadata{
+		HashedSecrets:      hashedSecrets,
+		HashedInputSecrets: hashedInputSecrets,
+	})
+	if err != nil {
+		logger.Warn("failed to start recording the session", zap.Error(err))
+
+		return nil
+	}
+
+	header := asciicast.Header{
+		WidthColumns: defaultWidthColumns,
+		HeightRows:   defaultHeightRows,
+		Timestamp:    time.Now(),
+	}
+
+	if dimensions := session.RequestedDimensions(); dimensions != nil {
+		header.WidthColumns = dimensions.WidthColumns
+		header.HeightRows = dimensions.HeightRows
+	}
+
+	asciicastWriter, err := asciicast.NewWriter(writer, header, ts.recordInput)
+	if err != nil {
+		logger.Warn("failed to start recording the session", zap.Error(err))
+		_ = writer.Close()
+
+		return nil
+	}
+
+	return &sessionRecording{
+		logger:    logger,
+		recording: asciicastWriter,
+	}
+}
+
+func (sr *sessionRecording) Output(data *api.Data) {
+	if sr == nil {
+		return
+	}
+
+	sr.record(sr.recording.Output(data.Data))
+}
+
+func (sr *sessionRecording) Input(data []byte) {
+	if sr == nil {
+		return
+	}
+
+	sr.record(sr.recording.Input(data))
+}
+
+func (sr *sessionRecording) Resize(dimensions *api.TerminalDimensions) {
+	if sr == nil {
+		return
+	}
+
+	sr.record(sr.recording.Resize(dimensions.WidthColumns, dimensions.HeightRows))
+}
+
+func (sr *sessionRecording) Close() {
+	if sr == nil {
+		return
+	}
+
+	if err := sr.recording.Close(); err != nil {
+		sr.logger.Warn("failed to finish the session recording", zap.Error(err))
+	}
+}
+
+func (sr *sessionRecording) record(err error) {
+	if err == nil {
+		return
+	}
+
+	sr.errOnce.Do(func() {
+		sr.logger.Warn("failed to record the session", zap.Error(err))
+	})
+}
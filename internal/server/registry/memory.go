@@ -0,0 +1,51 @@
+package registry
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory keeps the locators in the process memory, which is only
+// sufficient when there's a single replica of the server.
+type Memory struct {
+	lock      sync.RWMutex
+	addresses map[string]string
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		addresses: make(map[string]string),
+	}
+}
+
+func (registry *Memory) Register(ctx context.Context, locator string, address string) error {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	registry.addresses[locator] = address
+
+	return nil
+}
+
+func (registry *Memory) Unregister(ctx context.Context, locator string, address string) error {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	if registry.addresses[locator] == address {
+		delete(registry.addresses, locator)
+	}
+
+	return nil
+}
+
+func (registry *Memory) Lookup(ctx context.Context, locator string) (string, error) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+
+	address, ok := registry.addresses[locator]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return address, nil
+}
@@ -0,0 +1,57 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+const (
+	redisKeyPrefix = "terminal:locator:"
+
+	// RedisTTL is for how long the locator is kept in Redis since it was last (re-)registered,
+	// so that the locators of the crashed replicas eventually disappear.
+	RedisTTL = time.Minute
+)
+
+// Deletes the key only if it still holds the expected value
+var redisCompareAndDelete = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Redis keeps the locators in Redis shared by all the replicas of the server.
+// The replicas are expected to re-register their locators more often than RedisTTL.
+type Redis struct {
+	client redis.UniversalClient
+}
+
+func NewRedis(client redis.UniversalClient) *Redis {
+	return &Redis{
+		client: client,
+	}
+}
+
+func (registry *Redis) Register(ctx context.Context, locator string, address string) error {
+	return registry.client.Set(ctx, redisKeyPrefix+locator, address, RedisTTL).Err()
+}
+
+func (registry *Redis) Unregister(ctx context.Context, locator string, address string) error {
+	return redisCompareAndDelete.Run(ctx, registry.client, []string{redisKeyPrefix + locator}, address).Err()
+}
+
+func (registry *Redis) Lookup(ctx context.Context, locator string) (string, error) {
+	address, err := registry.client.Get(ctx, redisKeyPrefix+locator).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", ErrNotFound
+		}
+
+		return "", err
+	}
+
+	return address, nil
+}
@@ -0,0 +1,25 @@
+// Package registry provides the backends for recording which server replica owns each
+// locator, so that the replicas behind a load balancer can route the channels to the owner.
+package registry
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNotFound = errors.New("locator not found")
+
+// Registry maps the locators to the addresses of the server replicas owning them.
+type Registry interface {
+	// Register records that the locator is owned by the replica reachable on the address,
+	// registering the same locator again refreshes the record.
+	Register(ctx context.Context, locator string, address string) error
+
+	// Unregister forgets the locator, but only if it's still owned
+	// by the replica reachable on the address.
+	Unregister(ctx context.Context, locator string, address string) error
+
+	// Lookup returns the address of the replica owning
+	// the locator or ErrNotFound if there's no such locator.
+	Lookup(ctx context.Context, locator string) (string, error)
+}
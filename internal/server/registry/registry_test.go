@@ -0,0 +1,62 @@
+package registry_test
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/cirruslabs/terminal/internal/server/registry"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testRegistry(t *testing.T, locatorRegistry registry.Registry) {
+	ctx := context.Background()
+
+	_, err := locatorRegistry.Lookup(ctx, "locator")
+	require.ErrorIs(t, err, registry.ErrNotFound)
+
+	require.NoError(t, locatorRegistry.Register(ctx, "locator", "http://replica-1:8080"))
+
+	address, err := locatorRegistry.Lookup(ctx, "locator")
+	require.NoError(t, err)
+	require.Equal(t, "http://replica-1:8080", address)
+
+	// Only the owning replica can unregister the locator
+	require.NoError(t, locatorRegistry.Unregister(ctx, "locator", "http://replica-2:8080"))
+
+	address, err = locatorRegistry.Lookup(ctx, "locator")
+	require.NoError(t, err)
+	require.Equal(t, "http://replica-1:8080", address)
+
+	require.NoError(t, locatorRegistry.Unregister(ctx, "locator", "http://replica-1:8080"))
+
+	_, err = locatorRegistry.Lookup(ctx, "locator")
+	require.ErrorIs(t, err, registry.ErrNotFound)
+}
+
+func TestMemory(t *testing.T) {
+	testRegistry(t, registry.NewMemory())
+}
+
+func TestRedis(t *testing.T) {
+	testRegistry(t, registry.NewRedis(redis.NewClient(&redis.Options{
+		Addr: miniredis.RunT(t).Addr(),
+	})))
+}
+
+func TestRedisLocatorsExpire(t *testing.T) {
+	ctx := context.Background()
+
+	redisServer := miniredis.RunT(t)
+
+	locatorRegistry := registry.NewRedis(redis.NewClient(&redis.Options{
+		Addr: redisServer.Addr(),
+	}))
+
+	require.NoError(t, locatorRegistry.Register(ctx, "locator", "http://replica-1:8080"))
+
+	redisServer.FastForward(registry.RedisTTL)
+
+	_, err := locatorRegistry.Lookup(ctx, "locator")
+	require.ErrorIs(t, err, registry.ErrNotFound)
+}
@@ -0,0 +1,288 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/protocol"
+	"github.com/cirruslabs/terminal/internal/server/filetransfer"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"time"
+)
+
+// How long to wait for the Host to open the file transfer data channel.
+const fileTransferEstablishmentTimeout = 30 * time.Second
+
+func (ts *TerminalServer) FileTransferChannel(channel api.GuestService_FileTransferChannelServer) error {
+	logger := ts.logger.With(ts.TraceContext(channel.Context())...)
+
+	// Guest begins the file transfer by sending a Hello message
+	// with the credentials of the terminal it wants to talk to
+	requestFromGuest, err := channel.Recv()
+	if err != nil {
+		logger.Warn("failed to receive a Hello message")
+		return err
+	}
+	helloFromGuest := requestFromGuest.GetHello()
+	if helloFromGuest == nil {
+		logger.Warn("expected a Hello message, got something else")
+		return status.Errorf(codes.FailedPrecondition, "expected a Hello message")
+	}
+
+	logger = logger.With(LocatorField(helloFromGuest.Locator), HashedSecretField(helloFromGuest.Secret))
+
+	request := helloFromGuest.FileTransfer
+	if request == nil || request.Path == "" {
+		logger.Warn("guest didn't specify the file to transfer")
+		return status.Errorf(codes.InvalidArgument, "file to transfer is not specified")
+	}
+
+	switch request.Direction {
+	case api.FileTransfer_DOWNLOAD, api.FileTransfer_UPLOAD:
+	default:
+		logger.Warn("guest specified an invalid file transfer direction",
+			zap.Stringer("direction", request.Direction))
+		return status.Errorf(codes.InvalidArgument, "invalid file transfer direction %s", request.Direction)
+	}
+
+	logger = logger.With(zap.Stringer("direction", request.Direction), zap.String("path", request.Path))
+
+	clientIP := ts.clientIP(channel.Context())
+	if err := ts.admitGuest(clientIP, helloFromGuest.Locator); err != nil {
+		logger.Warn("refusing the guest", zap.String("client-ip", clientIP), zap.Error(err))
+		return err
+	}
+
+	terminal := ts.findTerminal(helloFromGuest.Locator)
+	if terminal == nil {
+		ts.guestFailed(logger, clientIP, "")
+		logger.Warn("terminal with the specified locator is not registered on this server")
+		return status.Errorf(codes.NotFound, "terminal with locator %q is not registered on this server",
+			helloFromGuest.Locator)
+	}
+
+	// File transfers give access to the Host's file system, so only the trusted secret allows them
+	if !terminal.IsSecretValid(helloFromGuest.Secret) {
+		ts.guestFailed(logger, clientIP, terminal.Locator())
+		logger.Warn("guest provided an invalid secret")
+		return status.Errorf(codes.PermissionDenied, "invalid secret")
+	}
+
+	ts.guestSucceeded(clientIP, terminal.Locator())
+
+	// Older Hosts would drop the control channel upon receiving a FileTransferRequest
+	if err := requireHostCapability(terminal, protocol.CapabilityFileTransfer, "file transfers"); err != nil {
+		logger.Warn("host doesn't support file transfers")
+		return err
+	}
+
+	fileTransfer := filetransfer.New(channel.Context(), request)
+	defer fileTransfer.Close()
+
+	logger = logger.With(HashedTokenField(fileTransfer.Token()))
+
+	if err := terminal.RegisterFileTransfer(fileTransfer); err != nil {
+		logger.Warn("failed to register a new file transfer", zap.Error(err))
+		return status.Errorf(codes.Unavailable, "%v", err)
+	}
+	defer terminal.UnregisterFileTransfer(fileTransfer)
+
+	hostEnd, err := ts.waitForFileTransferHost(logger, terminal.NewFileTransferChan, fileTransfer)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("started new file transfer")
+
+	// Each of the Goroutines below proxies its own direction of the file transfer
+	fromGuestErrChan := make(chan error, 1)
+	fromHostErrChan := make(chan error, 1)
+
+	go func() {
+		fromGuestErrChan <- fileTransferFromGuest(channel, hostEnd.Channel)
+	}()
+
+	go func() {
+		fromHostErrChan <- fileTransferFromHost(channel, hostEnd.Channel)
+	}()
+
+	// The file transfer is complete once the Host closes its side,
+	// since for uploads it sends a FileTrailer after storing the file
+	select {
+	case err = <-fromHostErrChan:
+	case err = <-fromGuestErrChan:
+		if err == nil {
+			err = <-fromHostErrChan
+		}
+	}
+
+	if err != nil {
+		if channel.Context().Err() == nil {
+			logger.Info("file transfer has failed", zap.Error(err))
+		}
+
+		return err
+	}
+
+	logger.Info("file transfer was completed")
+
+	return nil
+}
+
+// waitForFileTransferHost asks the Host to open the file transfer data channel and waits
+// for it to do so, the returned errors are gRPC statuses.
+func (ts *TerminalServer) waitForFileTransferHost(
+	logger *zap.Logger,
+	newFileTransferChan chan *filetransfer.FileTransfer,
+	fileTransfer *filetransfer.FileTransfer,
+) (*filetransfer.HostEnd, error) {
+	establishCtx, cancel := context.WithTimeout(fileTransfer.Context(), fileTransferEstablishmentTimeout)
+	defer cancel()
+
+	select {
+	case newFileTransferChan <- fileTransfer:
+	case <-establishCtx.Done():
+		logger.Warn("host didn't pick up the file transfer in time")
+		return nil, status.Errorf(codes.Unavailable, "host didn't pick up the file transfer in time")
+	}
+
+	hostEnd, err := fileTransfer.WaitForHost(establishCtx)
+	if err != nil {
+		logger.Warn("host didn't open the file transfer data channel in time")
+		return nil, status.Errorf(codes.Unavailable, "host didn't open the file transfer data channel in time")
+	}
+
+	if hostEnd.Error != nil {
+		logger.Info("host has refused the file transfer", zap.String("error", hostEnd.Error.Message))
+		return nil, status.Errorf(codes.FailedPrecondition, "host has refused the file transfer: %s",
+			hostEnd.Error.Message)
+	}
+
+	return hostEnd, nil
+}
+
+// fileTransferFromGuest proxies the uploaded file from the Guest to the Host until the Guest closes its side.
+func fileTransferFromGuest(
+	guestChannel api.GuestService_FileTransferChannelServer,
+	hostChannel api.HostService_FileTransferDataChannelServer,
+) error {
+	for {
+		requestFromGuest, err := guestChannel.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		responseToHost := &api.HostFileTransferResponse{}
+
+		switch op := requestFromGuest.Operation.(type) {
+		case *api.GuestFileTransferRequest_Chunk:
+			responseToHost.Operation = &api.HostFileTransferResponse_Chunk{
+				Chunk: op.Chunk,
+			}
+		case *api.GuestFileTransferRequest_Trailer:
+			responseToHost.Operation = &api.HostFileTransferResponse_Trailer{
+				Trailer: op.Trailer,
+			}
+		default:
+			return status.Errorf(codes.FailedPrecondition, "expected a Chunk or a Trailer message")
+		}
+
+		if err := hostChannel.Send(responseToHost); err != nil {
+			return err
+		}
+	}
+}
+
+// fileTransferFromHost proxies the downloaded file (or the upload acknowledgement)
+// from the Host to the Guest until the Host closes its side.
+func fileTransferFromHost(
+	guestChannel api.GuestService_FileTransferChannelServer,
+	hostChannel api.HostService_FileTransferDataChannelServer,
+) error {
+	for {
+		requestFromHost, err := hostChannel.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		responseToGuest := &api.GuestFileTransferResponse{}
+
+		switch op := requestFromHost.Operation.(type) {
+		case *api.HostFileTransferRequest_Header:
+			responseToGuest.Operation = &api.GuestFileTransferResponse_Header{
+				Header: op.Header,
+			}
+		case *api.HostFileTransferRequest_Chunk:
+			responseToGuest.Operation = &api.GuestFileTransferResponse_Chunk{
+				Chunk: op.Chunk,
+			}
+		case *api.HostFileTransferRequest_Trailer:
+			responseToGuest.Operation = &api.GuestFileTransferResponse_Trailer{
+				Trailer: op.Trailer,
+			}
+		case *api.HostFileTransferRequest_Error:
+			return status.Errorf(codes.Aborted, "host has failed the file transfer: %s", op.Error.Message)
+		default:
+			return status.Errorf(codes.FailedPrecondition, "expected a Header, a Chunk, a Trailer or an Error message")
+		}
+
+		if err := guestChannel.Send(responseToGuest); err != nil {
+			return err
+		}
+	}
+}
+
+func (ts *TerminalServer) FileTransferDataChannel(channel api.HostService_FileTransferDataChannelServer) error {
+	logger := ts.logger.With(ts.TraceContext(channel.Context())...)
+
+	// Host begins the channel by sending a Hello message
+	// with the token it received from the control channel
+	requestFromHost, err := channel.Recv()
+	if err != nil {
+		logger.Warn("failed to receive a Hello message", zap.Error(err))
+		return err
+	}
+	helloFromHost := requestFromHost.GetHello()
+	if helloFromHost == nil {
+		logger.Warn("expected a Hello message, got something else")
+		return status.Errorf(codes.FailedPrecondition, "expected a Hello message")
+	}
+
+	logger = logger.With(LocatorField(helloFromHost.Locator), HashedTokenField(helloFromHost.Token))
+
+	terminal := ts.findTerminal(helloFromHost.Locator)
+	if terminal == nil {
+		logger.Warn("terminal with the specified locator not found")
+		return status.Errorf(codes.NotFound, "terminal with locator %q not found", helloFromHost.Locator)
+	}
+
+	fileTransfer := terminal.FindFileTransfer(helloFromHost.Token)
+	if fileTransfer == nil {
+		logger.Warn("terminal has no active file transfers with the specified token")
+		return status.Errorf(codes.NotFound, "terminal %q has no active file transfers with the specified token",
+			terminal.Locator())
+	}
+
+	hostEnd := &filetransfer.HostEnd{
+		Channel: channel,
+		Error:   helloFromHost.Error,
+	}
+
+	// The Guest's side proxies the file, we just need to keep the channel open until it's done
+	if !fileTransfer.AttachHost(channel.Context(), hostEnd) {
+		return status.Errorf(codes.Aborted, "file transfer was closed before the host has attached to it")
+	}
+
+	return nil
+}
@@ -1,13 +1,27 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/compression"
+	"github.com/cirruslabs/terminal/internal/protocol"
 	"github.com/cirruslabs/terminal/internal/server/session"
+	"github.com/cirruslabs/terminal/internal/server/terminal"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"io"
+	"slices"
+	"time"
 )
 
+var errSessionTerminated = errors.New("session was terminated")
+
+// replayChunkSize limits the size of the individual Data messages
+// used to replay the missed terminal output to the resuming Guests.
+const replayChunkSize = 32 * 1024
+
 func (ts *TerminalServer) TerminalChannel(channel api.GuestService_TerminalChannelServer) error {
 	logger := ts.logger.With(ts.TraceContext(channel.Context())...)
 
@@ -16,137 +30,503 @@ func (ts *TerminalServer) TerminalChannel(channel api.GuestService_TerminalChann
 	requestFromGuest, err := channel.Recv()
 	if err != nil {
 		logger.Warn("failed to receive a Hello message")
-		return err
+		return ts.metrics.helloFailed(channelTerminal, err)
 	}
 	helloFromGuest := requestFromGuest.GetHello()
 	if helloFromGuest == nil {
 		logger.Warn("expected a Hello message, got something else")
-		return status.Errorf(codes.FailedPrecondition, "expected a Hello message")
+		return ts.metrics.helloFailed(channelTerminal,
+			status.Errorf(codes.FailedPrecondition, "expected a Hello message"))
 	}
 
 	logger = logger.With(LocatorField(helloFromGuest.Locator), HashedSecretField(helloFromGuest.Secret))
 
+	if err := ts.checkProtocolVersion("guest", helloFromGuest.ProtocolVersion); err != nil {
+		logger.Warn("refusing the guest", zap.Uint32("protocol-version", helloFromGuest.ProtocolVersion))
+		return ts.metrics.helloFailed(channelTerminal, err)
+	}
+
 	// Find a terminal with the requested locator
 	terminal := ts.findTerminal(helloFromGuest.Locator)
 	if terminal == nil {
+		// The terminal might be registered on another replica of the server
+		if peerConn := ts.findOwner(channel.Context(), logger, helloFromGuest.Locator); peerConn != nil {
+			logger.Info("proxying the terminal channel to the replica owning the terminal",
+				zap.String("peer", peerConn.Target()))
+
+			upstream, err := api.NewGuestServiceClient(peerConn).TerminalChannel(ts.peerContext(channel.Context()))
+			if err != nil {
+				return err
+			}
+
+			return proxyChannel[api.GuestTerminalRequest, api.GuestTerminalResponse](requestFromGuest,
+				channel, upstream)
+		}
+	}
+
+	clientIP := ts.clientIP(channel.Context())
+	if err := ts.admitGuest(clientIP, helloFromGuest.Locator); err != nil {
+		logger.Warn("refusing the guest", zap.String("client-ip", clientIP), zap.Error(err))
+		return ts.metrics.helloFailed(channelTerminal, err)
+	}
+
+	if terminal == nil {
+		ts.guestFailed(logger, clientIP, "")
 		logger.Warn("terminal with the specified locator is not registered on this server")
-		return status.Errorf(codes.NotFound, "terminal with locator %q is not registered on this server",
-			helloFromGuest.Locator)
+		return ts.metrics.helloFailed(channelTerminal, status.Errorf(codes.NotFound,
+			"terminal with locator %q is not registered on this server", helloFromGuest.Locator))
 	}
 
 	// Authenticate the Guest
-	if !terminal.IsSecretValid(helloFromGuest.Secret) {
-		logger.Warn("guest provided an invalid secret")
-		return status.Errorf(codes.PermissionDenied, "invalid secret")
+	var readOnly bool
+
+	switch {
+	case helloFromGuest.Token != "":
+		// Alternative credentials are validated by the authenticator instead of the Host's secrets
+		if ts.authenticator == nil {
+			logger.Warn("guest provided a token, but token authentication is disabled on this server")
+			return ts.metrics.helloFailed(channelTerminal, status.Errorf(codes.PermissionDenied,
+				"token authentication is disabled on this server"))
+		}
+
+		identity, err := ts.authenticator.Authenticate(channel.Context(), terminal.Locator(), helloFromGuest.Token)
+		if err != nil {
+			ts.guestFailed(logger, clientIP, terminal.Locator())
+			logger.Warn("guest provided an invalid token", zap.Error(err))
+			return ts.metrics.helloFailed(channelTerminal, status.Errorf(codes.PermissionDenied, "invalid token"))
+		}
+
+		readOnly = identity.ReadOnly
+
+		logger = logger.With(SubjectField(identity.Subject))
+		logger.Info("guest has authenticated using a token", zap.Bool("read-only", readOnly))
+	case !terminal.IsSecretValid(helloFromGuest.Secret):
+		if !terminal.IsReadOnlySecretValid(helloFromGuest.Secret) {
+			ts.guestFailed(logger, clientIP, terminal.Locator())
+			logger.Warn("guest provided an invalid secret")
+			return ts.metrics.helloFailed(channelTerminal, status.Errorf(codes.PermissionDenied, "invalid secret"))
+		}
+
+		readOnly = true
+	}
+
+	ts.guestSucceeded(clientIP, terminal.Locator())
+
+	switch {
+	case helloFromGuest.ResumeToken != "":
+		// Resume the session previously started by this Guest
+		if readOnly {
+			logger.Warn("guest with a read-only secret tried to resume a session")
+			return ts.metrics.helloFailed(channelTerminal, status.Errorf(codes.PermissionDenied,
+				"read-only secret only allows joining existing sessions"))
+		}
+
+		session := terminal.FindSessionByResumeToken(helloFromGuest.ResumeToken)
+		if session == nil {
+			logger.Warn("terminal has no active sessions with the specified resume token")
+			return ts.metrics.helloFailed(channelTerminal, status.Errorf(codes.NotFound,
+				"terminal %q has no active sessions with the specified resume token", terminal.Locator()))
+		}
+
+		logger = logger.With(HashedTokenField(session.Token()))
+
+		guest, replay, replayOffset := session.Resume(channel.Context(), false, helloFromGuest.ResumeOffset)
+
+		logger.Info("resumed an existing session", zap.Uint64("requested-offset", helloFromGuest.ResumeOffset),
+			zap.Uint64("actual-offset", replayOffset))
+
+		return ts.serveGuest(logger, terminal, session, guest, replay, replayOffset, helloFromGuest, channel)
+	case helloFromGuest.SessionId != "":
+		// Join an existing session on this terminal
+		session := terminal.FindSessionByID(helloFromGuest.SessionId)
+		if session == nil {
+			logger.Warn("terminal has no active sessions with the specified ID")
+			return ts.metrics.helloFailed(channelTerminal, status.Errorf(codes.NotFound,
+				"terminal %q has no active sessions with the specified ID", terminal.Locator()))
+		}
+
+		logger = logger.With(HashedTokenField(session.Token()))
+
+		// Only the Guest that has started the session is allowed to control it,
+		// replay the buffered output so that the joined Guest can catch up
+		guest, replay, replayOffset := session.Resume(channel.Context(), true, 0)
+
+		logger.Info("joined an existing session")
+
+		return ts.serveGuest(logger, terminal, session, guest, replay, replayOffset, helloFromGuest, channel)
+	case readOnly:
+		logger.Warn("guest with a read-only secret tried to start a new session")
+		return ts.metrics.helloFailed(channelTerminal, status.Errorf(codes.PermissionDenied,
+			"read-only secret only allows joining existing sessions"))
+	}
+
+	session, guest, err := ts.startSession(channel.Context(), logger, terminal, helloFromGuest.RequestedDimensions,
+		helloFromGuest.Command, helloFromGuest.ShellOverride)
+	if err != nil {
+		return ts.metrics.helloFailed(channelTerminal, err)
+	}
+
+	return ts.serveGuest(logger.With(HashedTokenField(session.Token())), terminal, session, guest, nil, 0,
+		helloFromGuest, channel)
+}
+
+// startSession starts a new session on the terminal, attaches the Guest
+// that will be in control of it and waits for the Host to pick it up.
+func (ts *TerminalServer) startSession(
+	ctx context.Context,
+	logger *zap.Logger,
+	terminal *terminal.Terminal,
+	requestedDimensions *api.TerminalDimensions,
+	command *api.Command,
+	shellOverride *api.ShellOverride,
+) (*session.Session, *session.Guest, error) {
+	// Older Hosts would silently start the default shell instead
+	if command != nil {
+		if err := requireHostCapability(terminal, protocol.CapabilityCommand, "running commands"); err != nil {
+			logger.Warn("host doesn't support running commands")
+			return nil, nil, err
+		}
+	}
+	if shellOverride != nil {
+		if err := requireHostCapability(terminal, protocol.CapabilityShellOverride,
+			"overriding the shell and the working directory"); err != nil {
+			logger.Warn("host doesn't support overriding the shell and the working directory")
+			return nil, nil, err
+		}
 	}
 
-	// Start a new session on this terminal
-	session := session.New(channel.Context(), helloFromGuest.RequestedDimensions)
-	defer session.Close()
+	// Start a new session on this terminal, it's not bound to the Guest's
+	// connection lifetime, so that the Guest has a chance to resume it after
+	// a disconnect
+	session := session.New(context.WithoutCancel(ctx), requestedDimensions,
+		session.WithOutputBufferSize(ts.outputBufferSize), session.WithCommand(command),
+		session.WithShellOverride(shellOverride),
+		session.WithFlowControlWindow(uint64(max(ts.flowControlWindow, 0))),
+		session.WithGuestBufferSize(ts.guestBufferSize), session.WithSlowGuestPolicy(ts.slowGuestPolicy),
+		session.WithObserverSlowGuestPolicy(ts.observerSlowGuestPolicy))
 
 	logger = logger.With(HashedTokenField(session.Token()))
 
 	if err := terminal.RegisterSession(session); err != nil {
 		logger.Warn("failed to register a new session", zap.Error(err))
-		return err
+		_ = session.Close()
+		return nil, nil, refusedSessionError(err)
 	}
-	defer terminal.UnregisterSession(session)
+	go func() {
+		startedAt := time.Now()
+
+		<-session.Context().Done()
+		terminal.UnregisterSession(session)
+		ts.metrics.sessionFinished(time.Since(startedAt))
+	}()
+
+	// Attach before the Host picks up the session to not to miss any output
+	guest, _, _ := session.Resume(ctx, false, 0)
 
 	logger.Info("started a new session")
 
 	// Broadcast the created session
 	select {
 	case terminal.NewSessionChan <- session:
-		// OK, proceed with session I/O below
-	case <-channel.Context().Done():
+		// OK, proceed with session I/O
+		return session, guest, nil
+	case <-ctx.Done():
 		// Connection with the Guest was terminated before the Host had a chance to pick up our session
 		logger.Warn("connection with the guest terminated before the host had a chance to pick up the session")
-		return nil
+		_ = session.Close()
+		return nil, nil, ctx.Err()
+	case <-session.Context().Done():
+		// Terminal was closed while waiting for the Host to reconnect
+		logger.Warn("terminal was closed before the host had a chance to pick up the session")
+		return nil, nil, status.Errorf(codes.Aborted, "lost connection with the terminal host")
+	}
+}
+
+// serveGuest tells the Guest about the session it's attached to, replays
+// the missed terminal output (if any) and proxies the session I/O, taking
+// into account the compression and the capabilities negotiated with the Guest.
+func (ts *TerminalServer) serveGuest(
+	logger *zap.Logger,
+	terminal *terminal.Terminal,
+	session *session.Session,
+	guest *session.Guest,
+	replay []byte,
+	replayOffset uint64,
+	helloFromGuest *api.GuestTerminalRequest_Hello,
+	channel api.GuestService_TerminalChannelServer,
+) error {
+	ts.guestsWG.Add(1)
+	defer ts.guestsWG.Done()
+
+	// Whether there's no point in keeping the session around after the Guest leaves
+	var finished bool
+
+	defer func() {
+		session.Detach(guest)
+
+		if !guest.ReadOnly() {
+			ts.releaseSession(logger, session, guest, finished)
+		}
+	}()
+
+	outputCompression := compression.Negotiate(helloFromGuest.AcceptedCompressions)
+	guestCapabilities := negotiateCapabilities(helloFromGuest.Capabilities)
+
+	helloToGuest := &api.GuestTerminalResponse_Hello{
+		SessionId:        session.ID(),
+		ReadOnly:         guest.ReadOnly(),
+		OutputOffset:     replayOffset,
+		Compression:      outputCompression,
+		ProtocolVersion:  protocol.Version,
+		Capabilities:     guestCapabilities,
+		HostCapabilities: terminal.HostCapabilities(),
+	}
+	if !guest.ReadOnly() {
+		helloToGuest.ResumeToken = session.ResumeToken()
+	}
+	if ts.recordingStorage != nil {
+		helloToGuest.RecordingId = hashed(session.Token())
+	}
+
+	if err := channel.Send(&api.GuestTerminalResponse{
+		Operation: &api.GuestTerminalResponse_Hello_{
+			Hello: helloToGuest,
+		},
+	}); err != nil {
+		logger.Warn("failed to send a Hello message to the guest", zap.Error(err))
+		ts.metrics.sendFailed(channelTerminal)
+		return err
+	}
+
+	for len(replay) != 0 {
+		chunk := replay[:min(len(replay), replayChunkSize)]
+		replay = replay[len(chunk):]
+
+		if err := channel.Send(&api.GuestTerminalResponse{
+			Operation: &api.GuestTerminalResponse_Output{
+				Output: ts.compressOutput(chunk, outputCompression),
+			},
+		}); err != nil {
+			logger.Warn("failed to replay the terminal output to the guest", zap.Error(err))
+			ts.metrics.sendFailed(channelTerminal)
+			return err
+		}
 	}
 
 	// A way to terminate channel if we receive at least one error from one of the two Goroutines below
 	const numGoroutines = 2
 	errChan := make(chan error, numGoroutines)
 
-	go fromHost(logger, session, channel, errChan)
-	go fromGuest(logger, session, channel, errChan)
+	// Lets fromGuest() tell the read-only Guest that its input is ignored through fromHost(),
+	// since only one of them can send on the channel
+	inputIgnoredChan := make(chan struct{}, 1)
+
+	go ts.fromHost(logger, session, guest, outputCompression,
+		slices.Contains(guestCapabilities, protocol.CapabilityOutputTruncated), channel, inputIgnoredChan, errChan)
+	go fromGuest(logger, session, guest, channel, inputIgnoredChan, errChan)
+
+	err := <-errChan
+
+	// Either the Guest has explicitly closed the channel or
+	// the session has ended, no need to keep the session around
+	if errors.Is(err, io.EOF) || errors.Is(err, errSessionTerminated) {
+		finished = true
+
+		return nil
+	}
 
-	return <-errChan
+	return err
 }
 
 // fromHost processes terminal output from the Host.
-func fromHost(
+func (ts *TerminalServer) fromHost(
 	logger *zap.Logger,
 	session *session.Session,
+	guest *session.Guest,
+	outputCompression api.Compression,
+	outputTruncated bool,
 	channel api.GuestService_TerminalChannelServer,
+	inputIgnoredChan <-chan struct{},
 	errChan chan error,
 ) {
 	for {
 		select {
-		case chunk := <-session.TerminalOutputChan:
+		case <-inputIgnoredChan:
 			if err := channel.Send(&api.GuestTerminalResponse{
-				Operation: &api.GuestTerminalResponse_Output{
-					Output: &api.Data{
-						Data: chunk,
+				Operation: &api.GuestTerminalResponse_InputIgnored{
+					InputIgnored: &api.InputIgnored{
+						Reason: "read-only guests can only observe the session, their input is ignored",
 					},
 				},
 			}); err != nil {
+				logger.Warn("failed to tell the read-only guest that its input is ignored", zap.Error(err))
+				ts.metrics.sendFailed(channelTerminal)
+				errChan <- err
+				return
+			}
+		case message := <-guest.OutputChan:
+			// The messages are shared between the Guests, so they're compressed into a copy
+			switch op := message.Operation.(type) {
+			case *api.GuestTerminalResponse_Output:
+				message = &api.GuestTerminalResponse{
+					Operation: &api.GuestTerminalResponse_Output{
+						Output: ts.compressOutput(op.Output.Data, outputCompression),
+					},
+				}
+			case *api.GuestTerminalResponse_ErrorOutput:
+				message = &api.GuestTerminalResponse{
+					Operation: &api.GuestTerminalResponse_ErrorOutput{
+						ErrorOutput: ts.compressOutput(op.ErrorOutput.Data, outputCompression),
+					},
+				}
+			case *api.GuestTerminalResponse_OutputTruncated:
+				// Older Guests don't know about the dropped output
+				if !outputTruncated {
+					continue
+				}
+			}
+
+			if err := channel.Send(message); err != nil {
 				logger.Warn("failed to send the host's terminal output to the guest", zap.Error(err))
+				ts.metrics.sendFailed(channelTerminal)
 				errChan <- err
 				return
 			}
-		case <-channel.Context().Done():
+
+			if message.GetTermination() != nil {
+				errChan <- errSessionTerminated
+				return
+			}
+		case <-guest.Context().Done():
+			if channel.Context().Err() == nil {
+				logger.Warn("guest was superseded by a guest that has resumed the session")
+				errChan <- status.Errorf(codes.Aborted, "session was resumed by another guest")
+				return
+			}
+
 			logger.Warn("channel was closed by the guest", zap.Error(channel.Context().Err()))
 			errChan <- nil
 			return
 		case <-session.Context().Done():
-			logger.Warn("lost connection with the terminal host")
-			errChan <- status.Errorf(codes.Aborted, "lost connection with the terminal host")
+			termination := sessionTermination(session)
+
+			logger.Info("session was terminated", zap.Stringer("reason", termination.Reason))
+
+			if err := channel.Send(&api.GuestTerminalResponse{
+				Operation: &api.GuestTerminalResponse_Termination{
+					Termination: termination,
+				},
+			}); err != nil {
+				logger.Warn("failed to tell the guest about the session termination", zap.Error(err))
+				ts.metrics.sendFailed(channelTerminal)
+				errChan <- err
+				return
+			}
+
+			errChan <- errSessionTerminated
 			return
 		}
 	}
 }
 
+// compressOutput compresses the output sent to the Guest with the negotiated compression, if any.
+func (ts *TerminalServer) compressOutput(data []byte, outputCompression api.Compression) *api.Data {
+	if outputCompression == api.Compression_COMPRESSION_NONE {
+		return &api.Data{Data: data}
+	}
+
+	compressed := compression.Compress(outputCompression, data)
+
+	ts.guestCompressionStats.Record(len(data), len(compressed.Data))
+
+	return compressed
+}
+
+// sessionTermination returns the reason for the session's termination
+// suitable for reporting it to the Guest.
+func sessionTermination(session *session.Session) *api.Termination {
+	if termination := session.Termination(); termination != nil {
+		return termination
+	}
+
+	// Session was closed because the Guest in control of it has left
+	return &api.Termination{
+		Reason: api.Termination_REASON_UNSPECIFIED,
+		Error: &api.Error{
+			Message: "session was closed",
+		},
+	}
+}
+
 // fromGuest processes terminal input and other commands from the Guest.
 func fromGuest(
 	logger *zap.Logger,
 	session *session.Session,
+	guest *session.Guest,
 	channel api.GuestService_TerminalChannelServer,
+	inputIgnoredChan chan<- struct{},
 	errChan chan error,
 ) {
+	var inputIgnoredReported bool
+
 	for {
 		requestFromGuest, err := channel.Recv()
 		if err != nil {
-			logger.Warn("failed to receive terminal input/commands from the guest", zap.Error(err))
+			if !errors.Is(err, io.EOF) {
+				logger.Warn("failed to receive terminal input/commands from the guest", zap.Error(err))
+			}
 			errChan <- err
 			return
 		}
 
+		// Read-only Guests can only observe the terminal output
+		if guest.ReadOnly() {
+			logger.Debug("ignoring terminal input/commands from a read-only guest")
+
+			// Let the Guest know once, instead of silently ignoring its every keystroke
+			if !inputIgnoredReported {
+				inputIgnoredChan <- struct{}{}
+				inputIgnoredReported = true
+			}
+
+			continue
+		}
+
 		switch msg := requestFromGuest.Operation.(type) {
 		case *api.GuestTerminalRequest_ChangeDimensions:
 			select {
 			case session.ChangeDimensionsChan <- msg.ChangeDimensions:
 				continue
-			case <-channel.Context().Done():
-				logger.Warn("channel was closed by the guest", zap.Error(channel.Context().Err()))
+			case <-guest.Context().Done():
+				logger.Warn("channel was closed by the guest", zap.Error(guest.Context().Err()))
+				errChan <- nil
+				return
+			case <-session.Context().Done():
+				// fromHost() will tell the Guest why the session has ended
+				return
+			}
+		case *api.GuestTerminalRequest_CloseInput:
+			select {
+			case session.CloseInputChan <- struct{}{}:
+				continue
+			case <-guest.Context().Done():
+				logger.Warn("channel was closed by the guest", zap.Error(guest.Context().Err()))
 				errChan <- nil
 				return
 			case <-session.Context().Done():
-				logger.Warn("lost connection with the terminal host")
-				errChan <- status.Errorf(codes.Aborted, "lost connection with the terminal host")
+				// fromHost() will tell the Guest why the session has ended
 				return
 			}
 		case *api.GuestTerminalRequest_Input:
 			select {
 			case session.TerminalInputChan <- msg.Input.Data:
 				continue
-			case <-channel.Context().Done():
-				logger.Warn("channel was closed by the guest", zap.Error(channel.Context().Err()))
+			case <-guest.Context().Done():
+				logger.Warn("channel was closed by the guest", zap.Error(guest.Context().Err()))
 				errChan <- nil
 				return
 			case <-session.Context().Done():
-				logger.Warn("lost connection with the terminal host")
-				errChan <- status.Errorf(codes.Aborted, "lost connection with the terminal host")
+				// fromHost() will tell the Guest why the session has ended
 				return
 			}
 		default:
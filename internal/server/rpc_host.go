@@ -2,7 +2,8 @@ package server
 
 import (
 	"github.com/cirruslabs/terminal/internal/api"
-	"github.com/cirruslabs/terminal/internal/server/terminal"
+	"github.com/cirruslabs/terminal/internal/compression"
+	"github.com/cirruslabs/terminal/internal/protocol"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -15,37 +16,73 @@ func (ts *TerminalServer) ControlChannel(channel api.HostService_ControlChannelS
 	requestFromHost, err := channel.Recv()
 	if err != nil {
 		logger.Warn("failed to receive a Hello message", zap.Error(err))
-		return err
+		return ts.metrics.helloFailed(channelControl, err)
 	}
 	helloFromHost := requestFromHost.GetHello()
 	if helloFromHost == nil {
 		logger.Warn("expected a Hello message, got something else")
-		return status.Errorf(codes.FailedPrecondition, "expected a Hello message")
+		return ts.metrics.helloFailed(channelControl,
+			status.Errorf(codes.FailedPrecondition, "expected a Hello message"))
 	}
 
-	// Create and register a new terminal associated with this Host
-	terminal := terminal.New(ts.generateLocator(), terminal.WithTrustedSecret(helloFromHost.TrustedSecret))
-	defer terminal.Close()
+	if err := ts.checkProtocolVersion("host", helloFromHost.ProtocolVersion); err != nil {
+		logger.Warn("refusing the host", zap.Uint32("protocol-version", helloFromHost.ProtocolVersion))
+		return ts.metrics.helloFailed(channelControl, err)
+	}
 
-	logger = logger.With(LocatorField(terminal.Locator()))
+	if ts.IsDraining() {
+		logger.Info("refusing the host since the server is draining")
+		return ts.metrics.helloFailed(channelControl,
+			status.Errorf(codes.Unavailable, "terminal server is draining, please re-connect to another one"))
+	}
 
-	if err := ts.registerTerminal(terminal); err != nil {
+	// Authenticate the Host by its client certificate, if required. The data channels
+	// don't need this, since they're bound to the tokens issued on this channel.
+	hostCertSubject, err := ts.hostCertSubject(channel.Context())
+	if err != nil {
+		logger.Warn("refusing the host without a trusted client certificate")
+		return ts.metrics.helloFailed(channelControl, err)
+	}
+	if hostCertSubject != "" {
+		logger = logger.With(HostCertSubjectField(hostCertSubject))
+	}
+
+	// Re-claim the terminal reserved for this Host if it's reconnecting,
+	// otherwise create and register a new terminal associated with this Host
+	terminal, attachCtx, generation, err := ts.acquireTerminal(channel.Context(), helloFromHost, hostCertSubject)
+	if err != nil {
 		logger.Warn("failed to register terminal", zap.Error(err))
-		return err
+		return ts.metrics.helloFailed(channelControl, err)
 	}
-	defer ts.unregisterTerminal(terminal)
 
-	logger.Info("registered new terminal")
+	logger = logger.With(LocatorField(terminal.Locator()), zap.Uint32("protocol-version", helloFromHost.ProtocolVersion),
+		zap.Strings("capabilities", terminal.HostCapabilities()))
+
+	defer ts.releaseTerminal(logger, terminal, generation)
+
+	switch helloFromHost.Locator {
+	case "":
+		logger.Info("registered new terminal")
+	case terminal.Locator():
+		logger.Info("host has re-claimed its terminal")
+	default:
+		logger.Warn("host failed to re-claim its terminal, registered a new one instead",
+			zap.String("requested-locator", helloFromHost.Locator))
+	}
 
 	// Tell the Host it's locator
 	if err := channel.Send(&api.HostControlResponse{
 		Operation: &api.HostControlResponse_Hello_{
 			Hello: &api.HostControlResponse_Hello{
-				Locator: terminal.Locator(),
+				Locator:         terminal.Locator(),
+				LocatorProof:    terminal.LocatorProof(),
+				ProtocolVersion: protocol.Version,
+				Capabilities:    terminal.HostCapabilities(),
 			},
 		},
 	}); err != nil {
 		logger.Warn("failed tell the host it's locator", zap.Error(err))
+		ts.metrics.sendFailed(channelControl)
 		return err
 	}
 
@@ -59,18 +96,87 @@ func (ts *TerminalServer) ControlChannel(channel api.HostService_ControlChannelS
 			if err := channel.Send(&api.HostControlResponse{
 				Operation: &api.HostControlResponse_DataChannelRequest_{
 					DataChannelRequest: &api.HostControlResponse_DataChannelRequest{
-						Token:               session.Token(),
-						RequestedDimensions: session.RequestedDimensions(),
+						Token:                 session.Token(),
+						RequestedDimensions:   session.RequestedDimensions(),
+						Command:               session.Command(),
+						ShellOverride:         session.ShellOverride(),
+						FlowControlWindow:     session.FlowControlWindow(),
+						SupportedCompressions: compression.Supported,
 					},
 				},
 			}); err != nil {
 				logger.Warn("failed to tell the host about the new session")
+				ts.metrics.sendFailed(channelControl)
 				return err
 			}
 
 			logger.Info("spawned new session")
-		case <-channel.Context().Done():
-			// The Host has left and there's nothing we can do about it except close and unregister it's terminal
+		case tunnel := <-terminal.NewTunnelChan:
+			// There's a new tunnel requested by the Guest, tell
+			// the Host to connect to the port and open a new tunnel data channel
+			if err := channel.Send(&api.HostControlResponse{
+				Operation: &api.HostControlResponse_TunnelRequest_{
+					TunnelRequest: &api.HostControlResponse_TunnelRequest{
+						Token:     tunnel.Token(),
+						Port:      tunnel.Port(),
+						Subsystem: tunnel.Subsystem(),
+					},
+				},
+			}); err != nil {
+				logger.Warn("failed to tell the host about the new tunnel")
+				ts.metrics.sendFailed(channelControl)
+				return err
+			}
+
+			logger.Info("requested new tunnel", HashedTokenField(tunnel.Token()), zap.Uint32("port", tunnel.Port()),
+				zap.String("subsystem", tunnel.Subsystem()))
+		case fileTransfer := <-terminal.NewFileTransferChan:
+			// There's a new file transfer requested by the Guest, tell
+			// the Host to open a new file transfer data channel
+			if err := channel.Send(&api.HostControlResponse{
+				Operation: &api.HostControlResponse_FileTransferRequest_{
+					FileTransferRequest: &api.HostControlResponse_FileTransferRequest{
+						Token:        fileTransfer.Token(),
+						FileTransfer: fileTransfer.Request(),
+					},
+				},
+			}); err != nil {
+				logger.Warn("failed to tell the host about the new file transfer")
+				ts.metrics.sendFailed(channelControl)
+				return err
+			}
+
+			logger.Info("requested new file transfer", HashedTokenField(fileTransfer.Token()),
+				zap.Stringer("direction", fileTransfer.Request().Direction),
+				zap.String("path", fileTransfer.Request().Path))
+		case <-ts.drainingChan:
+			// The server is about to shut down, ask the Host to re-connect to another
+			// server, while its sessions keep running here until they finish
+			if terminal.HasHostCapability(protocol.CapabilityDrain) {
+				if err := channel.Send(&api.HostControlResponse{
+					Operation: &api.HostControlResponse_Drain_{
+						Drain: &api.HostControlResponse_Drain{
+							Reason: "terminal server is shutting down",
+						},
+					},
+				}); err != nil {
+					logger.Warn("failed to tell the host about the drain", zap.Error(err))
+					ts.metrics.sendFailed(channelControl)
+					return err
+				}
+			}
+
+			// The older Hosts re-connect when the control channel fails
+			logger.Info("asked the host to re-connect since the server is draining")
+			return status.Errorf(codes.Unavailable, "terminal server is draining, please re-connect to another one")
+		case <-attachCtx.Done():
+			if channel.Context().Err() == nil {
+				// The Host has reconnected using a new control channel, which took over this terminal
+				logger.Info("host's control channel was superseded by a new one")
+				return status.Errorf(codes.Aborted, "control channel was superseded by a new one")
+			}
+
+			// The Host has left, reserve its terminal for a while in case it reconnects
 			logger.Info("host has disconnected", zap.Error(channel.Context().Err()))
 			return nil
 		}
@@ -85,31 +191,66 @@ func (ts *TerminalServer) DataChannel(channel api.HostService_DataChannelServer)
 	requestFromHost, err := channel.Recv()
 	if err != nil {
 		logger.Warn("failed to receive a Hello message", zap.Error(err))
-		return err
+		return ts.metrics.helloFailed(channelData, err)
 	}
 	helloFromHost := requestFromHost.GetHello()
 	if helloFromHost == nil {
 		logger.Warn("expected a Hello message, got something else")
-		return status.Errorf(codes.FailedPrecondition, "expected a Hello message")
+		return ts.metrics.helloFailed(channelData,
+			status.Errorf(codes.FailedPrecondition, "expected a Hello message"))
 	}
 
 	logger = logger.With(LocatorField(helloFromHost.Locator), HashedTokenField(helloFromHost.Token))
 
 	terminal := ts.findTerminal(helloFromHost.Locator)
 	if terminal == nil {
+		// The Host's control channel might be connected to another replica of the server
+		if peerConn := ts.findOwner(channel.Context(), logger, helloFromHost.Locator); peerConn != nil {
+			logger.Info("proxying the data channel to the replica owning the terminal",
+				zap.String("peer", peerConn.Target()))
+
+			upstream, err := api.NewHostServiceClient(peerConn).DataChannel(ts.peerContext(channel.Context()))
+			if err != nil {
+				return err
+			}
+
+			return proxyChannel[api.HostDataRequest, api.HostDataResponse](requestFromHost, channel, upstream)
+		}
+
 		logger.Warn("terminal with the specified locator not found")
-		return status.Errorf(codes.NotFound, "terminal with locator %q not found", helloFromHost.Locator)
+		return ts.metrics.helloFailed(channelData,
+			status.Errorf(codes.NotFound, "terminal with locator %q not found", helloFromHost.Locator))
 	}
 
 	session := terminal.FindSession(helloFromHost.Token)
 	if session == nil {
 		logger.Warn("terminal has no active sessions with the specified token")
-		return status.Errorf(codes.NotFound, "terminal %q has no active sessions with the specified token",
-			terminal.Locator())
+		return ts.metrics.helloFailed(channelData, status.Errorf(codes.NotFound,
+			"terminal %q has no active sessions with the specified token", terminal.Locator()))
 	}
 
 	logger.Info("established new terminal session")
 
+	sessionRecording := ts.startRecording(logger, terminal, session)
+	defer sessionRecording.Close()
+
+	// Only send the window updates to the Hosts that expect them
+	var outputConsumedChan chan struct{}
+
+	if helloFromHost.FlowControl {
+		outputConsumedChan = session.OutputConsumedChan
+	}
+
+	var channelCompressionStats compression.Stats
+
+	defer func() {
+		if channelCompressionStats.Uncompressed() != 0 {
+			logger.Debug("compressed terminal output from the host",
+				zap.Uint64("uncompressed-bytes", channelCompressionStats.Uncompressed()),
+				zap.Uint64("saved-bytes", channelCompressionStats.Saved()))
+		}
+	}()
+
 	// A way to terminate channel if we receive at least one error from one of the two Goroutines below
 	const numGoroutines = 2
 	errChan := make(chan error, numGoroutines)
@@ -121,6 +262,9 @@ func (ts *TerminalServer) DataChannel(channel api.HostService_DataChannelServer)
 
 			select {
 			case chunk := <-session.TerminalInputChan:
+				sessionRecording.Input(chunk)
+				ts.metrics.relayed(directionGuestToHost, len(chunk))
+
 				responseToHost = &api.HostDataResponse{
 					Operation: &api.HostDataResponse_Input{
 						Input: &api.Data{
@@ -128,7 +272,22 @@ func (ts *TerminalServer) DataChannel(channel api.HostService_DataChannelServer)
 						},
 					},
 				}
+			case <-session.CloseInputChan:
+				responseToHost = &api.HostDataResponse{
+					Operation: &api.HostDataResponse_CloseInput{
+						CloseInput: true,
+					},
+				}
+			case <-outputConsumedChan:
+				// Let the Host send more output
+				responseToHost = &api.HostDataResponse{
+					Operation: &api.HostDataResponse_WindowUpdate{
+						WindowUpdate: session.TakeConsumedOutput(),
+					},
+				}
 			case newDimensions := <-session.ChangeDimensionsChan:
+				sessionRecording.Resize(newDimensions)
+
 				responseToHost = &api.HostDataResponse{
 					Operation: &api.HostDataResponse_ChangeDimensions{
 						ChangeDimensions: newDimensions,
@@ -146,6 +305,7 @@ func (ts *TerminalServer) DataChannel(channel api.HostService_DataChannelServer)
 
 			if err := channel.Send(responseToHost); err != nil {
 				logger.Warn("failed to tell the host about guest's input/commands", zap.Error(err))
+				ts.metrics.sendFailed(channelData)
 				errChan <- err
 				return
 			}
@@ -158,18 +318,64 @@ func (ts *TerminalServer) DataChannel(channel api.HostService_DataChannelServer)
 			requestFromHost, err := channel.Recv()
 			if err != nil {
 				logger.Warn("failed to receive terminal output from the host", zap.Error(err))
+
+				// The Host won't be able to continue this session
+				session.Finish(&api.Termination{
+					Reason: api.Termination_HOST_DISCONNECTED,
+					Error: &api.Error{
+						Message: "lost connection with the terminal host",
+					},
+				})
+
 				errChan <- err
 				return
 			}
-			outputFromHost := requestFromHost.GetOutput()
-			if outputFromHost == nil {
-				logger.Warn("expected a Data message from the host, got something else")
-				errChan <- status.Errorf(codes.FailedPrecondition, "expected a Data message")
+
+			if err := ts.decompressOutput(requestFromHost, &channelCompressionStats); err != nil {
+				logger.Warn("failed to decompress terminal output from the host", zap.Error(err))
+				errChan <- status.Errorf(codes.InvalidArgument, "failed to decompress the output: %v", err)
+				return
+			}
+
+			var responseToGuest *api.GuestTerminalResponse
+
+			switch op := requestFromHost.Operation.(type) {
+			case *api.HostDataRequest_Output:
+				sessionRecording.Output(op.Output)
+				ts.metrics.relayed(directionHostToGuest, len(op.Output.Data))
+
+				responseToGuest = &api.GuestTerminalResponse{
+					Operation: &api.GuestTerminalResponse_Output{
+						Output: op.Output,
+					},
+				}
+			case *api.HostDataRequest_ErrorOutput:
+				sessionRecording.Output(op.ErrorOutput)
+				ts.metrics.relayed(directionHostToGuest, len(op.ErrorOutput.Data))
+
+				responseToGuest = &api.GuestTerminalResponse{
+					Operation: &api.GuestTerminalResponse_ErrorOutput{
+						ErrorOutput: op.ErrorOutput,
+					},
+				}
+			case *api.HostDataRequest_Termination:
+				logger.Info("session was terminated by the host",
+					zap.Stringer("reason", op.Termination.Reason),
+					zap.Int32("exit-code", op.Termination.GetExitStatus().GetCode()),
+					zap.String("signal", op.Termination.GetExitStatus().GetSignal()))
+
+				session.Finish(op.Termination)
+
+				errChan <- nil
+				return
+			default:
+				logger.Warn("expected a Data or a Termination message from the host, got something else")
+				errChan <- status.Errorf(codes.FailedPrecondition, "expected a Data or a Termination message")
 				return
 			}
 
 			select {
-			case session.TerminalOutputChan <- outputFromHost.Data:
+			case session.TerminalOutputChan <- responseToGuest:
 				continue
 			case <-channel.Context().Done():
 				logger.Warn("terminal channel was closed by the host", zap.Error(channel.Context().Err()))
@@ -184,3 +390,34 @@ func (ts *TerminalServer) DataChannel(channel api.HostService_DataChannelServer)
 
 	return <-errChan
 }
+
+// decompressOutput replaces the compressed output from the Host (if any) with
+// its decompressed version, since the Guests negotiate their own compression.
+func (ts *TerminalServer) decompressOutput(requestFromHost *api.HostDataRequest, stats *compression.Stats) error {
+	var data **api.Data
+
+	switch op := requestFromHost.Operation.(type) {
+	case *api.HostDataRequest_Output:
+		data = &op.Output
+	case *api.HostDataRequest_ErrorOutput:
+		data = &op.ErrorOutput
+	default:
+		return nil
+	}
+
+	if (*data).GetCompression() == api.Compression_COMPRESSION_NONE {
+		return nil
+	}
+
+	decompressed, err := compression.Decompress(*data)
+	if err != nil {
+		return err
+	}
+
+	ts.hostCompressionStats.Record(len(decompressed), len((*data).Data))
+	stats.Record(len(decompressed), len((*data).Data))
+
+	*data = &api.Data{Data: decompressed}
+
+	return nil
+}
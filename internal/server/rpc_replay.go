@@ -0,0 +1,178 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/asciicast"
+	"github.com/cirruslabs/terminal/internal/server/storage"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"time"
+)
+
+func (ts *TerminalServer) Replay(request *api.ReplayRequest, stream api.GuestService_ReplayServer) error {
+	logger := ts.logger.With(ts.TraceContext(stream.Context())...).With(LocatorField(request.Locator),
+		zap.String(tokenField, request.RecordingId), HashedSecretField(request.Secret))
+
+	if ts.recordingStorage == nil {
+		return status.Errorf(codes.FailedPrecondition, "session recording is disabled on this server")
+	}
+
+	clientIP := ts.clientIP(stream.Context())
+	if err := ts.admitGuest(clientIP, request.Locator); err != nil {
+		logger.Warn("refusing the guest", zap.String("client-ip", clientIP), zap.Error(err))
+		return err
+	}
+
+	reader, metadata, err := ts.recordingStorage.Open(storage.Key{
+		Locator:     request.Locator,
+		HashedToken: request.RecordingId,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			ts.guestFailed(logger, clientIP, "")
+			logger.Warn("guest requested a recording that does not exist")
+			return status.Errorf(codes.NotFound, "recording not found")
+		case errors.Is(err, storage.ErrInvalidKey):
+			logger.Warn("guest requested a recording using an invalid locator or recording ID")
+			return status.Errorf(codes.InvalidArgument, "invalid locator or recording ID")
+		default:
+			logger.Error("failed to open the recording", zap.Error(err))
+			return status.Errorf(codes.Internal, "failed to open the recording")
+		}
+	}
+	defer reader.Close()
+
+	if !isSecretAllowed(metadata.HashedSecrets, request.Secret) {
+		ts.guestFailed(logger, clientIP, request.Locator)
+		logger.Warn("guest provided an invalid secret for the recording")
+		return status.Errorf(codes.PermissionDenied, "invalid secret")
+	}
+
+	ts.guestSucceeded(clientIP, request.Locator)
+
+	asciicastReader, err := asciicast.NewReader(reader)
+	if err != nil {
+		logger.Error("failed to read the recording", zap.Error(err))
+		return status.Errorf(codes.Internal, "failed to read the recording")
+	}
+
+	// Only the trusted secret gives access to the recorded input
+	replayInput := isSecretAllowed(metadata.HashedInputSecrets, request.Secret)
+
+	logger.Info("replaying the recording", zap.Bool("input", replayInput))
+
+	header := asciicastReader.Header()
+
+	if err := stream.Send(&api.ReplayResponse{
+		Operation: &api.ReplayResponse_ChangeDimensions{
+			ChangeDimensions: &api.TerminalDimensions{
+				WidthColumns: header.WidthColumns,
+				HeightRows:   header.HeightRows,
+			},
+		},
+	}); err != nil {
+		return err
+	}
+
+	speed := request.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+
+	start := time.Now()
+
+	for {
+		event, err := asciicastReader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			logger.Error("failed to read the recording", zap.Error(err))
+			return status.Errorf(codes.Internal, "failed to read the recording")
+		}
+
+		var responseToGuest *api.ReplayResponse
+
+		switch event.Code {
+		case asciicast.EventOutput:
+			responseToGuest = &api.ReplayResponse{
+				Operation: &api.ReplayResponse_Output{
+					Output: &api.Data{
+						Data: []byte(event.Data),
+					},
+				},
+			}
+		case asciicast.EventResize:
+			widthColumns, heightRows, err := event.Resize()
+			if err != nil {
+				logger.Warn("skipping a malformed event in the recording", zap.Error(err))
+
+				continue
+			}
+
+			responseToGuest = &api.ReplayResponse{
+				Operation: &api.ReplayResponse_ChangeDimensions{
+					ChangeDimensions: &api.TerminalDimensions{
+						WidthColumns: widthColumns,
+						HeightRows:   heightRows,
+					},
+				},
+			}
+		case asciicast.EventInput:
+			if !replayInput {
+				continue
+			}
+
+			responseToGuest = &api.ReplayResponse{
+				Operation: &api.ReplayResponse_Input{
+					Input: &api.Data{
+						Data: []byte(event.Data),
+					},
+				},
+			}
+		default:
+			continue
+		}
+
+		// Preserve the original timing
+		delay := time.Until(start.Add(time.Duration(float64(event.Time) / speed)))
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+
+			select {
+			case <-timer.C:
+			case <-stream.Context().Done():
+				timer.Stop()
+
+				return stream.Context().Err()
+			}
+		}
+
+		if err := stream.Send(responseToGuest); err != nil {
+			return err
+		}
+	}
+}
+
+// isSecretAllowed returns true if the secret is one of the allowed ones, which are hashed.
+func isSecretAllowed(allowedHashedSecrets []string, secret string) bool {
+	if secret == "" {
+		return false
+	}
+
+	hashedSecret := []byte(hashed(secret))
+
+	for _, allowedHashedSecret := range allowedHashedSecrets {
+		if subtle.ConstantTimeCompare([]byte(allowedHashedSecret), hashedSecret) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,309 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/protocol"
+	"github.com/cirruslabs/terminal/internal/server/terminal"
+	"github.com/cirruslabs/terminal/internal/server/tunnel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"math"
+	"time"
+)
+
+// How long to wait for the Host to connect to the requested port
+// and to open the tunnel data channel.
+const tunnelEstablishmentTimeout = 30 * time.Second
+
+// Subsystems that the Guests can request instead of a port.
+const subsystemSFTP = "sftp"
+
+func (ts *TerminalServer) TunnelChannel(channel api.GuestService_TunnelChannelServer) error {
+	logger := ts.logger.With(ts.TraceContext(channel.Context())...)
+
+	// Guest begins the tunnel by sending a Hello message
+	// with the credentials of the terminal it wants to talk to
+	requestFromGuest, err := channel.Recv()
+	if err != nil {
+		logger.Warn("failed to receive a Hello message")
+		return err
+	}
+	helloFromGuest := requestFromGuest.GetHello()
+	if helloFromGuest == nil {
+		logger.Warn("expected a Hello message, got something else")
+		return status.Errorf(codes.FailedPrecondition, "expected a Hello message")
+	}
+
+	logger = logger.With(LocatorField(helloFromGuest.Locator), HashedSecretField(helloFromGuest.Secret),
+		zap.Uint32("port", helloFromGuest.Port), zap.String("subsystem", helloFromGuest.Subsystem))
+
+	clientIP := ts.clientIP(channel.Context())
+	if err := ts.admitGuest(clientIP, helloFromGuest.Locator); err != nil {
+		logger.Warn("refusing the guest", zap.String("client-ip", clientIP), zap.Error(err))
+		return err
+	}
+
+	terminal := ts.findTerminal(helloFromGuest.Locator)
+	if terminal == nil {
+		ts.guestFailed(logger, clientIP, "")
+		logger.Warn("terminal with the specified locator is not registered on this server")
+		return status.Errorf(codes.NotFound, "terminal with locator %q is not registered on this server",
+			helloFromGuest.Locator)
+	}
+
+	// Tunnels give access to the Host's network, so only the trusted secret allows them
+	if !terminal.IsSecretValid(helloFromGuest.Secret) {
+		ts.guestFailed(logger, clientIP, terminal.Locator())
+		logger.Warn("guest provided an invalid secret")
+		return status.Errorf(codes.PermissionDenied, "invalid secret")
+	}
+
+	ts.guestSucceeded(clientIP, terminal.Locator())
+
+	tunnel, hostEnd, err := ts.openTunnel(channel.Context(), logger, terminal, helloFromGuest.Port,
+		helloFromGuest.Subsystem)
+	if err != nil {
+		return err
+	}
+	defer tunnel.Close()
+
+	logger = logger.With(HashedTokenField(tunnel.Token()))
+
+	if err := channel.Send(&api.GuestTunnelResponse{
+		Operation: &api.GuestTunnelResponse_Hello_{
+			Hello: &api.GuestTunnelResponse_Hello{},
+		},
+	}); err != nil {
+		logger.Warn("failed to send a Hello message to the guest", zap.Error(err))
+		return err
+	}
+
+	logger.Info("established new tunnel")
+
+	// Each of the Goroutines below proxies its own direction of the tunnel
+	const numGoroutines = 2
+	errChan := make(chan error, numGoroutines)
+
+	go func() {
+		errChan <- tunnelFromGuest(channel, hostEnd.Channel)
+	}()
+
+	go func() {
+		errChan <- tunnelFromHost(channel, hostEnd.Channel)
+	}()
+
+	for i := 0; i < numGoroutines; i++ {
+		if err := <-errChan; err != nil {
+			if channel.Context().Err() == nil {
+				logger.Info("tunnel was closed", zap.Error(err))
+			}
+
+			return err
+		}
+	}
+
+	logger.Info("tunnel was closed")
+
+	return nil
+}
+
+// openTunnel asks the Host to connect to the port on its loopback interface (or to start
+// the subsystem, if specified) and waits for it to open the tunnel data channel. The tunnel
+// lives until it's closed or the ctx is cancelled, the returned errors are gRPC statuses.
+func (ts *TerminalServer) openTunnel(
+	ctx context.Context,
+	logger *zap.Logger,
+	terminal *terminal.Terminal,
+	port uint32,
+	subsystem string,
+) (*tunnel.Tunnel, *tunnel.HostEnd, error) {
+	switch subsystem {
+	case "":
+		if port == 0 || port > math.MaxUint16 {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "invalid port %d", port)
+		}
+
+		// Older Hosts would drop the control channel upon receiving a TunnelRequest
+		if err := requireHostCapability(terminal, protocol.CapabilityTunnel, "tunnels"); err != nil {
+			logger.Warn("host doesn't support tunnels")
+			return nil, nil, err
+		}
+	case subsystemSFTP:
+		if port != 0 {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "port can't be specified for a subsystem")
+		}
+
+		if err := requireHostCapability(terminal, protocol.CapabilitySFTP, "SFTP"); err != nil {
+			logger.Warn("host doesn't support SFTP")
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, status.Errorf(codes.InvalidArgument, "unsupported subsystem %q", subsystem)
+	}
+
+	tunnel := tunnel.New(ctx, port, subsystem)
+
+	logger = logger.With(HashedTokenField(tunnel.Token()))
+
+	if err := terminal.RegisterTunnel(tunnel); err != nil {
+		logger.Warn("failed to register a new tunnel", zap.Error(err))
+		_ = tunnel.Close()
+		return nil, nil, status.Errorf(codes.Unavailable, "%v", err)
+	}
+	go func() {
+		<-tunnel.Context().Done()
+		terminal.UnregisterTunnel(tunnel)
+	}()
+
+	establishCtx, cancel := context.WithTimeout(tunnel.Context(), tunnelEstablishmentTimeout)
+	defer cancel()
+
+	select {
+	case terminal.NewTunnelChan <- tunnel:
+	case <-establishCtx.Done():
+		logger.Warn("host didn't pick up the tunnel in time")
+		_ = tunnel.Close()
+		return nil, nil, status.Errorf(codes.Unavailable, "host didn't pick up the tunnel in time")
+	}
+
+	hostEnd, err := tunnel.WaitForHost(establishCtx)
+	if err != nil {
+		logger.Warn("host didn't open the tunnel data channel in time")
+		_ = tunnel.Close()
+		return nil, nil, status.Errorf(codes.Unavailable, "host didn't open the tunnel data channel in time")
+	}
+
+	if hostEnd.Error != nil {
+		logger.Info("host has failed to connect to the requested port or subsystem",
+			zap.String("error", hostEnd.Error.Message))
+		_ = tunnel.Close()
+
+		if subsystem != "" {
+			return nil, nil, status.Errorf(codes.Unavailable, "host has failed to start subsystem %q: %s",
+				subsystem, hostEnd.Error.Message)
+		}
+
+		return nil, nil, status.Errorf(codes.Unavailable, "host has failed to connect to port %d: %s",
+			port, hostEnd.Error.Message)
+	}
+
+	return tunnel, hostEnd, nil
+}
+
+// tunnelFromGuest proxies the data from the Guest to the Host until the Guest closes its side.
+func tunnelFromGuest(
+	guestChannel api.GuestService_TunnelChannelServer,
+	hostChannel api.HostService_TunnelDataChannelServer,
+) error {
+	for {
+		requestFromGuest, err := guestChannel.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+
+			// Let the Host know that there will be no more data
+			return hostChannel.Send(&api.HostTunnelResponse{
+				Operation: &api.HostTunnelResponse_CloseWrite{
+					CloseWrite: true,
+				},
+			})
+		}
+
+		data := requestFromGuest.GetData()
+		if data == nil {
+			return status.Errorf(codes.FailedPrecondition, "expected a Data message")
+		}
+
+		if err := hostChannel.Send(&api.HostTunnelResponse{
+			Operation: &api.HostTunnelResponse_Data{
+				Data: data,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// tunnelFromHost proxies the data from the Host to the Guest until the Host closes its side.
+func tunnelFromHost(
+	guestChannel api.GuestService_TunnelChannelServer,
+	hostChannel api.HostService_TunnelDataChannelServer,
+) error {
+	for {
+		requestFromHost, err := hostChannel.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+
+			// Let the Guest know that there will be no more data
+			return guestChannel.Send(&api.GuestTunnelResponse{
+				Operation: &api.GuestTunnelResponse_CloseWrite{
+					CloseWrite: true,
+				},
+			})
+		}
+
+		data := requestFromHost.GetData()
+		if data == nil {
+			return status.Errorf(codes.FailedPrecondition, "expected a Data message")
+		}
+
+		if err := guestChannel.Send(&api.GuestTunnelResponse{
+			Operation: &api.GuestTunnelResponse_Data{
+				Data: data,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+func (ts *TerminalServer) TunnelDataChannel(channel api.HostService_TunnelDataChannelServer) error {
+	logger := ts.logger.With(ts.TraceContext(channel.Context())...)
+
+	// Host begins the channel by sending a Hello message
+	// with the token it received from the control channel
+	requestFromHost, err := channel.Recv()
+	if err != nil {
+		logger.Warn("failed to receive a Hello message", zap.Error(err))
+		return err
+	}
+	helloFromHost := requestFromHost.GetHello()
+	if helloFromHost == nil {
+		logger.Warn("expected a Hello message, got something else")
+		return status.Errorf(codes.FailedPrecondition, "expected a Hello message")
+	}
+
+	logger = logger.With(LocatorField(helloFromHost.Locator), HashedTokenField(helloFromHost.Token))
+
+	terminal := ts.findTerminal(helloFromHost.Locator)
+	if terminal == nil {
+		logger.Warn("terminal with the specified locator not found")
+		return status.Errorf(codes.NotFound, "terminal with locator %q not found", helloFromHost.Locator)
+	}
+
+	hostEnd := &tunnel.HostEnd{
+		Channel: channel,
+		Error:   helloFromHost.Error,
+	}
+
+	tunnel := terminal.FindTunnel(helloFromHost.Token)
+	if tunnel == nil {
+		logger.Warn("terminal has no active tunnels with the specified token")
+		return status.Errorf(codes.NotFound, "terminal %q has no active tunnels with the specified token",
+			terminal.Locator())
+	}
+
+	// The Guest's side proxies the data, we just need to keep the channel open until it's done
+	if !tunnel.AttachHost(channel.Context(), hostEnd) {
+		return status.Errorf(codes.Aborted, "tunnel was closed before the host has attached to it")
+	}
+
+	return nil
+}
@@ -2,20 +2,34 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/compression"
+	"github.com/cirruslabs/terminal/internal/server/auth"
+	"github.com/cirruslabs/terminal/internal/server/lockout"
+	"github.com/cirruslabs/terminal/internal/server/registry"
+	"github.com/cirruslabs/terminal/internal/server/session"
+	"github.com/cirruslabs/terminal/internal/server/storage"
 	"github.com/cirruslabs/terminal/internal/server/terminal"
 	"github.com/google/uuid"
 	"github.com/improbable-eng/grpc-web/go/grpcweb"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 	"net"
 	"net/http"
+	"net/http/httputil"
 	"strings"
 	"sync"
 	"time"
@@ -23,7 +37,25 @@ import (
 
 var ErrNewTerminalRefused = errors.New("refusing to register new terminal")
 
-const keepaliveInterval = 1 * time.Minute
+var ErrHostClientCAsRequireTLS = errors.New("host client CAs require TLS to be enabled")
+
+var ErrHostClientCAsRequireHostAddresses = errors.New("host client CAs require the host addresses to listen on")
+
+const (
+	keepaliveInterval         = 1 * time.Minute
+	defaultLocatorGracePeriod = 1 * time.Minute
+	defaultSessionGracePeriod = 30 * time.Second
+	defaultOutputBufferSize   = 64 * 1024
+	defaultFlowControlWindow  = 256 * 1024
+	defaultGuestBufferSize    = 1024 * 1024
+	defaultDrainTimeout       = 20 * time.Second
+	defaultGuestHelloRate     = 50
+	defaultGuestHelloBurst    = 100
+	defaultLockoutThreshold   = 5
+
+	// How long to wait for the Guests to be notified about the server shutdown
+	terminationDeliveryTimeout = 5 * time.Second
+)
 
 type TerminalServer struct {
 	logger *zap.Logger
@@ -31,21 +63,111 @@ type TerminalServer struct {
 	terminalsLock sync.RWMutex
 	terminals     map[string]*terminal.Terminal
 
+	// Records which replica of the server owns each locator, this replica
+	// is reachable by the other ones on the peer address
+	registry    registry.Registry
+	peerAddress string
+	peerSecret  []byte
+
+	// Peer links to the other replicas, keyed by their peer addresses
+	peersLock sync.Mutex
+	peers     map[string]*grpc.ClientConn
+
+	// Tracks the Guests being served to let them know about the server shutdown
+	guestsWG sync.WaitGroup
+
+	// Closed once the server starts draining
+	drainingChan chan struct{}
+	drainOnce    sync.Once
+	drainTimeout time.Duration
+
+	// Tracks the in-flight RPCs to let them finish when the server stops after draining
+	rpcsLock     sync.Mutex
+	rpcsWG       sync.WaitGroup
+	rpcsStopping bool
+
 	addresses []string
 	listeners []net.Listener
 	tlsConfig *tls.Config
 
+	// Separate listeners for the Hosts, so that only the Hosts are asked for the client certificates
+	hostAddresses []string
+	hostListeners []net.Listener
+	hostTLSConfig *tls.Config
+
+	// CAs issuing the client certificates required from the Hosts, if any
+	hostClientCAs *x509.CertPool
+
+	sshAddress  string
+	sshListener net.Listener
+	sshHostKey  ssh.Signer
+
+	metricsAddress  string
+	metricsListener net.Listener
+	metrics         *metrics
+
 	api.UnimplementedGuestServiceServer
 	api.UnimplementedHostServiceServer
 
-	generateLocator LocatorGenerator
+	generateLocator    LocatorGenerator
+	locatorGracePeriod time.Duration
+
+	sessionGracePeriod time.Duration
+	outputBufferSize   int
+	flowControlWindow  int
+	guestBufferSize    int
+	slowGuestPolicy    session.SlowGuestPolicy
+
+	observerSlowGuestPolicy session.SlowGuestPolicy
+
+	minProtocolVersion uint32
+
+	// Validates the alternative credentials presented by the Guests, if any
+	authenticator auth.Authenticator
+
+	// Protect the Guests' authentication from the brute-force attempts
+	guestHelloRate         float64
+	guestHelloBurst        int
+	helloLimiter           *rate.Limiter
+	lockoutThreshold       int
+	clientIPLockout        *lockout.Lockout
+	locatorLockout         *lockout.Lockout
+	clientIPHeader         string
+	trustedProxies         int
+	maxSessionsPerTerminal int
+
+	// How much the output compression has saved on the data
+	// channels from the Hosts and on the channels to the Guests
+	hostCompressionStats  compression.Stats
+	guestCompressionStats compression.Stats
+
+	recordingStorage storage.Storage
+	recordInput      bool
+
+	previewEnabled   bool
+	previewDomain    string
+	previewProxy     *httputil.ReverseProxy
+	previewCookieKey []byte
 
 	gcpProjectID string
 }
 
 func New(opts ...Option) (*TerminalServer, error) {
 	ts := &TerminalServer{
-		terminals: make(map[string]*terminal.Terminal),
+		terminals:          make(map[string]*terminal.Terminal),
+		peers:              make(map[string]*grpc.ClientConn),
+		locatorGracePeriod: defaultLocatorGracePeriod,
+		sessionGracePeriod: defaultSessionGracePeriod,
+		outputBufferSize:   defaultOutputBufferSize,
+		flowControlWindow:  defaultFlowControlWindow,
+		guestBufferSize:    defaultGuestBufferSize,
+		drainingChan:       make(chan struct{}),
+		drainTimeout:       defaultDrainTimeout,
+		guestHelloRate:     defaultGuestHelloRate,
+		guestHelloBurst:    defaultGuestHelloBurst,
+		lockoutThreshold:   defaultLockoutThreshold,
+
+		observerSlowGuestPolicy: session.SlowGuestPolicyDropOldest,
 	}
 
 	// Apply options
@@ -65,6 +187,43 @@ func New(opts ...Option) (*TerminalServer, error) {
 	if len(ts.addresses) == 0 {
 		ts.addresses = []string{"0.0.0.0:0"}
 	}
+	if ts.registry == nil {
+		ts.registry = registry.NewMemory()
+	}
+	if ts.guestHelloRate > 0 {
+		ts.helloLimiter = rate.NewLimiter(rate.Limit(ts.guestHelloRate), max(ts.guestHelloBurst, 1))
+	}
+	if ts.lockoutThreshold > 0 {
+		ts.clientIPLockout = lockout.New(ts.lockoutThreshold, lockoutBaseDuration, lockoutMaxDuration)
+		ts.locatorLockout = lockout.New(ts.lockoutThreshold, lockoutBaseDuration, lockoutMaxDuration)
+	}
+	ts.hostTLSConfig = ts.tlsConfig
+	if ts.hostClientCAs != nil {
+		if ts.tlsConfig == nil {
+			return nil, ErrHostClientCAsRequireTLS
+		}
+		if len(ts.hostAddresses) == 0 {
+			return nil, ErrHostClientCAsRequireHostAddresses
+		}
+
+		// Only verify the client certificates when presented, the Hosts are then
+		// additionally required to present one in ControlChannel, which lets
+		// them know why they were refused instead of failing the handshake
+		ts.hostTLSConfig = ts.tlsConfig.Clone()
+		ts.hostTLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		ts.hostTLSConfig.ClientCAs = ts.hostClientCAs
+	}
+	ts.metrics = newMetrics(ts)
+
+	if ts.previewEnabled {
+		ts.previewProxy = ts.newPreviewProxy()
+
+		// Preview cookies are only valid until the server restarts
+		ts.previewCookieKey = make([]byte, sha256.Size)
+		if _, err := rand.Read(ts.previewCookieKey); err != nil {
+			return nil, err
+		}
+	}
 
 	// Listen
 	for _, address := range ts.addresses {
@@ -76,6 +235,33 @@ func New(opts ...Option) (*TerminalServer, error) {
 		ts.listeners = append(ts.listeners, listener)
 	}
 
+	for _, hostAddress := range ts.hostAddresses {
+		hostListener, err := net.Listen("tcp", hostAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		ts.hostListeners = append(ts.hostListeners, hostListener)
+	}
+
+	if ts.sshAddress != "" {
+		sshListener, err := net.Listen("tcp", ts.sshAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		ts.sshListener = sshListener
+	}
+
+	if ts.metricsAddress != "" {
+		metricsListener, err := net.Listen("tcp", ts.metricsAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		ts.metricsListener = metricsListener
+	}
+
 	return ts, nil
 }
 
@@ -87,8 +273,9 @@ func (ts *TerminalServer) Run(ctx context.Context) error {
 	keepaliveOption := grpc.KeepaliveParams(keepalive.ServerParameters{
 		Time: keepaliveInterval,
 	})
-	grpcServer := grpc.NewServer(keepaliveOption)
+	grpcServer := grpc.NewServer(keepaliveOption, grpc.StreamInterceptor(ts.trackRPC))
 	defer grpcServer.Stop()
+	defer ts.closePeerConns()
 	api.RegisterHostServiceServer(grpcServer, ts)
 	api.RegisterGuestServiceServer(grpcServer, ts)
 
@@ -104,6 +291,15 @@ func (ts *TerminalServer) Run(ctx context.Context) error {
 	grpcHandler := func(w http.ResponseWriter, r *http.Request) {
 		contentType := r.Header.Get("Content-Type")
 		switch {
+		case ts.previewEnabled && ts.previewLocator(r) != "":
+			// Nothing but the previews is served on the preview hosts
+			ts.servePreview(w, r)
+		case r.URL.Path == healthzPath:
+			ts.serveHealthz(w, r)
+		case r.URL.Path == readyzPath:
+			ts.serveReadyz(w, r)
+		case ts.previewEnabled && ts.previewDomain == "" && strings.HasPrefix(r.URL.Path, previewPathPrefix):
+			ts.servePreview(w, r)
 		case strings.ToLower(r.Header.Get("Sec-Websocket-Protocol")) == "grpc-websockets":
 			grpcWebServer.ServeHTTP(w, r)
 		case strings.HasPrefix(contentType, "application/grpc-web"):
@@ -115,11 +311,11 @@ func (ts *TerminalServer) Run(ctx context.Context) error {
 		}
 	}
 
-	startServer := func(listener net.Listener) error {
+	startServer := func(listener net.Listener, tlsConfig *tls.Config) error {
 		server := http.Server{
 			Handler:           http.HandlerFunc(grpcHandler),
 			ReadHeaderTimeout: 5 * time.Second,
-			TLSConfig:         ts.tlsConfig,
+			TLSConfig:         tlsConfig,
 		}
 
 		ts.logger.Sugar().Infof("starting server on %s...", listener.Addr().String())
@@ -137,17 +333,108 @@ func (ts *TerminalServer) Run(ctx context.Context) error {
 		go func() {
 			defer cancel()
 
-			if err := startServer(listener); err != nil {
+			if err := startServer(listener, ts.tlsConfig); err != nil {
 				ts.logger.Sugar().With(zap.Error(err)).Warnf("server failed to start on %s", listener.Addr().String())
 			}
 		}()
 	}
 
-	<-subCtx.Done()
+	for _, hostListener := range ts.hostListeners {
+		go func() {
+			defer cancel()
+
+			if err := startServer(hostListener, ts.hostTLSConfig); err != nil {
+				ts.logger.Sugar().With(zap.Error(err)).Warnf("server failed to start on %s",
+					hostListener.Addr().String())
+			}
+		}()
+	}
+
+	if ts.sshListener != nil {
+		sshServerConfig, err := ts.newSSHServerConfig()
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			defer cancel()
+
+			ts.logger.Sugar().Infof("starting SSH server on %s...", ts.sshListener.Addr().String())
+
+			if err := ts.serveSSH(subCtx, ts.sshListener, sshServerConfig); err != nil {
+				ts.logger.Sugar().With(zap.Error(err)).Warnf("SSH server failed on %s",
+					ts.sshListener.Addr().String())
+			}
+		}()
+
+		go func() {
+			<-subCtx.Done()
+			_ = ts.sshListener.Close()
+		}()
+	}
+
+	go ts.refreshRegistry(subCtx)
+
+	if ts.metricsListener != nil {
+		go func() {
+			defer cancel()
+
+			ts.logger.Sugar().Infof("starting metrics server on %s...", ts.metricsListener.Addr().String())
+
+			if err := ts.metrics.serve(subCtx, ts.metricsListener); err != nil {
+				ts.logger.Sugar().With(zap.Error(err)).Warnf("metrics server failed on %s",
+					ts.metricsListener.Addr().String())
+			}
+		}()
+	}
+
+	var drained bool
+
+	select {
+	case <-subCtx.Done():
+	case <-ts.drainingChan:
+		// Let the sessions finish on their own
+		ts.waitForSessions(subCtx)
+		drained = true
+	}
+
+	ts.terminateAll(&api.Termination{
+		Reason: api.Termination_SERVER_SHUTDOWN,
+		Error: &api.Error{
+			Message: "terminal server is shutting down",
+		},
+	})
+
+	if drained {
+		ts.waitForRPCs()
+	}
 
 	return nil
 }
 
+// terminateAll terminates sessions on all terminals and gives
+// the Guests some time to learn the reason for the termination.
+func (ts *TerminalServer) terminateAll(termination *api.Termination) {
+	ts.terminalsLock.RLock()
+	for _, terminal := range ts.terminals {
+		terminal.Terminate(termination)
+	}
+	ts.terminalsLock.RUnlock()
+
+	guestsDone := make(chan struct{})
+
+	go func() {
+		ts.guestsWG.Wait()
+		close(guestsDone)
+	}()
+
+	select {
+	case <-guestsDone:
+	case <-time.After(terminationDeliveryTimeout):
+		ts.logger.Warn("timed out waiting for the guests to be notified about the server shutdown")
+	}
+}
+
 func (ts *TerminalServer) Addresses() []string {
 	var result []string
 
@@ -158,7 +445,43 @@ func (ts *TerminalServer) Addresses() []string {
 	return result
 }
 
-func (ts *TerminalServer) registerTerminal(terminal *terminal.Terminal) error {
+// HostAddresses returns the addresses of the separate listeners for the Hosts, if any.
+func (ts *TerminalServer) HostAddresses() []string {
+	var result []string
+
+	for _, hostListener := range ts.hostListeners {
+		result = append(result, hostListener.Addr().String())
+	}
+
+	return result
+}
+
+// SSHAddress returns the address of the SSH gateway,
+// or an empty string if the SSH gateway is disabled.
+func (ts *TerminalServer) SSHAddress() string {
+	if ts.sshListener == nil {
+		return ""
+	}
+
+	return ts.sshListener.Addr().String()
+}
+
+// MetricsAddress returns the address of the metrics server,
+// or an empty string if the metrics server is disabled.
+func (ts *TerminalServer) MetricsAddress() string {
+	if ts.metricsListener == nil {
+		return ""
+	}
+
+	return ts.metricsListener.Addr().String()
+}
+
+func (ts *TerminalServer) registerTerminal(ctx context.Context, terminal *terminal.Terminal) error {
+	// Let the other replicas know where to find this terminal
+	if err := ts.registerLocator(ctx, terminal.Locator()); err != nil {
+		return status.Errorf(codes.Unavailable, "failed to register the locator: %v", err)
+	}
+
 	ts.terminalsLock.Lock()
 	defer ts.terminalsLock.Unlock()
 
@@ -171,6 +494,119 @@ func (ts *TerminalServer) registerTerminal(terminal *terminal.Terminal) error {
 	return nil
 }
 
+// acquireTerminal re-claims the terminal reserved for the reconnecting Host
+// if it has proven the locator ownership and presents the same secrets as before,
+// otherwise a new terminal is created and registered. In both cases, the terminal
+// is attached to the Host's control channel identified by the ctx.
+func (ts *TerminalServer) acquireTerminal(
+	ctx context.Context,
+	hello *api.HostControlRequest_Hello,
+	hostCertSubject string,
+) (*terminal.Terminal, context.Context, uint64, error) {
+	if hello.Locator != "" {
+		// Prevent the terminal from being expired while we're re-claiming it
+		ts.terminalsLock.RLock()
+		reservedTerminal, ok := ts.terminals[hello.Locator]
+		// The secrets are only set when the terminal is created, so a Host
+		// with different secrets gets a new terminal instead of having
+		// its secrets silently ignored
+		if ok && reservedTerminal.IsLocatorProofValid(hello.LocatorProof) &&
+			reservedTerminal.HostCertSubject() == hostCertSubject &&
+			reservedTerminal.HasSecrets(hello.TrustedSecret, hello.ReadOnlySecret) {
+			attachCtx, generation := reservedTerminal.Attach(ctx, negotiateCapabilities(hello.Capabilities))
+			ts.terminalsLock.RUnlock()
+
+			return reservedTerminal, attachCtx, generation, nil
+		}
+		ts.terminalsLock.RUnlock()
+	}
+
+	newTerminal := terminal.New(ts.generateLocator(), terminal.WithTrustedSecret(hello.TrustedSecret),
+		terminal.WithReadOnlySecret(hello.ReadOnlySecret), terminal.WithLocatorProof(uuid.New().String()),
+		terminal.WithMaxSessions(ts.maxSessionsPerTerminal), terminal.WithHostCertSubject(hostCertSubject))
+
+	if err := ts.registerTerminal(ctx, newTerminal); err != nil {
+		return nil, nil, 0, err
+	}
+
+	attachCtx, generation := newTerminal.Attach(ctx, negotiateCapabilities(hello.Capabilities))
+
+	return newTerminal, attachCtx, generation, nil
+}
+
+// releaseTerminal detaches the terminal from the Host's control channel
+// and reserves it for the locator grace period, after which the terminal
+// is closed and unregistered, unless the Host re-claims it.
+func (ts *TerminalServer) releaseTerminal(logger *zap.Logger, terminal *terminal.Terminal, generation uint64) {
+	if !terminal.Detach(generation) {
+		// Another control channel has already taken over
+		return
+	}
+
+	if ts.IsDraining() {
+		// Keep the terminal for its remaining sessions until the server stops,
+		// the Host has been asked to re-connect to another server anyway
+		return
+	}
+
+	if ts.locatorGracePeriod == 0 {
+		ts.expireTerminal(terminal, generation)
+
+		return
+	}
+
+	time.AfterFunc(ts.locatorGracePeriod, func() {
+		if ts.expireTerminal(terminal, generation) {
+			logger.Info("terminal reservation has expired")
+		}
+	})
+}
+
+func (ts *TerminalServer) expireTerminal(terminal *terminal.Terminal, generation uint64) bool {
+	ts.terminalsLock.Lock()
+	defer ts.terminalsLock.Unlock()
+
+	if !terminal.IsDetached(generation) {
+		return false
+	}
+
+	delete(ts.terminals, terminal.Locator())
+	go ts.unregisterLocator(terminal.Locator())
+
+	if err := terminal.Close(); err != nil {
+		ts.logger.Warn("failed to close terminal", LocatorField(terminal.Locator()), zap.Error(err))
+	}
+
+	return true
+}
+
+// releaseSession keeps the session abandoned by the Guest in control of it
+// alive for the session grace period, so that the Guest has a chance to resume it.
+func (ts *TerminalServer) releaseSession(
+	logger *zap.Logger,
+	session *session.Session,
+	guest *session.Guest,
+	finished bool,
+) {
+	if !session.IsAbandonedBy(guest) {
+		// Another Guest has already taken over
+		return
+	}
+
+	if finished || ts.sessionGracePeriod == 0 {
+		_ = session.Close()
+
+		return
+	}
+
+	time.AfterFunc(ts.sessionGracePeriod, func() {
+		if session.IsAbandonedBy(guest) {
+			logger.Info("session was not resumed in time, closing it")
+			_ = session.Close()
+		}
+	})
+}
+
 func (ts *TerminalServer) findTerminal(locator string) *terminal.Terminal {
 	ts.terminalsLock.RLock()
 	defer ts.terminalsLock.RUnlock()
@@ -183,4 +619,5 @@ func (ts *TerminalServer) unregisterTerminal(terminal *terminal.Terminal) {
 	defer ts.terminalsLock.Unlock()
 
 	delete(ts.terminals, terminal.Locator())
+	go ts.unregisterLocator(terminal.Locator())
 }
@@ -2,9 +2,19 @@
 package server
 
 import (
+	"context"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/protocol"
 	"github.com/cirruslabs/terminal/internal/server/terminal"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
 	"testing"
+	"time"
 )
 
 func TestTerminalRegistrationUnregistration(t *testing.T) {
@@ -15,7 +25,7 @@ func TestTerminalRegistrationUnregistration(t *testing.T) {
 
 	terminal := terminal.New("doesn't matter")
 
-	require.NoError(t, terminalServer.registerTerminal(terminal))
+	require.NoError(t, terminalServer.registerTerminal(context.Background(), terminal))
 	require.NotNil(t, terminalServer.findTerminal(terminal.Locator()))
 
 	terminalServer.unregisterTerminal(terminal)
@@ -30,6 +40,212 @@ func TestNoDuplicateRegistrations(t *testing.T) {
 
 	terminal := terminal.New("doesn't matter")
 
-	require.NoError(t, terminalServer.registerTerminal(terminal))
-	require.Error(t, terminalServer.registerTerminal(terminal))
+	require.NoError(t, terminalServer.registerTerminal(context.Background(), terminal))
+	require.Error(t, terminalServer.registerTerminal(context.Background(), terminal))
+}
+
+func TestLocatorCanBeReclaimed(t *testing.T) {
+	terminalServer, err := New(WithLocatorGracePeriod(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Register a new terminal
+	first, _, generation, err := terminalServer.acquireTerminal(context.Background(), &api.HostControlRequest_Hello{
+		TrustedSecret: "doesn't matter",
+	}, "")
+	require.NoError(t, err)
+
+	// Host disconnects, but the terminal is still reserved
+	terminalServer.releaseTerminal(zap.NewNop(), first, generation)
+	require.Equal(t, first, terminalServer.findTerminal(first.Locator()))
+
+	// Re-claiming with an invalid proof results in a new terminal
+	second, _, _, err := terminalServer.acquireTerminal(context.Background(), &api.HostControlRequest_Hello{
+		TrustedSecret: "doesn't matter",
+		Locator:       first.Locator(),
+		LocatorProof:  "invalid proof",
+	}, "")
+	require.NoError(t, err)
+	require.NotEqual(t, first.Locator(), second.Locator())
+
+	// Re-claiming with a valid proof results in the same terminal
+	third, _, _, err := terminalServer.acquireTerminal(context.Background(), &api.HostControlRequest_Hello{
+		TrustedSecret: "doesn't matter",
+		Locator:       first.Locator(),
+		LocatorProof:  first.LocatorProof(),
+	}, "")
+	require.NoError(t, err)
+	require.Equal(t, first, third)
+}
+
+func TestLocatorCannotBeReclaimedWithAnotherCertificate(t *testing.T) {
+	terminalServer, err := New(WithLocatorGracePeriod(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _, generation, err := terminalServer.acquireTerminal(context.Background(), &api.HostControlRequest_Hello{
+		TrustedSecret: "doesn't matter",
+	}, "CN=first")
+	require.NoError(t, err)
+	require.Equal(t, "CN=first", first.HostCertSubject())
+
+	terminalServer.releaseTerminal(zap.NewNop(), first, generation)
+
+	// Re-claiming with a valid proof, but on behalf of another host results in a new terminal
+	second, _, _, err := terminalServer.acquireTerminal(context.Background(), &api.HostControlRequest_Hello{
+		TrustedSecret: "doesn't matter",
+		Locator:       first.Locator(),
+		LocatorProof:  first.LocatorProof(),
+	}, "CN=second")
+	require.NoError(t, err)
+	require.NotEqual(t, first.Locator(), second.Locator())
+	require.Equal(t, "CN=second", second.HostCertSubject())
+}
+
+func TestLocatorCannotBeReclaimedWithOtherSecrets(t *testing.T) {
+	terminalServer, err := New(WithLocatorGracePeriod(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _, generation, err := terminalServer.acquireTerminal(context.Background(), &api.HostControlRequest_Hello{
+		TrustedSecret:  "trusted",
+		ReadOnlySecret: "read-only",
+	}, "")
+	require.NoError(t, err)
+
+	terminalServer.releaseTerminal(zap.NewNop(), first, generation)
+
+	// Re-claiming with a valid proof, but with another read-only secret results in a new terminal
+	second, _, generation, err := terminalServer.acquireTerminal(context.Background(), &api.HostControlRequest_Hello{
+		TrustedSecret:  "trusted",
+		ReadOnlySecret: "another read-only",
+		Locator:        first.Locator(),
+		LocatorProof:   first.LocatorProof(),
+	}, "")
+	require.NoError(t, err)
+	require.NotEqual(t, first.Locator(), second.Locator())
+	require.True(t, second.IsReadOnlySecretValid("another read-only"))
+	require.False(t, second.IsReadOnlySecretValid("read-only"))
+
+	terminalServer.releaseTerminal(zap.NewNop(), second, generation)
+
+	// Same for the trusted secret
+	third, _, _, err := terminalServer.acquireTerminal(context.Background(), &api.HostControlRequest_Hello{
+		TrustedSecret:  "another trusted",
+		ReadOnlySecret: "another read-only",
+		Locator:        second.Locator(),
+		LocatorProof:   second.LocatorProof(),
+	}, "")
+	require.NoError(t, err)
+	require.NotEqual(t, second.Locator(), third.Locator())
+	require.True(t, third.IsSecretValid("another trusted"))
+}
+
+func TestLocatorReservationExpires(t *testing.T) {
+	terminalServer, err := New(WithLocatorGracePeriod(100 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminal, _, generation, err := terminalServer.acquireTerminal(context.Background(), &api.HostControlRequest_Hello{
+		TrustedSecret: "doesn't matter",
+	}, "")
+	require.NoError(t, err)
+
+	terminalServer.releaseTerminal(zap.NewNop(), terminal, generation)
+
+	require.Eventually(t, func() bool {
+		return terminalServer.findTerminal(terminal.Locator()) == nil
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
+func TestHostCapabilitiesAreNegotiated(t *testing.T) {
+	terminalServer, err := New(WithLocatorGracePeriod(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Host predating the capability negotiation
+	legacy, _, generation, err := terminalServer.acquireTerminal(context.Background(), &api.HostControlRequest_Hello{
+		TrustedSecret: "doesn't matter",
+	}, "")
+	require.NoError(t, err)
+	require.Empty(t, legacy.HostCapabilities())
+
+	// Commands are refused instead of silently starting the shell
+	_, _, err = terminalServer.startSession(context.Background(), zap.NewNop(), legacy, nil,
+		&api.Command{Name: "true"}, nil)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Host reconnects after an upgrade, the unknown capabilities are ignored
+	terminalServer.releaseTerminal(zap.NewNop(), legacy, generation)
+
+	upgraded, _, _, err := terminalServer.acquireTerminal(context.Background(), &api.HostControlRequest_Hello{
+		TrustedSecret:   "doesn't matter",
+		Locator:         legacy.Locator(),
+		LocatorProof:    legacy.LocatorProof(),
+		ProtocolVersion: protocol.Version,
+		Capabilities:    []string{protocol.CapabilityCommand, "teleportation"},
+	}, "")
+	require.NoError(t, err)
+	require.Equal(t, legacy, upgraded)
+	require.Equal(t, []string{protocol.CapabilityCommand}, upgraded.HostCapabilities())
+}
+
+func TestMinProtocolVersion(t *testing.T) {
+	terminalServer, err := New(WithMinProtocolVersion(protocol.Version))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.NoError(t, terminalServer.checkProtocolVersion("host", protocol.Version))
+	require.Equal(t, codes.FailedPrecondition, status.Code(terminalServer.checkProtocolVersion("host", 0)))
+}
+
+func TestForwardedAddressIsTakenFromTheTrustedProxies(t *testing.T) {
+	// The client's own address is appended by the outermost trusted proxy
+	require.Equal(t, "10.0.0.1", forwardedAddress([]string{"192.0.2.1, 10.0.0.1"}, 1))
+	require.Equal(t, "10.0.0.1", forwardedAddress([]string{"192.0.2.1, 10.0.0.1", "10.0.0.2"}, 2))
+
+	// The client has connected through the inner proxy directly
+	require.Equal(t, "10.0.0.1", forwardedAddress([]string{"10.0.0.1"}, 2))
+
+	require.Empty(t, forwardedAddress(nil, 1))
+	require.Empty(t, forwardedAddress([]string{" , "}, 1))
+}
+
+func TestProxiedClientIPIsOnlyTrustedFromPeers(t *testing.T) {
+	proxy, err := New(WithPeerSecret("peer secret"))
+	require.NoError(t, err)
+
+	owner, err := New(WithPeerSecret("peer secret"))
+	require.NoError(t, err)
+
+	impostor, err := New(WithPeerSecret("another peer secret"))
+	require.NoError(t, err)
+
+	guestCtx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 12345},
+	})
+
+	proxiedCtx := func(ts *TerminalServer) context.Context {
+		outgoingMD, _ := metadata.FromOutgoingContext(ts.peerContext(guestCtx))
+
+		return peer.NewContext(metadata.NewIncomingContext(context.Background(), outgoingMD), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 12345},
+		})
+	}
+
+	require.Equal(t, "192.0.2.1", owner.clientIP(proxiedCtx(proxy)))
+	require.Equal(t, "10.0.0.1", owner.clientIP(proxiedCtx(impostor)))
+
+	// The client IP is not trusted without a valid signature
+	forgedCtx := metadata.NewIncomingContext(guestCtx, metadata.Pairs(
+		clientIPMetadataKey, "198.51.100.1",
+		clientIPSignatureMetadataKey, "00",
+	))
+	require.Equal(t, "192.0.2.1", owner.clientIP(forgedCtx))
 }
@@ -0,0 +1,60 @@
+package session
+
+import "github.com/cirruslabs/terminal/internal/api"
+
+type Option func(*Session)
+
+// WithOutputBufferSize specifies how many bytes of the most recent terminal
+// output to keep for replaying it to the Guests resuming the session,
+// zero or a negative size disables the replay.
+func WithOutputBufferSize(outputBufferSize int) Option {
+	return func(session *Session) {
+		session.outputBufferSize = outputBufferSize
+	}
+}
+
+// WithCommand runs the specified command on the Host instead of the shell.
+func WithCommand(command *api.Command) Option {
+	return func(session *Session) {
+		session.command = command
+	}
+}
+
+// WithShellOverride asks the Host to run a different shell or to start it in
+// a different directory, the Host decides whether to allow that or not.
+func WithShellOverride(shellOverride *api.ShellOverride) Option {
+	return func(session *Session) {
+		session.shellOverride = shellOverride
+	}
+}
+
+// WithFlowControlWindow specifies how many output bytes the Host can send before
+// waiting for them to be queued for the Guests. Zero disables the flow control.
+func WithFlowControlWindow(flowControlWindow uint64) Option {
+	return func(session *Session) {
+		session.flowControlWindow = flowControlWindow
+	}
+}
+
+// WithGuestBufferSize specifies how many bytes of the terminal output to queue for
+// each Guest before applying the slow Guest policy, see WithSlowGuestPolicy().
+func WithGuestBufferSize(guestBufferSize int) Option {
+	return func(session *Session) {
+		session.guestBufferSize = guestBufferSize
+	}
+}
+
+// WithSlowGuestPolicy specifies what to do when the buffer of the Guest in control of the session is full.
+func WithSlowGuestPolicy(slowGuestPolicy SlowGuestPolicy) Option {
+	return func(session *Session) {
+		session.slowGuestPolicy = slowGuestPolicy
+	}
+}
+
+// WithObserverSlowGuestPolicy specifies what to do when the buffer of a read-only Guest
+// is full, by default the oldest output queued for that Guest is dropped.
+func WithObserverSlowGuestPolicy(observerSlowGuestPolicy SlowGuestPolicy) Option {
+	return func(session *Session) {
+		session.observerSlowGuestPolicy = observerSlowGuestPolicy
+	}
+}
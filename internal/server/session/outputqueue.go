@@ -0,0 +1,193 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"github.com/cirruslabs/terminal/internal/api"
+	"sync"
+)
+
+// SlowGuestPolicy decides what happens when a Guest can't keep up with the terminal output.
+type SlowGuestPolicy int
+
+const (
+	// SlowGuestPolicyBlock stops accepting the terminal output from the Host until
+	// the slow Guest catches up, which eventually pauses the program on the Host.
+	SlowGuestPolicyBlock SlowGuestPolicy = iota
+
+	// SlowGuestPolicyDropOldest discards the oldest terminal output that's not yet
+	// delivered to the slow Guest and tells it about that with an OutputTruncated message.
+	SlowGuestPolicyDropOldest
+)
+
+func (policy SlowGuestPolicy) String() string {
+	switch policy {
+	case SlowGuestPolicyBlock:
+		return "block"
+	case SlowGuestPolicyDropOldest:
+		return "drop-oldest"
+	default:
+		return fmt.Sprintf("SlowGuestPolicy(%d)", int(policy))
+	}
+}
+
+func ParseSlowGuestPolicy(s string) (SlowGuestPolicy, error) {
+	switch s {
+	case "block":
+		return SlowGuestPolicyBlock, nil
+	case "drop-oldest":
+		return SlowGuestPolicyDropOldest, nil
+	default:
+		return 0, fmt.Errorf("unknown slow guest policy %q, should be either \"block\" or \"drop-oldest\"", s)
+	}
+}
+
+// outputQueue holds the terminal output that's not yet delivered to a single Guest,
+// only the output and the error output count towards the queue's limit.
+type outputQueue struct {
+	lock   sync.Mutex
+	limit  int
+	policy SlowGuestPolicy
+
+	messages []*api.GuestTerminalResponse
+	size     int
+
+	droppedOutput      uint64
+	droppedErrorOutput uint64
+
+	pushed chan struct{}
+	popped chan struct{}
+}
+
+func newOutputQueue(limit int, policy SlowGuestPolicy) *outputQueue {
+	return &outputQueue{
+		limit:  limit,
+		policy: policy,
+		pushed: make(chan struct{}, 1),
+		popped: make(chan struct{}, 1),
+	}
+}
+
+// push adds the message to the end of the queue. When the queue is full, it either waits
+// for the Guest to catch up or drops the oldest output, depending on the policy.
+func (queue *outputQueue) push(ctx context.Context, message *api.GuestTerminalResponse) error {
+	size := outputSize(message)
+
+	queue.lock.Lock()
+
+	if queue.policy == SlowGuestPolicyBlock {
+		for queue.size != 0 && queue.size+size > queue.limit {
+			queue.lock.Unlock()
+
+			select {
+			case <-queue.popped:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			queue.lock.Lock()
+		}
+	}
+
+	queue.messages = append(queue.messages, message)
+	queue.size += size
+
+	if queue.policy == SlowGuestPolicyDropOldest {
+		queue.dropOldest()
+	}
+
+	queue.lock.Unlock()
+
+	notify(queue.pushed)
+
+	return nil
+}
+
+// dropOldest drops the oldest output until the queue fits into its limit,
+// the newest message and the messages other than output are always kept.
+func (queue *outputQueue) dropOldest() {
+	for i := 0; queue.size > queue.limit && i < len(queue.messages)-1; {
+		message := queue.messages[i]
+
+		switch op := message.Operation.(type) {
+		case *api.GuestTerminalResponse_Output:
+			queue.droppedOutput += uint64(len(op.Output.Data))
+		case *api.GuestTerminalResponse_ErrorOutput:
+			queue.droppedErrorOutput += uint64(len(op.ErrorOutput.Data))
+		default:
+			i++
+
+			continue
+		}
+
+		queue.size -= outputSize(message)
+		queue.messages = append(queue.messages[:i], queue.messages[i+1:]...)
+	}
+}
+
+// pop waits for the next message, which is an OutputTruncated
+// message if some of the output was dropped since the last pop.
+func (queue *outputQueue) pop(ctx context.Context) (*api.GuestTerminalResponse, error) {
+	for {
+		queue.lock.Lock()
+
+		if queue.droppedOutput != 0 || queue.droppedErrorOutput != 0 {
+			message := &api.GuestTerminalResponse{
+				Operation: &api.GuestTerminalResponse_OutputTruncated{
+					OutputTruncated: &api.OutputTruncated{
+						DroppedOutputBytes:      queue.droppedOutput,
+						DroppedErrorOutputBytes: queue.droppedErrorOutput,
+					},
+				},
+			}
+
+			queue.droppedOutput = 0
+			queue.droppedErrorOutput = 0
+
+			queue.lock.Unlock()
+
+			return message, nil
+		}
+
+		if len(queue.messages) != 0 {
+			message := queue.messages[0]
+
+			queue.messages[0] = nil
+			queue.messages = queue.messages[1:]
+			queue.size -= outputSize(message)
+
+			queue.lock.Unlock()
+
+			notify(queue.popped)
+
+			return message, nil
+		}
+
+		queue.lock.Unlock()
+
+		select {
+		case <-queue.pushed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func outputSize(message *api.GuestTerminalResponse) int {
+	switch op := message.Operation.(type) {
+	case *api.GuestTerminalResponse_Output:
+		return len(op.Output.Data)
+	case *api.GuestTerminalResponse_ErrorOutput:
+		return len(op.ErrorOutput.Data)
+	default:
+		return 0
+	}
+}
+
+// notify wakes up the waiter (if any) without blocking.
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,85 @@
+package session
+
+// ringBuffer keeps the most recent terminal output
+// for replaying it to the resuming Guests.
+type ringBuffer struct {
+	buf  []byte
+	head int
+	full bool
+
+	// total number of bytes ever written
+	written uint64
+}
+
+// newRingBuffer creates a ring buffer of the specified size,
+// the non-positive sizes result in no output being kept.
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{
+		buf: make([]byte, max(size, 0)),
+	}
+}
+
+func (rb *ringBuffer) Write(p []byte) {
+	rb.written += uint64(len(p))
+
+	if len(rb.buf) == 0 {
+		return
+	}
+
+	// Only the tail of the chunk will fit anyway
+	if len(p) >= len(rb.buf) {
+		copy(rb.buf, p[len(p)-len(rb.buf):])
+		rb.head = 0
+		rb.full = true
+
+		return
+	}
+
+	n := copy(rb.buf[rb.head:], p)
+	if n < len(p) {
+		copy(rb.buf, p[n:])
+		rb.full = true
+	}
+
+	rb.head = (rb.head + len(p)) % len(rb.buf)
+	if rb.head == 0 {
+		rb.full = true
+	}
+}
+
+// Offset returns the offset of the next byte to be written.
+func (rb *ringBuffer) Offset() uint64 {
+	return rb.written
+}
+
+// Since returns the buffered bytes starting from the specified offset,
+// or starting from the oldest buffered byte if the offset was already
+// evicted, along with the offset of the first returned byte.
+func (rb *ringBuffer) Since(offset uint64) ([]byte, uint64) {
+	buffered := rb.head
+	if rb.full {
+		buffered = len(rb.buf)
+	}
+
+	oldest := rb.written - uint64(buffered)
+
+	if offset < oldest {
+		offset = oldest
+	}
+	if offset > rb.written {
+		offset = rb.written
+	}
+
+	n := int(rb.written - offset)
+	result := make([]byte, 0, n)
+
+	start := (rb.head - n + len(rb.buf)) % max(len(rb.buf), 1)
+	if start+n <= len(rb.buf) {
+		result = append(result, rb.buf[start:start+n]...)
+	} else {
+		result = append(result, rb.buf[start:]...)
+		result = append(result, rb.buf[:n-(len(rb.buf)-start)]...)
+	}
+
+	return result, offset
+}
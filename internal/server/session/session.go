@@ -2,8 +2,18 @@ package session
 
 import (
 	"context"
+	"crypto/subtle"
 	"github.com/cirruslabs/terminal/internal/api"
 	"github.com/google/uuid"
+	"math"
+	"sync"
+)
+
+const (
+	defaultGuestBufferSize = 1024 * 1024
+
+	// The window update is sent once this fraction of the window is consumed
+	windowUpdateDivisor = 4
 )
 
 type Session struct {
@@ -11,43 +21,370 @@ type Session struct {
 	subCtx context.Context
 	cancel context.CancelFunc
 
-	token string
+	token       string
+	id          string
+	resumeToken string
 
 	requestedDimensions *api.TerminalDimensions
+	command             *api.Command
+	shellOverride       *api.ShellOverride
+
+	outputBufferSize int
+
+	flowControlWindow uint64
+	guestBufferSize   int
+	slowGuestPolicy   SlowGuestPolicy
+
+	// Read-only Guests shouldn't be able to slow down the session for everyone else
+	observerSlowGuestPolicy SlowGuestPolicy
+
+	consumedOutputLock sync.Mutex
+	consumedOutput     uint64
+
+	guestsLock     sync.RWMutex
+	guests         map[*Guest]struct{}
+	driver         *Guest
+	driverDetached bool
+	outputBuffer   *ringBuffer
+	termination    *api.Termination
 
 	TerminalInputChan    chan []byte
-	TerminalOutputChan   chan []byte
+	CloseInputChan       chan struct{}
 	ChangeDimensionsChan chan *api.TerminalDimensions
+
+	// TerminalOutputChan carries the terminal output, error output
+	// and the termination messages from the Host to the Guests,
+	// the session is closed once the termination is delivered
+	TerminalOutputChan chan *api.GuestTerminalResponse
+
+	// OutputConsumedChan is signalled once enough terminal output was queued
+	// for the Guests to let the Host send more, see TakeConsumedOutput()
+	OutputConsumedChan chan struct{}
+}
+
+// Guest is a single Guest attached to the session.
+type Guest struct {
+	//nolint:containedctx // seems perfectly valid for our use-case
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	readOnly bool
+
+	queue                *outputQueue
+	terminationDelivered chan struct{}
+
+	OutputChan chan *api.GuestTerminalResponse
 }
 
-func New(ctx context.Context, requestedDimensions *api.TerminalDimensions) *Session {
+func New(ctx context.Context, requestedDimensions *api.TerminalDimensions, opts ...Option) *Session {
 	subCtx, cancel := context.WithCancel(ctx)
 
-	return &Session{
+	session := &Session{
 		subCtx:               subCtx,
 		cancel:               cancel,
 		token:                uuid.New().String(),
+		id:                   uuid.New().String(),
+		resumeToken:          uuid.New().String(),
 		requestedDimensions:  requestedDimensions,
+		guests:               make(map[*Guest]struct{}),
 		TerminalInputChan:    make(chan []byte),
-		TerminalOutputChan:   make(chan []byte),
+		CloseInputChan:       make(chan struct{}),
 		ChangeDimensionsChan: make(chan *api.TerminalDimensions),
+		TerminalOutputChan:   make(chan *api.GuestTerminalResponse),
+		OutputConsumedChan:   make(chan struct{}, 1),
+		guestBufferSize:      defaultGuestBufferSize,
+
+		observerSlowGuestPolicy: SlowGuestPolicyDropOldest,
 	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(session)
+	}
+
+	session.outputBuffer = newRingBuffer(session.outputBufferSize)
+
+	go session.fanOut()
+
+	return session
 }
 
 func (session *Session) Token() string {
 	return session.token
 }
 
+// ID returns the session identifier, which, unlike the token,
+// is safe to share with the Guests wishing to join this session.
+func (session *Session) ID() string {
+	return session.id
+}
+
+// ResumeToken returns the token that lets the Guest in control
+// of this session to resume it after getting disconnected.
+func (session *Session) ResumeToken() string {
+	return session.resumeToken
+}
+
+func (session *Session) IsResumeTokenValid(resumeToken string) bool {
+	return subtle.ConstantTimeCompare([]byte(session.resumeToken), []byte(resumeToken)) == 1
+}
+
 func (session *Session) RequestedDimensions() *api.TerminalDimensions {
 	return session.requestedDimensions
 }
 
+// Command returns the command to run instead of the shell, if any.
+func (session *Session) Command() *api.Command {
+	return session.command
+}
+
+// ShellOverride returns the shell and the working directory requested by the Guest, if any.
+func (session *Session) ShellOverride() *api.ShellOverride {
+	return session.shellOverride
+}
+
+// FlowControlWindow returns the number of output bytes the Host can send
+// before waiting for the window update, zero means no flow control.
+func (session *Session) FlowControlWindow() uint64 {
+	return session.flowControlWindow
+}
+
+// TakeConsumedOutput returns the number of output bytes queued for the Guests
+// since the last call, which the Host can now send in addition.
+func (session *Session) TakeConsumedOutput() uint64 {
+	session.consumedOutputLock.Lock()
+	defer session.consumedOutputLock.Unlock()
+
+	consumedOutput := session.consumedOutput
+	session.consumedOutput = 0
+
+	return consumedOutput
+}
+
+// consumeOutput accounts for the output queued for the Guests, window updates are
+// batched to avoid sending them for every chunk of the output.
+func (session *Session) consumeOutput(n int) {
+	if session.flowControlWindow == 0 || n == 0 {
+		return
+	}
+
+	session.consumedOutputLock.Lock()
+	session.consumedOutput += uint64(n)
+	consumedOutput := session.consumedOutput
+	session.consumedOutputLock.Unlock()
+
+	if consumedOutput >= max(session.flowControlWindow/windowUpdateDivisor, 1) {
+		notify(session.OutputConsumedChan)
+	}
+}
+
 func (session *Session) Context() context.Context {
 	return session.subCtx
 }
 
+// Attach registers a new Guest that will receive the terminal output
+// until either its context is cancelled or it's detached.
+//
+// Only one Guest can be in control of the session at a time, so
+// attaching a non-read-only Guest supersedes the previous one.
+func (session *Session) Attach(ctx context.Context, readOnly bool) *Guest {
+	// Offset past the end of the output means no replay
+	guest, _, _ := session.Resume(ctx, readOnly, math.MaxUint64)
+
+	return guest
+}
+
+// Resume is like Attach, but additionally returns the terminal output
+// starting from the specified offset (or from the oldest buffered output
+// if the offset was already evicted) along with its actual offset.
+func (session *Session) Resume(ctx context.Context, readOnly bool, offset uint64) (*Guest, []byte, uint64) {
+	session.guestsLock.Lock()
+	defer session.guestsLock.Unlock()
+
+	guestCtx, cancel := context.WithCancel(ctx)
+
+	slowGuestPolicy := session.slowGuestPolicy
+	if readOnly {
+		slowGuestPolicy = session.observerSlowGuestPolicy
+	}
+
+	guest := &Guest{
+		ctx:                  guestCtx,
+		cancel:               cancel,
+		readOnly:             readOnly,
+		queue:                newOutputQueue(session.guestBufferSize, slowGuestPolicy),
+		terminationDelivered: make(chan struct{}),
+		OutputChan:           make(chan *api.GuestTerminalResponse),
+	}
+
+	go guest.deliverOutput()
+
+	session.guests[guest] = struct{}{}
+
+	if !readOnly {
+		if session.driver != nil {
+			session.driver.cancel()
+		}
+
+		session.driver = guest
+		session.driverDetached = false
+	}
+
+	replay, replayOffset := session.outputBuffer.Since(offset)
+
+	return guest, replay, replayOffset
+}
+
+func (session *Session) Detach(guest *Guest) {
+	session.guestsLock.Lock()
+	defer session.guestsLock.Unlock()
+
+	guest.cancel()
+
+	delete(session.guests, guest)
+
+	if session.driver == guest {
+		session.driverDetached = true
+	}
+}
+
+// IsAbandonedBy returns true if the specified Guest was the last one
+// in control of the session and no other Guest has taken over since.
+func (session *Session) IsAbandonedBy(guest *Guest) bool {
+	session.guestsLock.RLock()
+	defer session.guestsLock.RUnlock()
+
+	return session.driver == guest && session.driverDetached
+}
+
+func (session *Session) NumGuests() int {
+	session.guestsLock.RLock()
+	defer session.guestsLock.RUnlock()
+
+	return len(session.guests)
+}
+
 func (session *Session) Close() error {
 	session.cancel()
 
 	return nil
 }
+
+// Finish delivers the termination message to the Guests after
+// the preceding terminal output and closes the session.
+func (session *Session) Finish(termination *api.Termination) {
+	select {
+	case session.TerminalOutputChan <- &api.GuestTerminalResponse{
+		Operation: &api.GuestTerminalResponse_Termination{
+			Termination: termination,
+		},
+	}:
+	case <-session.subCtx.Done():
+	}
+}
+
+// Terminate records the reason for the session's termination, which
+// is later reported to the Guests by the Termination(), and closes the session.
+//
+// Unlike sending the termination message via TerminalOutputChan, the
+// terminal output that's not yet delivered to the Guests might be lost.
+func (session *Session) Terminate(termination *api.Termination) {
+	session.guestsLock.Lock()
+	if session.termination == nil {
+		session.termination = termination
+	}
+	session.guestsLock.Unlock()
+
+	session.cancel()
+}
+
+// Termination returns the reason for the session's termination,
+// or nil if the session is still active or was closed without a reason.
+func (session *Session) Termination() *api.Termination {
+	session.guestsLock.RLock()
+	defer session.guestsLock.RUnlock()
+
+	return session.termination
+}
+
+// fanOut queues the terminal output from the Host for all the attached Guests.
+func (session *Session) fanOut() {
+	for {
+		select {
+		case message := <-session.TerminalOutputChan:
+			guests := session.record(message)
+
+			for _, guest := range guests {
+				// Only fails when the Guest is gone
+				_ = guest.queue.push(guest.ctx, message)
+			}
+
+			session.consumeOutput(outputSize(message))
+
+			if termination := message.GetTermination(); termination != nil {
+				// Let the Guests receive the preceding output before closing the session
+				for _, guest := range guests {
+					select {
+					case <-guest.terminationDelivered:
+					case <-guest.ctx.Done():
+					}
+				}
+
+				session.Terminate(termination)
+
+				return
+			}
+		case <-session.subCtx.Done():
+			return
+		}
+	}
+}
+
+// record buffers the terminal output for the resuming Guests and
+// returns the Guests that are currently attached to the session.
+func (session *Session) record(message *api.GuestTerminalResponse) []*Guest {
+	session.guestsLock.Lock()
+	defer session.guestsLock.Unlock()
+
+	if output := message.GetOutput(); output != nil {
+		session.outputBuffer.Write(output.Data)
+	}
+
+	var result []*Guest
+
+	for guest := range session.guests {
+		result = append(result, guest)
+	}
+
+	return result
+}
+
+// deliverOutput passes the queued terminal output to the OutputChan until the Guest is gone.
+func (guest *Guest) deliverOutput() {
+	for {
+		message, err := guest.queue.pop(guest.ctx)
+		if err != nil {
+			return
+		}
+
+		select {
+		case guest.OutputChan <- message:
+		case <-guest.ctx.Done():
+			return
+		}
+
+		if message.GetTermination() != nil {
+			close(guest.terminationDelivered)
+
+			return
+		}
+	}
+}
+
+func (guest *Guest) Context() context.Context {
+	return guest.ctx
+}
+
+func (guest *Guest) ReadOnly() bool {
+	return guest.readOnly
+}
@@ -1,10 +1,13 @@
 package session_test
 
 import (
+	"bytes"
 	"context"
+	"github.com/cirruslabs/terminal/internal/api"
 	"github.com/cirruslabs/terminal/internal/server/session"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestSessionCloseResultsInContextCancellation(t *testing.T) {
@@ -18,3 +21,234 @@ func TestSessionCloseResultsInContextCancellation(t *testing.T) {
 		t.Fatal("session's context wasn't cancelled after session.Close()")
 	}
 }
+
+func TestOutputIsDeliveredToAllGuests(t *testing.T) {
+	session := session.New(context.Background(), nil)
+	defer session.Close()
+
+	driver := session.Attach(context.Background(), false)
+	observer := session.Attach(context.Background(), true)
+	require.Equal(t, 2, session.NumGuests())
+
+	go func() {
+		session.TerminalOutputChan <- output("hello")
+	}()
+
+	require.Equal(t, []byte("hello"), (<-driver.OutputChan).GetOutput().Data)
+	require.Equal(t, []byte("hello"), (<-observer.OutputChan).GetOutput().Data)
+
+	session.Detach(observer)
+	require.Equal(t, 1, session.NumGuests())
+}
+
+func TestFinishDeliversTerminationAfterOutput(t *testing.T) {
+	session := session.New(context.Background(), nil)
+	defer session.Close()
+
+	driver := session.Attach(context.Background(), false)
+
+	termination := &api.Termination{
+		Reason: api.Termination_SHELL_EXITED,
+		ExitStatus: &api.ExitStatus{
+			Code: 1,
+		},
+	}
+
+	go func() {
+		session.TerminalOutputChan <- output("bye")
+		session.Finish(termination)
+	}()
+
+	require.Equal(t, []byte("bye"), (<-driver.OutputChan).GetOutput().Data)
+	require.Equal(t, termination, (<-driver.OutputChan).GetTermination())
+
+	<-session.Context().Done()
+	require.Equal(t, termination, session.Termination())
+}
+
+func TestResumeReplaysBufferedOutput(t *testing.T) {
+	session := session.New(context.Background(), nil, session.WithOutputBufferSize(8))
+	defer session.Close()
+
+	// Wait for the output to be processed by observing it
+	observer := session.Attach(context.Background(), true)
+
+	go func() {
+		session.TerminalOutputChan <- output("hello")
+		session.TerminalOutputChan <- output("world!")
+	}()
+
+	require.Equal(t, []byte("hello"), (<-observer.OutputChan).GetOutput().Data)
+	require.Equal(t, []byte("world!"), (<-observer.OutputChan).GetOutput().Data)
+
+	// The beginning of the output was already evicted
+	driver, replay, offset := session.Resume(context.Background(), false, 0)
+	require.Equal(t, "loworld!", string(replay))
+	require.EqualValues(t, 3, offset)
+
+	// Only the missed output is replayed
+	_, replay, offset = session.Resume(context.Background(), false, 5)
+	require.Equal(t, "world!", string(replay))
+	require.EqualValues(t, 5, offset)
+
+	// Second driver has superseded the first one
+	require.Error(t, driver.Context().Err())
+}
+
+func TestNegativeOutputBufferSizeDisablesReplay(t *testing.T) {
+	session := session.New(context.Background(), nil, session.WithOutputBufferSize(-1))
+	defer session.Close()
+
+	observer := session.Attach(context.Background(), true)
+
+	go func() {
+		session.TerminalOutputChan <- output("hello")
+	}()
+
+	require.Equal(t, []byte("hello"), (<-observer.OutputChan).GetOutput().Data)
+
+	_, replay, offset := session.Resume(context.Background(), false, 0)
+	require.Empty(t, replay)
+	require.EqualValues(t, 5, offset)
+}
+
+func TestSessionIsAbandonedOnlyByTheLastDriver(t *testing.T) {
+	session := session.New(context.Background(), nil)
+	defer session.Close()
+
+	first := session.Attach(context.Background(), false)
+	second := session.Attach(context.Background(), false)
+
+	session.Detach(first)
+	require.False(t, session.IsAbandonedBy(first))
+	require.False(t, session.IsAbandonedBy(second))
+
+	session.Detach(second)
+	require.True(t, session.IsAbandonedBy(second))
+}
+
+func TestSlowGuestPolicyBlock(t *testing.T) {
+	session := session.New(context.Background(), nil, session.WithGuestBufferSize(4),
+		session.WithSlowGuestPolicy(session.SlowGuestPolicyBlock))
+	defer session.Close()
+
+	slow := session.Attach(context.Background(), false)
+
+	// One chunk is waiting to be received by the Guest, one is queued
+	// and one is being queued, so the next one should be blocked
+	for _, chunk := range []string{"aaaa", "bbbb", "cccc"} {
+		session.TerminalOutputChan <- output(chunk)
+	}
+
+	blocked := make(chan struct{})
+
+	go func() {
+		session.TerminalOutputChan <- output("dddd")
+		close(blocked)
+	}()
+
+	select {
+	case <-blocked:
+		t.Fatal("output wasn't blocked by the slow guest")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Nothing should be lost once the Guest catches up
+	for _, chunk := range []string{"aaaa", "bbbb", "cccc", "dddd"} {
+		require.Equal(t, []byte(chunk), (<-slow.OutputChan).GetOutput().Data)
+	}
+
+	<-blocked
+}
+
+func TestSlowObserverDoesNotBlockByDefault(t *testing.T) {
+	session := session.New(context.Background(), nil, session.WithGuestBufferSize(4),
+		session.WithSlowGuestPolicy(session.SlowGuestPolicyBlock))
+	defer session.Close()
+
+	driver := session.Attach(context.Background(), false)
+	observer := session.Attach(context.Background(), true)
+
+	// The slow read-only Guest shouldn't hold back the Guest in control
+	for _, chunk := range []string{"aaaa", "bbbb", "cccc", "dddd", "eeee"} {
+		session.TerminalOutputChan <- output(chunk)
+
+		require.Equal(t, []byte(chunk), (<-driver.OutputChan).GetOutput().Data)
+	}
+
+	var truncated bool
+
+	for !truncated {
+		truncated = (<-observer.OutputChan).GetOutputTruncated() != nil
+	}
+}
+
+func TestSlowGuestPolicyDropOldest(t *testing.T) {
+	session := session.New(context.Background(), nil, session.WithGuestBufferSize(10),
+		session.WithSlowGuestPolicy(session.SlowGuestPolicyDropOldest))
+	defer session.Close()
+
+	fast := session.Attach(context.Background(), true)
+	slow := session.Attach(context.Background(), false)
+
+	chunks := []string{"aaaa", "bbbb", "cccc", "dddd", "eeee"}
+
+	// The slow Guest shouldn't hold back the others
+	for _, chunk := range chunks {
+		session.TerminalOutputChan <- output(chunk)
+
+		require.Equal(t, []byte(chunk), (<-fast.OutputChan).GetOutput().Data)
+	}
+
+	session.Finish(&api.Termination{Reason: api.Termination_SHELL_EXITED})
+	require.NotNil(t, (<-fast.OutputChan).GetTermination())
+
+	var received []byte
+	var dropped uint64
+
+	for {
+		message := <-slow.OutputChan
+
+		if message.GetTermination() != nil {
+			break
+		}
+
+		if outputTruncated := message.GetOutputTruncated(); outputTruncated != nil {
+			dropped += outputTruncated.DroppedOutputBytes
+
+			continue
+		}
+
+		received = append(received, message.GetOutput().Data...)
+	}
+
+	require.NotZero(t, dropped)
+	require.EqualValues(t, 20, uint64(len(received))+dropped)
+	require.True(t, bytes.HasSuffix(received, []byte("eeee")))
+}
+
+func TestConsumedOutputIsReported(t *testing.T) {
+	session := session.New(context.Background(), nil, session.WithFlowControlWindow(16))
+	defer session.Close()
+
+	driver := session.Attach(context.Background(), false)
+
+	go func() {
+		session.TerminalOutputChan <- output("hello")
+	}()
+
+	require.Equal(t, []byte("hello"), (<-driver.OutputChan).GetOutput().Data)
+
+	<-session.OutputConsumedChan
+	require.EqualValues(t, 5, session.TakeConsumedOutput())
+}
+
+func output(s string) *api.GuestTerminalResponse {
+	return &api.GuestTerminalResponse{
+		Operation: &api.GuestTerminalResponse_Output{
+			Output: &api.Data{
+				Data: []byte(s),
+			},
+		},
+	}
+}
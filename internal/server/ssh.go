@@ -0,0 +1,499 @@
+package server
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/server/session"
+	"github.com/cirruslabs/terminal/internal/server/tunnel"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"io"
+	"net"
+	"strings"
+)
+
+var ErrSSHInvalidCredentials = errors.New("invalid locator or secret")
+
+// Shown to the SSH guests in place of the terminal output they couldn't keep up with.
+const outputTruncatedMarker = "\r\n*** output truncated ***\r\n"
+
+// SSH guests authenticate by using the terminal's locator as a username
+// and the terminal's secret as a password or a keyboard-interactive answer.
+func (ts *TerminalServer) newSSHServerConfig() (*ssh.ServerConfig, error) {
+	hostKey := ts.sshHostKey
+
+	if hostKey == nil {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		hostKey, err = ssh.NewSignerFromKey(privateKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			return ts.authenticateSSH(conn, string(password))
+		},
+		KeyboardInteractiveCallback: func(
+			conn ssh.ConnMetadata,
+			client ssh.KeyboardInteractiveChallenge,
+		) (*ssh.Permissions, error) {
+			answers, err := client("", "", []string{"Secret: "}, []bool{false})
+			if err != nil {
+				return nil, err
+			}
+			if len(answers) != 1 {
+				return nil, ErrSSHInvalidCredentials
+			}
+
+			return ts.authenticateSSH(conn, answers[0])
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	return config, nil
+}
+
+func (ts *TerminalServer) authenticateSSH(conn ssh.ConnMetadata, secret string) (*ssh.Permissions, error) {
+	logger := ts.logger.With(LocatorField(conn.User()), HashedSecretField(secret))
+
+	clientIP := hostOnly(conn.RemoteAddr().String())
+	if err := ts.admitGuest(clientIP, conn.User()); err != nil {
+		logger.Warn("refusing the SSH guest", zap.String("client-ip", clientIP), zap.Error(err))
+		return nil, ErrSSHInvalidCredentials
+	}
+
+	terminal := ts.findTerminal(conn.User())
+	if terminal == nil {
+		ts.guestFailed(logger, clientIP, "")
+		logger.Warn("SSH guest requested a terminal that is not registered on this server")
+		return nil, ErrSSHInvalidCredentials
+	}
+
+	// Read-only guests can only join existing sessions, which is not supported over SSH
+	if !terminal.IsSecretValid(secret) {
+		ts.guestFailed(logger, clientIP, terminal.Locator())
+		logger.Warn("SSH guest provided an invalid secret")
+		return nil, ErrSSHInvalidCredentials
+	}
+
+	ts.guestSucceeded(clientIP, terminal.Locator())
+
+	return &ssh.Permissions{}, nil
+}
+
+func (ts *TerminalServer) serveSSH(ctx context.Context, listener net.Listener, config *ssh.ServerConfig) error {
+	for {
+		netConn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		go ts.handleSSHConn(ctx, netConn, config)
+	}
+}
+
+func (ts *TerminalServer) handleSSHConn(ctx context.Context, netConn net.Conn, config *ssh.ServerConfig) {
+	sshConn, newChannels, requests, err := ssh.NewServerConn(netConn, config)
+	if err != nil {
+		ts.logger.Debug("SSH handshake failed", zap.String("remote-address", netConn.RemoteAddr().String()),
+			zap.Error(err))
+		_ = netConn.Close()
+
+		return
+	}
+	defer sshConn.Close()
+
+	go ssh.DiscardRequests(requests)
+
+	// Make sure we don't leave the SSH connection hanging when the server shuts down
+	stop := context.AfterFunc(ctx, func() {
+		_ = sshConn.Close()
+	})
+	defer stop()
+
+	logger := ts.logger.With(LocatorField(sshConn.User()),
+		zap.String("remote-address", sshConn.RemoteAddr().String()))
+
+	for newChannel := range newChannels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "only session channels are supported")
+
+			continue
+		}
+
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			logger.Warn("failed to accept SSH channel", zap.Error(err))
+
+			continue
+		}
+
+		go ts.handleSSHChannel(ctx, logger, sshConn.User(), channel, requests)
+	}
+}
+
+// handleSSHChannel bridges the SSH session channel onto the terminal session.
+func (ts *TerminalServer) handleSSHChannel(
+	ctx context.Context,
+	logger *zap.Logger,
+	locator string,
+	channel ssh.Channel,
+	requests <-chan *ssh.Request,
+) {
+	defer channel.Close()
+
+	channelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var requestedDimensions *api.TerminalDimensions
+	var ptyRequested bool
+	var terminalSession *session.Session
+	var subsystemStarted bool
+
+	for {
+		var request *ssh.Request
+
+		select {
+		case request = <-requests:
+		case <-channelCtx.Done():
+			return
+		}
+
+		if request == nil {
+			return
+		}
+
+		switch request.Type {
+		case "pty-req":
+			var ptyRequest struct {
+				Term         string
+				WidthColumns uint32
+				HeightRows   uint32
+				WidthPixels  uint32
+				HeightPixels uint32
+				Modes        string
+			}
+
+			if err := ssh.Unmarshal(request.Payload, &ptyRequest); err != nil {
+				_ = request.Reply(false, nil)
+
+				continue
+			}
+
+			requestedDimensions = &api.TerminalDimensions{
+				WidthColumns: ptyRequest.WidthColumns,
+				HeightRows:   ptyRequest.HeightRows,
+			}
+			ptyRequested = true
+
+			_ = request.Reply(true, nil)
+		case "window-change":
+			var windowChange struct {
+				WidthColumns uint32
+				HeightRows   uint32
+				WidthPixels  uint32
+				HeightPixels uint32
+			}
+
+			if err := ssh.Unmarshal(request.Payload, &windowChange); err != nil || terminalSession == nil {
+				_ = request.Reply(false, nil)
+
+				continue
+			}
+
+			select {
+			case terminalSession.ChangeDimensionsChan <- &api.TerminalDimensions{
+				WidthColumns: windowChange.WidthColumns,
+				HeightRows:   windowChange.HeightRows,
+			}:
+			case <-terminalSession.Context().Done():
+			}
+
+			_ = request.Reply(true, nil)
+		case "shell", "exec":
+			if terminalSession != nil || subsystemStarted {
+				_ = request.Reply(false, nil)
+
+				continue
+			}
+
+			// Run the requested command using the shell, just like the OpenSSH does
+			var command *api.Command
+
+			if request.Type == "exec" {
+				var execRequest struct {
+					Command string
+				}
+
+				if err := ssh.Unmarshal(request.Payload, &execRequest); err != nil {
+					_ = request.Reply(false, nil)
+
+					continue
+				}
+
+				command = &api.Command{
+					Name: "/bin/sh",
+					Args: []string{"-c", execRequest.Command},
+					Pty:  ptyRequested,
+				}
+			}
+
+			terminal := ts.findTerminal(locator)
+			if terminal == nil {
+				logger.Warn("terminal has disappeared before the SSH guest requested a shell")
+				_ = request.Reply(false, nil)
+
+				return
+			}
+
+			newSession, guest, err := ts.startSession(channelCtx, logger, terminal, requestedDimensions, command, nil)
+			if err != nil {
+				_ = request.Reply(false, nil)
+
+				return
+			}
+
+			_ = request.Reply(true, nil)
+
+			terminalSession = newSession
+
+			go func() {
+				defer cancel()
+
+				ts.bridgeSSH(logger.With(HashedTokenField(newSession.Token())), newSession, guest, channel)
+			}()
+		case "subsystem":
+			var subsystemRequest struct {
+				Name string
+			}
+
+			if err := ssh.Unmarshal(request.Payload, &subsystemRequest); err != nil ||
+				subsystemRequest.Name != subsystemSFTP || terminalSession != nil || subsystemStarted {
+				_ = request.Reply(false, nil)
+
+				continue
+			}
+
+			terminal := ts.findTerminal(locator)
+			if terminal == nil {
+				logger.Warn("terminal has disappeared before the SSH guest requested a subsystem")
+				_ = request.Reply(false, nil)
+
+				return
+			}
+
+			subsystemLogger := logger.With(zap.String("subsystem", subsystemRequest.Name))
+
+			newTunnel, hostEnd, err := ts.openTunnel(channelCtx, subsystemLogger, terminal, 0,
+				subsystemRequest.Name)
+			if err != nil {
+				_ = request.Reply(false, nil)
+
+				return
+			}
+
+			_ = request.Reply(true, nil)
+
+			subsystemStarted = true
+
+			subsystemLogger.Info("SSH guest has started a subsystem")
+
+			go func() {
+				defer cancel()
+
+				bridgeSSHTunnel(channel, tunnel.NewConn(newTunnel, hostEnd))
+			}()
+		default:
+			_ = request.Reply(false, nil)
+		}
+	}
+}
+
+func (ts *TerminalServer) bridgeSSH(
+	logger *zap.Logger,
+	session *session.Session,
+	guest *session.Guest,
+	channel ssh.Channel,
+) {
+	ts.guestsWG.Add(1)
+	defer ts.guestsWG.Done()
+
+	// SSH guests can't resume sessions, so there's no point in keeping them around
+	defer func() {
+		session.Detach(guest)
+		ts.releaseSession(logger, session, guest, true)
+	}()
+
+	// Process terminal input from the SSH guest
+	inputErrChan := make(chan error, 1)
+
+	go func() {
+		const bufSize = 4096
+		buf := make([]byte, bufSize)
+
+		for {
+			n, err := channel.Read(buf)
+			if err != nil {
+				inputErrChan <- err
+
+				return
+			}
+
+			// Copy the chunk since the buffer is re-used
+			chunk := append([]byte{}, buf[:n]...)
+
+			select {
+			case session.TerminalInputChan <- chunk:
+			case <-guest.Context().Done():
+				inputErrChan <- guest.Context().Err()
+
+				return
+			case <-session.Context().Done():
+				inputErrChan <- session.Context().Err()
+
+				return
+			}
+		}
+	}()
+
+	// Process terminal output from the Host
+	for {
+		select {
+		case message := <-guest.OutputChan:
+			var err error
+
+			switch op := message.Operation.(type) {
+			case *api.GuestTerminalResponse_Output:
+				_, err = channel.Write(op.Output.Data)
+			case *api.GuestTerminalResponse_ErrorOutput:
+				_, err = channel.Stderr().Write(op.ErrorOutput.Data)
+			case *api.GuestTerminalResponse_OutputTruncated:
+				_, err = channel.Write([]byte(outputTruncatedMarker))
+			case *api.GuestTerminalResponse_Termination:
+				reportSSHTermination(channel, op.Termination)
+
+				return
+			}
+
+			if err != nil {
+				logger.Warn("failed to send the host's terminal output to the SSH guest", zap.Error(err))
+
+				return
+			}
+		case err := <-inputErrChan:
+			if !errors.Is(err, io.EOF) {
+				return
+			}
+
+			// Commands without a PTY may be waiting for the end of the input
+			select {
+			case session.CloseInputChan <- struct{}{}:
+			case <-session.Context().Done():
+			}
+
+			inputErrChan = nil
+		case <-guest.Context().Done():
+			return
+		case <-session.Context().Done():
+			termination := sessionTermination(session)
+
+			logger.Info("session was terminated", zap.Stringer("reason", termination.Reason))
+			reportSSHTermination(channel, termination)
+
+			return
+		}
+	}
+}
+
+// bridgeSSHTunnel copies the data in both directions until both sides close their connections for writing.
+func bridgeSSHTunnel(channel ssh.Channel, conn *tunnel.Conn) {
+	defer conn.Close()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		// Tear down the channel if the tunnel has failed
+		if _, err := io.Copy(channel, conn); err != nil {
+			_ = channel.Close()
+
+			return
+		}
+
+		_ = channel.CloseWrite()
+	}()
+
+	if _, err := io.Copy(conn, channel); err != nil {
+		_ = conn.Close()
+	} else {
+		_ = conn.CloseWrite()
+	}
+
+	<-done
+}
+
+// reportSSHTermination tells the SSH guest how the shell or the command has exited
+// or, if the session has ended for some other reason, explains why.
+func reportSSHTermination(channel ssh.Channel, termination *api.Termination) {
+	exitStatus := termination.GetExitStatus()
+
+	if exitStatus == nil {
+		_, _ = channel.Stderr().Write([]byte("\r\n" + describeTermination(termination) + "\r\n"))
+
+		return
+	}
+
+	if exitStatus.Signal != "" {
+		exitSignal := struct {
+			Signal     string
+			CoreDumped bool
+			Error      string
+			Language   string
+		}{
+			Signal: exitStatus.Signal,
+		}
+
+		_, _ = channel.SendRequest("exit-signal", false, ssh.Marshal(&exitSignal))
+
+		return
+	}
+
+	sshExitStatus := struct {
+		Status uint32
+	}{
+		Status: uint32(exitStatus.Code), //nolint:gosec // negative codes are fine to wrap around
+	}
+
+	_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(&sshExitStatus))
+}
+
+// describeTermination returns a human-readable description of the session's termination.
+func describeTermination(termination *api.Termination) string {
+	if exitStatus := termination.GetExitStatus(); exitStatus != nil {
+		if exitStatus.Signal != "" {
+			return fmt.Sprintf("process was killed by signal %s", exitStatus.Signal)
+		}
+
+		return fmt.Sprintf("process exited with status %d", exitStatus.Code)
+	}
+
+	if message := termination.GetError().GetMessage(); message != "" {
+		return message
+	}
+
+	return strings.ToLower(strings.ReplaceAll(termination.Reason.String(), "_", " "))
+}
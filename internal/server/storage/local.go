@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	recordingExtension = ".cast"
+	metadataExtension  = ".json"
+	partialExtension   = ".partial"
+)
+
+// Local stores the recordings in a local directory, with
+// a sub-directory for each locator and a file for each session.
+type Local struct {
+	dir string
+}
+
+func NewLocal(dir string) *Local {
+	return &Local{
+		dir: dir,
+	}
+}
+
+func (storage *Local) Create(key Key, metadata *Metadata) (io.WriteCloser, error) {
+	basePath, err := storage.basePath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(basePath), 0700); err != nil {
+		return nil, err
+	}
+
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(basePath+metadataExtension, metadataBytes, 0600); err != nil {
+		return nil, err
+	}
+
+	partialPath := basePath + recordingExtension + partialExtension
+
+	file, err := os.OpenFile(partialPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &localRecordingFile{
+		File:      file,
+		finalPath: basePath + recordingExtension,
+	}, nil
+}
+
+func (storage *Local) Open(key Key) (io.ReadCloser, *Metadata, error) {
+	basePath, err := storage.basePath(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadataBytes, err := os.ReadFile(basePath + metadataExtension)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+
+		return nil, nil, err
+	}
+
+	var metadata Metadata
+
+	if err := json.Unmarshal(metadataBytes, &metadata); err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(basePath + recordingExtension)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+
+		return nil, nil, err
+	}
+
+	return file, &metadata, nil
+}
+
+func (storage *Local) basePath(key Key) (string, error) {
+	// Make sure that the key won't escape the directory
+	for _, component := range []string{key.Locator, key.HashedToken} {
+		if component == "" || component == "." || component == ".." || filepath.Base(component) != component {
+			return "", fmt.Errorf("%w: %q can't be used as a file name", ErrInvalidKey, component)
+		}
+	}
+
+	return filepath.Join(storage.dir, key.Locator, key.HashedToken), nil
+}
+
+// localRecordingFile makes the recording available under
+// its final name only after it's completely written.
+type localRecordingFile struct {
+	*os.File
+
+	finalPath string
+}
+
+func (file *localRecordingFile) Close() error {
+	if err := file.File.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), file.finalPath)
+}
@@ -0,0 +1,45 @@
+package storage_test
+
+import (
+	"github.com/cirruslabs/terminal/internal/server/storage"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+func TestLocalRecordingIsOnlyAvailableOnceFinished(t *testing.T) {
+	localStorage := storage.NewLocal(t.TempDir())
+
+	key := storage.Key{Locator: "locator", HashedToken: "hashed-token"}
+	metadata := &storage.Metadata{HashedSecrets: []string{"hashed-secret"}}
+
+	writer, err := localStorage.Create(key, metadata)
+	require.NoError(t, err)
+
+	_, err = writer.Write([]byte("recording"))
+	require.NoError(t, err)
+
+	_, _, err = localStorage.Open(key)
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, writer.Close())
+
+	reader, openedMetadata, err := localStorage.Open(key)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	recording, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, "recording", string(recording))
+	require.Equal(t, metadata, openedMetadata)
+}
+
+func TestLocalRejectsInvalidKeys(t *testing.T) {
+	localStorage := storage.NewLocal(t.TempDir())
+
+	_, err := localStorage.Create(storage.Key{Locator: "..", HashedToken: "hashed-token"}, &storage.Metadata{})
+	require.ErrorIs(t, err, storage.ErrInvalidKey)
+
+	_, _, err = localStorage.Open(storage.Key{Locator: "locator", HashedToken: "../../etc/passwd"})
+	require.ErrorIs(t, err, storage.ErrInvalidKey)
+}
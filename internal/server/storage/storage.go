@@ -0,0 +1,38 @@
+// Package storage provides the backends for storing the session recordings made by the server.
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	ErrNotFound   = errors.New("recording not found")
+	ErrInvalidKey = errors.New("invalid recording key")
+)
+
+// Key identifies a recording of a single session.
+type Key struct {
+	Locator     string
+	HashedToken string
+}
+
+// Metadata is stored alongside the recording.
+type Metadata struct {
+	// SHA-256 hashes of the secrets that grant access to the recording
+	HashedSecrets []string `json:"hashed_secrets"`
+
+	// SHA-256 hashes of the secrets that additionally grant access to the recorded terminal input
+	HashedInputSecrets []string `json:"hashed_input_secrets,omitempty"`
+}
+
+// Storage persists the session recordings.
+type Storage interface {
+	// Create starts a new recording, which should only become
+	// available through Open() after the writer is closed.
+	Create(key Key, metadata *Metadata) (io.WriteCloser, error)
+
+	// Open returns a finished recording along with its metadata
+	// or ErrNotFound if there's no such recording.
+	Open(key Key) (io.ReadCloser, *Metadata, error)
+}
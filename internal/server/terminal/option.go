@@ -7,3 +7,30 @@ func WithTrustedSecret(trustedSecret string) Option {
 		terminal.trustedSecret = trustedSecret
 	}
 }
+
+func WithReadOnlySecret(readOnlySecret string) Option {
+	return func(terminal *Terminal) {
+		terminal.readOnlySecret = readOnlySecret
+	}
+}
+
+// WithMaxSessions limits the number of the concurrent sessions on the terminal, 0 means no limit.
+func WithMaxSessions(maxSessions int) Option {
+	return func(terminal *Terminal) {
+		terminal.maxSessions = maxSessions
+	}
+}
+
+// WithHostCertSubject records the subject of the verified client
+// certificate presented by the host registering the terminal.
+func WithHostCertSubject(hostCertSubject string) Option {
+	return func(terminal *Terminal) {
+		terminal.hostCertSubject = hostCertSubject
+	}
+}
+
+func WithLocatorProof(locatorProof string) Option {
+	return func(terminal *Terminal) {
+		terminal.locatorProof = locatorProof
+	}
+}
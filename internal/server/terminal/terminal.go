@@ -1,32 +1,62 @@
 package terminal
 
 import (
+	"context"
 	"crypto/subtle"
 	"errors"
 	"fmt"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/server/filetransfer"
 	"github.com/cirruslabs/terminal/internal/server/session"
+	"github.com/cirruslabs/terminal/internal/server/tunnel"
+	"slices"
 	"sync"
 )
 
-var ErrNewSessionRefused = errors.New("refusing to register new session")
+var (
+	ErrNewSessionRefused      = errors.New("refusing to register new session")
+	ErrNewTunnelRefused       = errors.New("refusing to register new tunnel")
+	ErrNewFileTransferRefused = errors.New("refusing to register new file transfer")
+	ErrTooManySessions        = errors.New("too many sessions")
+)
 
 type Terminal struct {
 	locator string
 
-	trustedSecret string
+	trustedSecret  string
+	readOnlySecret string
+	locatorProof   string
+
+	// Subject of the client certificate presented by the host, if any
+	hostCertSubject string
+
+	hostLock         sync.Mutex
+	hostGeneration   uint64
+	detachHost       context.CancelFunc
+	hostCapabilities []string
 
 	sessionsLock   sync.RWMutex
 	sessions       map[string]*session.Session
+	tunnels        map[string]*tunnel.Tunnel
+	fileTransfers  map[string]*filetransfer.FileTransfer
 	noMoreSessions bool
+	maxSessions    int
 
-	NewSessionChan chan *session.Session
+	NewSessionChan      chan *session.Session
+	NewTunnelChan       chan *tunnel.Tunnel
+	NewFileTransferChan chan *filetransfer.FileTransfer
 }
 
 func New(locator string, opts ...Option) *Terminal {
 	terminal := &Terminal{
-		locator:        locator,
-		sessions:       make(map[string]*session.Session),
-		NewSessionChan: make(chan *session.Session),
+		locator:       locator,
+		sessions:      make(map[string]*session.Session),
+		tunnels:       make(map[string]*tunnel.Tunnel),
+		fileTransfers: make(map[string]*filetransfer.FileTransfer),
+
+		NewSessionChan:      make(chan *session.Session),
+		NewTunnelChan:       make(chan *tunnel.Tunnel),
+		NewFileTransferChan: make(chan *filetransfer.FileTransfer),
 	}
 
 	// Apply options
@@ -49,6 +79,10 @@ func (terminal *Terminal) RegisterSession(session *session.Session) error {
 		return fmt.Errorf("%w: a session with the same token already exists", ErrNewSessionRefused)
 	}
 
+	if terminal.maxSessions > 0 && len(terminal.sessions) >= terminal.maxSessions {
+		return fmt.Errorf("%w: terminal already has %d sessions", ErrTooManySessions, len(terminal.sessions))
+	}
+
 	terminal.sessions[session.Token()] = session
 
 	return nil
@@ -61,6 +95,75 @@ func (terminal *Terminal) UnregisterSession(session *session.Session) {
 	delete(terminal.sessions, session.Token())
 }
 
+func (terminal *Terminal) NumSessions() int {
+	terminal.sessionsLock.RLock()
+	defer terminal.sessionsLock.RUnlock()
+
+	return len(terminal.sessions)
+}
+
+func (terminal *Terminal) RegisterTunnel(tunnel *tunnel.Tunnel) error {
+	terminal.sessionsLock.Lock()
+	defer terminal.sessionsLock.Unlock()
+
+	if terminal.noMoreSessions {
+		return fmt.Errorf("%w: terminal is shutting down", ErrNewTunnelRefused)
+	}
+
+	if _, ok := terminal.tunnels[tunnel.Token()]; ok {
+		return fmt.Errorf("%w: a tunnel with the same token already exists", ErrNewTunnelRefused)
+	}
+
+	terminal.tunnels[tunnel.Token()] = tunnel
+
+	return nil
+}
+
+func (terminal *Terminal) UnregisterTunnel(tunnel *tunnel.Tunnel) {
+	terminal.sessionsLock.Lock()
+	defer terminal.sessionsLock.Unlock()
+
+	delete(terminal.tunnels, tunnel.Token())
+}
+
+func (terminal *Terminal) FindTunnel(token string) *tunnel.Tunnel {
+	terminal.sessionsLock.RLock()
+	defer terminal.sessionsLock.RUnlock()
+
+	return terminal.tunnels[token]
+}
+
+func (terminal *Terminal) RegisterFileTransfer(fileTransfer *filetransfer.FileTransfer) error {
+	terminal.sessionsLock.Lock()
+	defer terminal.sessionsLock.Unlock()
+
+	if terminal.noMoreSessions {
+		return fmt.Errorf("%w: terminal is shutting down", ErrNewFileTransferRefused)
+	}
+
+	if _, ok := terminal.fileTransfers[fileTransfer.Token()]; ok {
+		return fmt.Errorf("%w: a file transfer with the same token already exists", ErrNewFileTransferRefused)
+	}
+
+	terminal.fileTransfers[fileTransfer.Token()] = fileTransfer
+
+	return nil
+}
+
+func (terminal *Terminal) UnregisterFileTransfer(fileTransfer *filetransfer.FileTransfer) {
+	terminal.sessionsLock.Lock()
+	defer terminal.sessionsLock.Unlock()
+
+	delete(terminal.fileTransfers, fileTransfer.Token())
+}
+
+func (terminal *Terminal) FindFileTransfer(token string) *filetransfer.FileTransfer {
+	terminal.sessionsLock.RLock()
+	defer terminal.sessionsLock.RUnlock()
+
+	return terminal.fileTransfers[token]
+}
+
 func (terminal *Terminal) IsSecretValid(secret string) bool {
 	if terminal.trustedSecret == "" {
 		return false
@@ -69,6 +172,118 @@ func (terminal *Terminal) IsSecretValid(secret string) bool {
 	return subtle.ConstantTimeCompare([]byte(terminal.trustedSecret), []byte(secret)) == 1
 }
 
+// IsReadOnlySecretValid returns true if the secret only grants the permission to observe existing sessions.
+func (terminal *Terminal) IsReadOnlySecretValid(secret string) bool {
+	if terminal.readOnlySecret == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(terminal.readOnlySecret), []byte(secret)) == 1
+}
+
+// Secrets returns all the secrets that grant access to this terminal.
+func (terminal *Terminal) Secrets() []string {
+	var result []string
+
+	for _, secret := range []string{terminal.trustedSecret, terminal.readOnlySecret} {
+		if secret != "" {
+			result = append(result, secret)
+		}
+	}
+
+	return result
+}
+
+// HasSecrets returns true if the terminal was registered with exactly these secrets.
+func (terminal *Terminal) HasSecrets(trustedSecret string, readOnlySecret string) bool {
+	trustedSecretMatches := subtle.ConstantTimeCompare([]byte(terminal.trustedSecret), []byte(trustedSecret)) == 1
+	readOnlySecretMatches := subtle.ConstantTimeCompare([]byte(terminal.readOnlySecret), []byte(readOnlySecret)) == 1
+
+	return trustedSecretMatches && readOnlySecretMatches
+}
+
+func (terminal *Terminal) IsLocatorProofValid(locatorProof string) bool {
+	if terminal.locatorProof == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(terminal.locatorProof), []byte(locatorProof)) == 1
+}
+
+func (terminal *Terminal) LocatorProof() string {
+	return terminal.locatorProof
+}
+
+// HostCertSubject returns the subject of the verified client certificate
+// presented by the host that has registered this terminal, if any.
+func (terminal *Terminal) HostCertSubject() string {
+	return terminal.hostCertSubject
+}
+
+// Attach marks the terminal as served by the host's control channel
+// and returns a context that is cancelled once another control channel
+// takes over, along with the generation number to be passed to Detach().
+//
+// The host capabilities are the ones negotiated with the attaching Host,
+// since the Host could've been upgraded while reconnecting.
+func (terminal *Terminal) Attach(ctx context.Context, hostCapabilities []string) (context.Context, uint64) {
+	terminal.hostLock.Lock()
+	defer terminal.hostLock.Unlock()
+
+	if terminal.detachHost != nil {
+		terminal.detachHost()
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+
+	terminal.hostGeneration++
+	terminal.detachHost = cancel
+	terminal.hostCapabilities = hostCapabilities
+
+	return subCtx, terminal.hostGeneration
+}
+
+// HostCapabilities returns the capabilities negotiated with the Host.
+func (terminal *Terminal) HostCapabilities() []string {
+	terminal.hostLock.Lock()
+	defer terminal.hostLock.Unlock()
+
+	return terminal.hostCapabilities
+}
+
+// HasHostCapability returns true if the capability was negotiated with the Host.
+func (terminal *Terminal) HasHostCapability(capability string) bool {
+	return slices.Contains(terminal.HostCapabilities(), capability)
+}
+
+// Detach marks the terminal as no longer served by the control channel
+// with the specified generation, returning false if another control
+// channel has already taken over.
+func (terminal *Terminal) Detach(generation uint64) bool {
+	terminal.hostLock.Lock()
+	defer terminal.hostLock.Unlock()
+
+	if terminal.hostGeneration != generation {
+		return false
+	}
+
+	if terminal.detachHost != nil {
+		terminal.detachHost()
+		terminal.detachHost = nil
+	}
+
+	return true
+}
+
+// IsDetached returns true if no control channel has
+// attached since the specified generation was detached.
+func (terminal *Terminal) IsDetached(generation uint64) bool {
+	terminal.hostLock.Lock()
+	defer terminal.hostLock.Unlock()
+
+	return terminal.hostGeneration == generation && terminal.detachHost == nil
+}
+
 func (terminal *Terminal) FindSession(token string) *session.Session {
 	terminal.sessionsLock.RLock()
 	defer terminal.sessionsLock.RUnlock()
@@ -76,23 +291,71 @@ func (terminal *Terminal) FindSession(token string) *session.Session {
 	return terminal.sessions[token]
 }
 
+func (terminal *Terminal) FindSessionByID(id string) *session.Session {
+	terminal.sessionsLock.RLock()
+	defer terminal.sessionsLock.RUnlock()
+
+	for _, session := range terminal.sessions {
+		if session.ID() == id {
+			return session
+		}
+	}
+
+	return nil
+}
+
+func (terminal *Terminal) FindSessionByResumeToken(resumeToken string) *session.Session {
+	terminal.sessionsLock.RLock()
+	defer terminal.sessionsLock.RUnlock()
+
+	for _, session := range terminal.sessions {
+		if session.IsResumeTokenValid(resumeToken) {
+			return session
+		}
+	}
+
+	return nil
+}
+
 func (terminal *Terminal) Locator() string {
 	return terminal.locator
 }
 
+// Close terminates all the sessions on this terminal, telling
+// the Guests that the Host has disconnected, and refuses new sessions.
 func (terminal *Terminal) Close() error {
+	terminal.Terminate(&api.Termination{
+		Reason: api.Termination_HOST_DISCONNECTED,
+		Error: &api.Error{
+			Message: "lost connection with the terminal host",
+		},
+	})
+
+	return nil
+}
+
+// Terminate is like Close, but allows specifying the reason for the termination.
+func (terminal *Terminal) Terminate(termination *api.Termination) {
 	terminal.sessionsLock.Lock()
 	defer terminal.sessionsLock.Unlock()
 
 	terminal.noMoreSessions = true
 
 	for token, session := range terminal.sessions {
-		if err := session.Close(); err != nil {
-			return err
-		}
+		session.Terminate(termination)
 
 		delete(terminal.sessions, token)
 	}
 
-	return nil
+	for token, tunnel := range terminal.tunnels {
+		_ = tunnel.Close()
+
+		delete(terminal.tunnels, token)
+	}
+
+	for token, fileTransfer := range terminal.fileTransfers {
+		_ = fileTransfer.Close()
+
+		delete(terminal.fileTransfers, token)
+	}
 }
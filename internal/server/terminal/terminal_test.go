@@ -132,3 +132,46 @@ func TestNoDuplicateRegistrations(t *testing.T) {
 	require.NoError(t, terminal.RegisterSession(session))
 	require.Error(t, terminal.RegisterSession(session))
 }
+
+func TestMaxSessions(t *testing.T) {
+	terminalWithLimit := terminal.New("doesn't matter", terminal.WithMaxSessions(1))
+
+	firstSession := session.New(context.Background(), nil)
+	require.NoError(t, terminalWithLimit.RegisterSession(firstSession))
+	require.ErrorIs(t, terminalWithLimit.RegisterSession(session.New(context.Background(), nil)),
+		terminal.ErrTooManySessions)
+
+	// Sessions can be registered again once the previous ones finish
+	terminalWithLimit.UnregisterSession(firstSession)
+	require.NoError(t, terminalWithLimit.RegisterSession(session.New(context.Background(), nil)))
+}
+
+func TestNewAttachmentSupersedesTheOldOne(t *testing.T) {
+	terminal := terminal.New("doesn't matter")
+
+	firstCtx, firstGeneration := terminal.Attach(context.Background(), nil)
+	secondCtx, secondGeneration := terminal.Attach(context.Background(), nil)
+
+	// First attachment is superseded by the second one
+	require.Error(t, firstCtx.Err())
+	require.NoError(t, secondCtx.Err())
+	require.False(t, terminal.Detach(firstGeneration))
+	require.False(t, terminal.IsDetached(secondGeneration))
+
+	// Second attachment is still in charge
+	require.True(t, terminal.Detach(secondGeneration))
+	require.Error(t, secondCtx.Err())
+	require.True(t, terminal.IsDetached(secondGeneration))
+}
+
+func TestReadOnlySecretValidation(t *testing.T) {
+	sharedTerminal := terminal.New("doesn't matter", terminal.WithTrustedSecret("read-write"),
+		terminal.WithReadOnlySecret("read-only"))
+
+	assert.True(t, sharedTerminal.IsReadOnlySecretValid("read-only"))
+	assert.False(t, sharedTerminal.IsReadOnlySecretValid("read-write"))
+	assert.False(t, sharedTerminal.IsSecretValid("read-only"))
+
+	// Read-only access is disabled by default
+	assert.False(t, terminal.New("doesn't matter").IsReadOnlySecretValid(""))
+}
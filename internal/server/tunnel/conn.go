@@ -0,0 +1,115 @@
+package tunnel
+
+import (
+	"errors"
+	"github.com/cirruslabs/terminal/internal/api"
+	"net"
+	"sync"
+	"time"
+)
+
+var ErrDeadlinesNotSupported = errors.New("tunnel connections don't support deadlines")
+
+// Conn exposes the Host's side of the tunnel as a net.Conn,
+// for when the tunnel is used by the server itself.
+type Conn struct {
+	tunnel  *Tunnel
+	hostEnd *HostEnd
+
+	writeLock sync.Mutex
+
+	readLock sync.Mutex
+	pending  []byte
+}
+
+func NewConn(tunnel *Tunnel, hostEnd *HostEnd) *Conn {
+	return &Conn{
+		tunnel:  tunnel,
+		hostEnd: hostEnd,
+	}
+}
+
+// Read returns io.EOF once the Host has closed its side of the tunnel for writing.
+func (conn *Conn) Read(p []byte) (int, error) {
+	conn.readLock.Lock()
+	defer conn.readLock.Unlock()
+
+	for len(conn.pending) == 0 {
+		requestFromHost, err := conn.hostEnd.Channel.Recv()
+		if err != nil {
+			return 0, err
+		}
+
+		if data := requestFromHost.GetData(); data != nil {
+			conn.pending = data.Data
+		}
+	}
+
+	n := copy(p, conn.pending)
+	conn.pending = conn.pending[n:]
+
+	return n, nil
+}
+
+func (conn *Conn) Write(p []byte) (int, error) {
+	conn.writeLock.Lock()
+	defer conn.writeLock.Unlock()
+
+	if err := conn.hostEnd.Channel.Send(&api.HostTunnelResponse{
+		Operation: &api.HostTunnelResponse_Data{
+			Data: &api.Data{
+				Data: p,
+			},
+		},
+	}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// CloseWrite closes the tunneled connection on the Host for writing.
+func (conn *Conn) CloseWrite() error {
+	conn.writeLock.Lock()
+	defer conn.writeLock.Unlock()
+
+	return conn.hostEnd.Channel.Send(&api.HostTunnelResponse{
+		Operation: &api.HostTunnelResponse_CloseWrite{
+			CloseWrite: true,
+		},
+	})
+}
+
+func (conn *Conn) Close() error {
+	return conn.tunnel.Close()
+}
+
+func (conn *Conn) LocalAddr() net.Addr {
+	return tunnelAddr{}
+}
+
+func (conn *Conn) RemoteAddr() net.Addr {
+	return tunnelAddr{}
+}
+
+func (conn *Conn) SetDeadline(t time.Time) error {
+	return ErrDeadlinesNotSupported
+}
+
+func (conn *Conn) SetReadDeadline(t time.Time) error {
+	return ErrDeadlinesNotSupported
+}
+
+func (conn *Conn) SetWriteDeadline(t time.Time) error {
+	return ErrDeadlinesNotSupported
+}
+
+type tunnelAddr struct{}
+
+func (tunnelAddr) Network() string {
+	return "tunnel"
+}
+
+func (tunnelAddr) String() string {
+	return "tunnel"
+}
@@ -0,0 +1,97 @@
+package tunnel
+
+import (
+	"context"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/google/uuid"
+)
+
+// Tunnel is a TCP connection (or a connection to the Host's subsystem, like SFTP) requested
+// by the Guest, which is carried by the Guest's tunnel channel and the Host's tunnel data channel.
+type Tunnel struct {
+	token     string
+	port      uint32
+	subsystem string
+
+	subCtx context.Context
+	cancel context.CancelFunc
+
+	hostChan chan *HostEnd
+}
+
+// HostEnd is the Host's side of the tunnel.
+type HostEnd struct {
+	Channel api.HostService_TunnelDataChannelServer
+
+	// Set when the Host has failed to connect to the requested port or subsystem
+	Error *api.Error
+}
+
+func New(ctx context.Context, port uint32, subsystem string) *Tunnel {
+	subCtx, cancel := context.WithCancel(ctx)
+
+	return &Tunnel{
+		token:     uuid.New().String(),
+		port:      port,
+		subsystem: subsystem,
+		subCtx:    subCtx,
+		cancel:    cancel,
+		hostChan:  make(chan *HostEnd),
+	}
+}
+
+func (tunnel *Tunnel) Token() string {
+	return tunnel.token
+}
+
+func (tunnel *Tunnel) Port() uint32 {
+	return tunnel.port
+}
+
+// Subsystem returns the Host's subsystem to talk to instead of
+// connecting to the port, or an empty string if there's none.
+func (tunnel *Tunnel) Subsystem() string {
+	return tunnel.subsystem
+}
+
+func (tunnel *Tunnel) Context() context.Context {
+	return tunnel.subCtx
+}
+
+// AttachHost hands the Host's side of the tunnel over to the Guest's side
+// and waits for the tunnel to be closed, since the Host's channel is only
+// usable until its handler returns.
+func (tunnel *Tunnel) AttachHost(ctx context.Context, hostEnd *HostEnd) bool {
+	select {
+	case tunnel.hostChan <- hostEnd:
+	case <-ctx.Done():
+		return false
+	case <-tunnel.subCtx.Done():
+		return false
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-tunnel.subCtx.Done():
+	}
+
+	return true
+}
+
+// WaitForHost waits for the Host to attach its side of the tunnel.
+func (tunnel *Tunnel) WaitForHost(ctx context.Context) (*HostEnd, error) {
+	select {
+	case hostEnd := <-tunnel.hostChan:
+		return hostEnd, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-tunnel.subCtx.Done():
+		return nil, tunnel.subCtx.Err()
+	}
+}
+
+func (tunnel *Tunnel) Close() error {
+	tunnel.cancel()
+
+	return nil
+}
@@ -10,6 +10,9 @@ const (
 	locatorField = "terminal-locator"
 	tokenField   = "terminal-token-hashed"
 	secretField  = "terminal-secret-hashed"
+	subjectField = "terminal-subject"
+
+	hostCertSubjectField = "terminal-host-cert-subject"
 )
 
 func LocatorField(locator string) zap.Field {
@@ -24,6 +27,16 @@ func HashedSecretField(secret string) zap.Field {
 	return zap.String(secretField, hashed(secret))
 }
 
+// SubjectField identifies the Guest authenticated by the authenticator for auditing.
+func SubjectField(subject string) zap.Field {
+	return zap.String(subjectField, subject)
+}
+
+// HostCertSubjectField identifies the Host by its client certificate for auditing.
+func HostCertSubjectField(subject string) zap.Field {
+	return zap.String(hostCertSubjectField, subject)
+}
+
 func hashed(s string) string {
 	digest := sha256.Sum256([]byte(s))
 	return hex.EncodeToString(digest[:])
@@ -0,0 +1,245 @@
+package guest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/cirrus-ci-agent/pkg/grpchelper"
+	"github.com/cirruslabs/terminal/internal/api"
+	"google.golang.org/grpc"
+	"io"
+	"os"
+)
+
+const fileTransferChunkSize = 32 * 1024
+
+var ErrFileTransferFailed = errors.New("file transfer has failed")
+
+// FileInfo describes the file downloaded from the Host.
+type FileInfo struct {
+	Size uint64
+	Mode os.FileMode
+}
+
+// Download writes the contents of the file at the specified absolute path on the Host
+// into the writer, verifying its size and checksum. Only the WithLogger(), WithServerAddress(),
+// WithLocator() and WithSecret() options are taken into account.
+//
+// The path should be inside one of the Host's root directories and the secret should be
+// the Host's trusted secret, since the read-only secret doesn't allow file transfers.
+func Download(ctx context.Context, path string, writer io.Writer, opts ...Option) (*FileInfo, error) {
+	fileTransferChannel, cleanup, err := openFileTransferChannel(ctx, &api.FileTransfer{
+		Direction: api.FileTransfer_DOWNLOAD,
+		Path:      path,
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if err := fileTransferChannel.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	// Receive the header
+	responseFromServer, err := fileTransferChannel.Recv()
+	if err != nil {
+		return nil, err
+	}
+	header := responseFromServer.GetHeader()
+	if header == nil {
+		return nil, fmt.Errorf("%w: should've received a Header message", ErrProtocol)
+	}
+
+	checksum := sha256.New()
+
+	var received uint64
+
+	for {
+		responseFromServer, err := fileTransferChannel.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		switch op := responseFromServer.Operation.(type) {
+		case *api.GuestFileTransferResponse_Chunk:
+			received += uint64(len(op.Chunk.Data))
+			if received > header.Size {
+				return nil, fmt.Errorf("%w: received more than the announced %d bytes",
+					ErrFileTransferFailed, header.Size)
+			}
+
+			checksum.Write(op.Chunk.Data)
+
+			if _, err := writer.Write(op.Chunk.Data); err != nil {
+				return nil, err
+			}
+		case *api.GuestFileTransferResponse_Trailer:
+			if received != header.Size {
+				return nil, fmt.Errorf("%w: received %d bytes, but %d bytes were announced",
+					ErrFileTransferFailed, received, header.Size)
+			}
+
+			if !bytes.Equal(checksum.Sum(nil), op.Trailer.Sha256) {
+				return nil, fmt.Errorf("%w: checksum mismatch", ErrFileTransferFailed)
+			}
+
+			return &FileInfo{
+				Size: header.Size,
+				Mode: os.FileMode(header.Mode).Perm(),
+			}, nil
+		default:
+			return nil, fmt.Errorf("%w: should've received a Chunk or a Trailer message", ErrProtocol)
+		}
+	}
+}
+
+// Upload stores the size bytes read from the reader into the file at the specified absolute
+// path on the Host, which verifies their checksum before replacing the file. Only the
+// WithLogger(), WithServerAddress(), WithLocator() and WithSecret() options are taken into account.
+//
+// The path should be inside one of the Host's root directories and the secret should be
+// the Host's trusted secret, since the read-only secret doesn't allow file transfers.
+func Upload(
+	ctx context.Context,
+	path string,
+	reader io.Reader,
+	size uint64,
+	mode os.FileMode,
+	opts ...Option,
+) error {
+	fileTransferChannel, cleanup, err := openFileTransferChannel(ctx, &api.FileTransfer{
+		Direction: api.FileTransfer_UPLOAD,
+		Path:      path,
+		Size:      size,
+		Mode:      uint32(mode.Perm()),
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	checksum := sha256.New()
+	buf := make([]byte, fileTransferChunkSize)
+
+	for sent := uint64(0); sent < size; {
+		n, err := reader.Read(buf[:min(uint64(len(buf)), size-sent)])
+		if n > 0 {
+			checksum.Write(buf[:n])
+
+			if err := fileTransferChannel.Send(&api.GuestFileTransferRequest{
+				Operation: &api.GuestFileTransferRequest_Chunk{
+					Chunk: &api.Data{Data: append([]byte{}, buf[:n]...)},
+				},
+			}); err != nil {
+				return receiveUploadError(fileTransferChannel, err)
+			}
+
+			sent += uint64(n)
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) && sent < size {
+				return fmt.Errorf("%w: read %d bytes, but %d bytes were expected", ErrFileTransferFailed,
+					sent, size)
+			}
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+		}
+	}
+
+	sum := checksum.Sum(nil)
+
+	if err := fileTransferChannel.Send(&api.GuestFileTransferRequest{
+		Operation: &api.GuestFileTransferRequest_Trailer{
+			Trailer: &api.FileTrailer{
+				Sha256: sum,
+			},
+		},
+	}); err != nil {
+		return receiveUploadError(fileTransferChannel, err)
+	}
+
+	if err := fileTransferChannel.CloseSend(); err != nil {
+		return err
+	}
+
+	// The Host acknowledges that the file was stored by sending the checksum back
+	responseFromServer, err := fileTransferChannel.Recv()
+	if err != nil {
+		return err
+	}
+	trailer := responseFromServer.GetTrailer()
+	if trailer == nil {
+		return fmt.Errorf("%w: should've received a Trailer message", ErrProtocol)
+	}
+	if !bytes.Equal(trailer.Sha256, sum) {
+		return fmt.Errorf("%w: checksum mismatch", ErrFileTransferFailed)
+	}
+
+	return nil
+}
+
+func openFileTransferChannel(
+	ctx context.Context,
+	request *api.FileTransfer,
+	opts ...Option,
+) (api.GuestService_FileTransferChannelClient, func(), error) {
+	tg, err := newTerminalGuest(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target, transportSecurity := grpchelper.TransportSettingsAsDialOption(tg.serverAddress)
+
+	clientConn, err := grpc.Dial(target, transportSecurity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileTransferChannel, err := api.NewGuestServiceClient(clientConn).FileTransferChannel(ctx)
+	if err != nil {
+		_ = clientConn.Close()
+
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		_ = clientConn.Close()
+	}
+
+	// Send Hello
+	if err := fileTransferChannel.Send(&api.GuestFileTransferRequest{
+		Operation: &api.GuestFileTransferRequest_Hello_{
+			Hello: &api.GuestFileTransferRequest_Hello{
+				Locator:      tg.locator,
+				Secret:       tg.secret,
+				FileTransfer: request,
+			},
+		},
+	}); err != nil {
+		cleanup()
+
+		return nil, nil, err
+	}
+
+	tg.logger.Sugar().Debugf("requested file transfer of %s", request.Path)
+
+	return fileTransferChannel, cleanup, nil
+}
+
+// receiveUploadError retrieves the actual error when sending fails because
+// the server has closed the channel, e.g. when the Host has refused the upload.
+func receiveUploadError(fileTransferChannel api.GuestService_FileTransferChannelClient, sendErr error) error {
+	if !errors.Is(sendErr, io.EOF) {
+		return sendErr
+	}
+
+	for {
+		if _, err := fileTransferChannel.Recv(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package guest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/cirrus-ci-agent/pkg/grpchelper"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/compression"
+	"github.com/cirruslabs/terminal/internal/protocol"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	defaultServerAddress = "https://terminal.cirrus-ci.com:443"
+)
+
+var (
+	ErrProtocol    = errors.New("protocol error")
+	ErrSecurity    = errors.New("security violation")
+	ErrTerminated  = errors.New("session was terminated")
+	ErrNotAllowed  = errors.New("not allowed by the host")
+	ErrUnsupported = errors.New("not supported by the server or the host")
+)
+
+// TerminalGuest is a terminal session on the Host, which can be
+// read from and written to just like a regular io.ReadWriteCloser.
+type TerminalGuest struct {
+	logger *zap.Logger
+
+	serverAddress string
+
+	locator string
+	secret  string
+	token   string
+
+	requestedDimensions *api.TerminalDimensions
+	sessionID           string
+	resumeToken         string
+	command             *api.Command
+	shellOverride       *api.ShellOverride
+	errorOutput         io.Writer
+	compression         bool
+
+	clientConn      *grpc.ClientConn
+	terminalChannel api.GuestService_TerminalChannelClient
+	cancel          context.CancelFunc
+
+	readOnly bool
+
+	sendLock sync.Mutex
+
+	readLock    sync.Mutex
+	pending     []byte
+	offset      atomic.Uint64
+	termination *api.Termination
+}
+
+// New connects to the terminal with the specified locator and starts
+// a new session on it (or joins/resumes an existing one, see WithSessionID()
+// and WithResume()).
+func New(ctx context.Context, opts ...Option) (*TerminalGuest, error) {
+	tg, err := newTerminalGuest(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tg.connect(ctx); err != nil {
+		tg.Close()
+
+		return nil, err
+	}
+
+	return tg, nil
+}
+
+// newTerminalGuest applies the options and the defaults and validates the result.
+func newTerminalGuest(opts ...Option) (*TerminalGuest, error) {
+	tg := &TerminalGuest{
+		compression: true,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(tg)
+	}
+
+	// Apply defaults
+	if tg.logger == nil {
+		tg.logger = zap.NewNop()
+	}
+	if tg.serverAddress == "" {
+		tg.serverAddress = defaultServerAddress
+	}
+	if tg.errorOutput == nil {
+		tg.errorOutput = io.Discard
+	}
+
+	// Sanity check
+	if tg.locator == "" {
+		return nil, fmt.Errorf("%w: empty locator supplied", ErrSecurity)
+	}
+	if tg.secret == "" && tg.token == "" {
+		return nil, fmt.Errorf("%w: empty secret supplied", ErrSecurity)
+	}
+
+	return tg, nil
+}
+
+func (tg *TerminalGuest) connect(ctx context.Context) error {
+	target, transportSecurity := grpchelper.TransportSettingsAsDialOption(tg.serverAddress)
+
+	clientConn, err := grpc.Dial(target, transportSecurity)
+	if err != nil {
+		return err
+	}
+	tg.clientConn = clientConn
+
+	// The channel should outlive the ctx passed to New(), since it's
+	// only meant to limit the connection establishment
+	channelCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	tg.cancel = cancel
+
+	connectDone := make(chan struct{})
+	defer close(connectDone)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-connectDone:
+		}
+	}()
+
+	tg.terminalChannel, err = api.NewGuestServiceClient(clientConn).TerminalChannel(channelCtx)
+	if err != nil {
+		return err
+	}
+
+	hello := &api.GuestTerminalRequest_Hello{
+		Locator:             tg.locator,
+		Secret:              tg.secret,
+		Token:               tg.token,
+		RequestedDimensions: tg.requestedDimensions,
+		SessionId:           tg.sessionID,
+		ResumeToken:         tg.resumeToken,
+		ResumeOffset:        tg.offset.Load(),
+		Command:             tg.command,
+		ShellOverride:       tg.shellOverride,
+		ProtocolVersion:     protocol.Version,
+		Capabilities:        protocol.GuestCapabilities,
+	}
+	if tg.compression {
+		hello.AcceptedCompressions = compression.Supported
+	}
+
+	// Send Hello
+	if err := tg.terminalChannel.Send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Hello_{
+			Hello: hello,
+		},
+	}); err != nil {
+		return err
+	}
+
+	// Receive Hello
+	responseFromServer, err := tg.terminalChannel.Recv()
+	if err != nil {
+		return err
+	}
+	helloFromServer := responseFromServer.GetHello()
+	if helloFromServer == nil {
+		return fmt.Errorf("%w: should've received a Hello message", ErrProtocol)
+	}
+
+	// Servers predating the capability negotiation would
+	// silently start the default shell on the Host instead
+	if tg.command != nil && !slices.Contains(helloFromServer.HostCapabilities, protocol.CapabilityCommand) {
+		return fmt.Errorf("%w: running commands", ErrUnsupported)
+	}
+	if tg.shellOverride != nil &&
+		!slices.Contains(helloFromServer.HostCapabilities, protocol.CapabilityShellOverride) {
+		return fmt.Errorf("%w: overriding the shell and the working directory", ErrUnsupported)
+	}
+
+	tg.sessionID = helloFromServer.SessionId
+	tg.resumeToken = helloFromServer.ResumeToken
+	tg.readOnly = helloFromServer.ReadOnly
+	tg.offset.Store(helloFromServer.OutputOffset)
+
+	tg.logger.Sugar().Debugf("attached to session %s", tg.sessionID)
+
+	return nil
+}
+
+// Read reads the terminal output from the Host.
+//
+// Read returns io.EOF once the shell or the command (see WithCommand()) exits,
+// after which its exit code is available via ExitCode(). If the Host refuses
+// to start the requested shell or command, an error wrapping ErrNotAllowed
+// is returned. If the session has ended for some other reason (e.g. the Host
+// has disconnected), an error wrapping ErrTerminated is returned instead.
+func (tg *TerminalGuest) Read(p []byte) (int, error) {
+	tg.readLock.Lock()
+	defer tg.readLock.Unlock()
+
+	for len(tg.pending) == 0 {
+		if tg.termination != nil {
+			return 0, terminationError(tg.termination)
+		}
+
+		responseFromServer, err := tg.terminalChannel.Recv()
+		if err != nil {
+			return 0, err
+		}
+
+		// Ignore the messages we don't know about
+		switch op := responseFromServer.Operation.(type) {
+		case *api.GuestTerminalResponse_Output:
+			data, err := compression.Decompress(op.Output)
+			if err != nil {
+				return 0, fmt.Errorf("%w: failed to decompress the output: %v", ErrProtocol, err)
+			}
+
+			tg.pending = data
+		case *api.GuestTerminalResponse_ErrorOutput:
+			data, err := compression.Decompress(op.ErrorOutput)
+			if err != nil {
+				return 0, fmt.Errorf("%w: failed to decompress the error output: %v", ErrProtocol, err)
+			}
+
+			if _, err := tg.errorOutput.Write(data); err != nil {
+				return 0, err
+			}
+		case *api.GuestTerminalResponse_Termination:
+			tg.termination = op.Termination
+		case *api.GuestTerminalResponse_OutputTruncated:
+			// Keep the offset in sync with the server to be able to resume the session
+			tg.offset.Add(op.OutputTruncated.DroppedOutputBytes)
+
+			tg.logger.Sugar().Warnf("dropped %d bytes of the terminal output and %d bytes of the error "+
+				"output since we couldn't keep up with them", op.OutputTruncated.DroppedOutputBytes,
+				op.OutputTruncated.DroppedErrorOutputBytes)
+		case *api.GuestTerminalResponse_InputIgnored:
+			tg.logger.Sugar().Warnf("terminal input is ignored: %s", op.InputIgnored.Reason)
+		}
+	}
+
+	n := copy(p, tg.pending)
+	tg.pending = tg.pending[n:]
+	tg.offset.Add(uint64(n))
+
+	return n, nil
+}
+
+// Write writes the terminal input to the Host.
+func (tg *TerminalGuest) Write(p []byte) (int, error) {
+	if err := tg.send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_Input{
+			Input: &api.Data{
+				Data: p,
+			},
+		},
+	}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// CloseWrite signals the end of input to the command, see WithCommand().
+func (tg *TerminalGuest) CloseWrite() error {
+	return tg.send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_CloseInput{
+			CloseInput: true,
+		},
+	})
+}
+
+// Resize changes the dimensions of the terminal on the Host.
+func (tg *TerminalGuest) Resize(widthColumns, heightRows uint32) error {
+	return tg.send(&api.GuestTerminalRequest{
+		Operation: &api.GuestTerminalRequest_ChangeDimensions{
+			ChangeDimensions: &api.TerminalDimensions{
+				WidthColumns: widthColumns,
+				HeightRows:   heightRows,
+			},
+		},
+	})
+}
+
+// Close ends the session and releases the associated resources.
+func (tg *TerminalGuest) Close() error {
+	var result error
+
+	if tg.terminalChannel != nil {
+		tg.sendLock.Lock()
+		result = tg.terminalChannel.CloseSend()
+		tg.sendLock.Unlock()
+	}
+
+	if tg.cancel != nil {
+		tg.cancel()
+	}
+
+	if tg.clientConn != nil {
+		if err := tg.clientConn.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+
+	return result
+}
+
+// SessionID returns the identifier that other Guests can use to join this session.
+func (tg *TerminalGuest) SessionID() string {
+	return tg.sessionID
+}
+
+// ResumeToken returns the token that can be used to resume this
+// session after a disconnect, see WithResume().
+func (tg *TerminalGuest) ResumeToken() string {
+	return tg.resumeToken
+}
+
+// Offset returns the number of terminal output bytes received so far,
+// which can be used to resume this session after a disconnect, see WithResume().
+func (tg *TerminalGuest) Offset() uint64 {
+	return tg.offset.Load()
+}
+
+// ExitCode returns the exit code of the shell or the command (see WithCommand()),
+// and false if it hasn't exited yet. The exit code is -1 if it was killed by a signal.
+func (tg *TerminalGuest) ExitCode() (int, bool) {
+	tg.readLock.Lock()
+	defer tg.readLock.Unlock()
+
+	exitStatus := tg.termination.GetExitStatus()
+	if exitStatus == nil {
+		return 0, false
+	}
+
+	return int(exitStatus.Code), true
+}
+
+// ReadOnly returns true if the terminal input and dimension changes from this Guest are ignored.
+func (tg *TerminalGuest) ReadOnly() bool {
+	return tg.readOnly
+}
+
+func (tg *TerminalGuest) send(request *api.GuestTerminalRequest) error {
+	tg.sendLock.Lock()
+	defer tg.sendLock.Unlock()
+
+	return tg.terminalChannel.Send(request)
+}
+
+func terminationError(termination *api.Termination) error {
+	if termination.Reason == api.Termination_SHELL_EXITED {
+		return io.EOF
+	}
+
+	if termination.Reason == api.Termination_NOT_ALLOWED {
+		return fmt.Errorf("%w: %s", ErrNotAllowed, termination.GetError().GetMessage())
+	}
+
+	if message := termination.GetError().GetMessage(); message != "" {
+		return fmt.Errorf("%w: %s", ErrTerminated, message)
+	}
+
+	return fmt.Errorf("%w: %s", ErrTerminated, termination.Reason)
+}
@@ -0,0 +1,775 @@
+//go:build !windows
+// +build !windows
+
+package guest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/terminal/internal/server"
+	"github.com/cirruslabs/terminal/pkg/guest"
+	"github.com/cirruslabs/terminal/pkg/host"
+	"github.com/cirruslabs/terminal/pkg/host/recording"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGuest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	const secret = "fixed secret used in tests"
+
+	serverAddress, locator, wait := runServerAndHost(ctx, t, logger, secret)
+
+	// Start a new session
+	driver, err := guest.New(ctx,
+		guest.WithLogger(logger),
+		guest.WithServerAddress(serverAddress),
+		guest.WithLocator(locator),
+		guest.WithSecret(secret),
+		guest.WithDimensions(123, 45),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.False(t, driver.ReadOnly())
+	require.NotEmpty(t, driver.ResumeToken())
+
+	// Join the session as an observer
+	observer, err := guest.New(ctx,
+		guest.WithLogger(logger),
+		guest.WithServerAddress(serverAddress),
+		guest.WithLocator(locator),
+		guest.WithSecret("read-only "+secret),
+		guest.WithSessionID(driver.SessionID()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.True(t, observer.ReadOnly())
+	require.Empty(t, observer.ResumeToken())
+
+	waitForCanary := func(terminalGuest *guest.TerminalGuest, canary string) {
+		buf := bytes.NewBuffer([]byte{})
+		chunk := make([]byte, 4096)
+
+		for !strings.Contains(buf.String(), canary) {
+			n, err := terminalGuest.Read(chunk)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			buf.Write(chunk[:n])
+		}
+	}
+
+	// Both guests should see the output of the driver's commands
+	_, err = fmt.Fprintln(driver, "echo -e \"cols\\nlines\" | tput -S")
+	require.NoError(t, err)
+	waitForCanary(driver, "123\r\n45")
+	waitForCanary(observer, "123\r\n45")
+
+	require.NoError(t, driver.Resize(111, 22))
+
+	_, err = fmt.Fprintln(driver, "echo -e \"cols\\nlines\" | tput -S")
+	require.NoError(t, err)
+	waitForCanary(driver, "111\r\n22")
+	waitForCanary(observer, "111\r\n22")
+
+	require.NoError(t, observer.Close())
+	require.NoError(t, driver.Close())
+
+	cancel()
+	wait()
+}
+
+func TestCommand(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	const secret = "fixed secret used in tests"
+
+	serverAddress, locator, wait := runServerAndHost(ctx, t, logger, secret,
+		host.WithAllowedCommands("/bin/sh"))
+
+	errorOutput := bytes.NewBuffer([]byte{})
+
+	terminalGuest, err := guest.New(ctx,
+		guest.WithLogger(logger),
+		guest.WithServerAddress(serverAddress),
+		guest.WithLocator(locator),
+		guest.WithSecret(secret),
+		guest.WithCommand("/bin/sh", []string{"-c", "cat; echo oops >&2; exit 3"}, false),
+		guest.WithErrorOutput(errorOutput),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, exited := terminalGuest.ExitCode()
+	require.False(t, exited)
+
+	// The command should receive the input until it's closed
+	_, err = fmt.Fprint(terminalGuest, "hello")
+	require.NoError(t, err)
+	require.NoError(t, terminalGuest.CloseWrite())
+
+	output, err := io.ReadAll(terminalGuest)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(output))
+	require.Equal(t, "oops\n", errorOutput.String())
+
+	exitCode, exited := terminalGuest.ExitCode()
+	require.True(t, exited)
+	require.Equal(t, 3, exitCode)
+
+	require.NoError(t, terminalGuest.Close())
+
+	// Commands outside of the Host's allowlist are refused once it's configured
+	terminalGuest, err = guest.New(ctx,
+		guest.WithLogger(logger),
+		guest.WithServerAddress(serverAddress),
+		guest.WithLocator(locator),
+		guest.WithSecret(secret),
+		guest.WithCommand("/bin/bash", []string{"-c", "echo escaped"}, false),
+	)
+	require.NoError(t, err)
+
+	output, err = io.ReadAll(terminalGuest)
+	require.ErrorIs(t, err, guest.ErrNotAllowed)
+	require.NotContains(t, string(output), "escaped")
+
+	_, exited = terminalGuest.ExitCode()
+	require.False(t, exited)
+
+	require.NoError(t, terminalGuest.Close())
+
+	cancel()
+	wait()
+}
+
+func TestLargeOutputWithFlowControl(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	const secret = "fixed secret used in tests"
+
+	serverAddress, locator, wait := runServerAndHost(ctx, t, logger, secret)
+
+	// Produce way more output than the server's flow control window
+	const outputSize = 8 * 1024 * 1024
+
+	terminalGuest, err := guest.New(ctx,
+		guest.WithLogger(logger),
+		guest.WithServerAddress(serverAddress),
+		guest.WithLocator(locator),
+		guest.WithSecret(secret),
+		guest.WithCommand("head", []string{"-c", strconv.Itoa(outputSize), "/dev/zero"}, false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := io.Copy(io.Discard, terminalGuest)
+	require.NoError(t, err)
+	require.EqualValues(t, outputSize, n)
+
+	require.NoError(t, terminalGuest.Close())
+
+	cancel()
+	wait()
+}
+
+func TestCompressedOutput(t *testing.T) {
+	for _, compression := range []bool{true, false} {
+		t.Run(fmt.Sprintf("compression=%t", compression), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			logger, err := zap.NewDevelopment()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				_ = logger.Sync()
+			}()
+
+			const secret = "fixed secret used in tests"
+
+			serverAddress, locator, wait := runServerAndHost(ctx, t, logger, secret)
+
+			// Run through a PTY to exercise the output coalescing too
+			terminalGuest, err := guest.New(ctx,
+				guest.WithLogger(logger),
+				guest.WithServerAddress(serverAddress),
+				guest.WithLocator(locator),
+				guest.WithSecret(secret),
+				guest.WithCommand("seq", []string{"1", "20000"}, true),
+				guest.WithCompression(compression),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			output, err := io.ReadAll(terminalGuest)
+			require.NoError(t, err)
+
+			var expectedOutput strings.Builder
+
+			for i := 1; i <= 20000; i++ {
+				expectedOutput.WriteString(strconv.Itoa(i) + "\r\n")
+			}
+
+			require.Equal(t, expectedOutput.String(), string(output))
+
+			require.NoError(t, terminalGuest.Close())
+
+			cancel()
+			wait()
+		})
+	}
+}
+
+func TestWorkingDirectory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	const secret = "fixed secret used in tests"
+
+	workingDirectory, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	serverAddress, locator, wait := runServerAndHost(ctx, t, logger, secret,
+		host.WithAllowedWorkingDirectories(workingDirectory))
+
+	runPwd := func(opts ...guest.Option) (string, error) {
+		terminalGuest, err := guest.New(ctx, append([]guest.Option{
+			guest.WithLogger(logger),
+			guest.WithServerAddress(serverAddress),
+			guest.WithLocator(locator),
+			guest.WithSecret(secret),
+			guest.WithCommand("pwd", nil, false),
+		}, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer terminalGuest.Close()
+
+		output, err := io.ReadAll(terminalGuest)
+		if err != nil {
+			return "", err
+		}
+
+		exitCode, exited := terminalGuest.ExitCode()
+		require.True(t, exited)
+		require.Equal(t, 0, exitCode)
+
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	// Directory from the allowlist
+	output, err := runPwd(guest.WithWorkingDirectory(workingDirectory))
+	require.NoError(t, err)
+	require.Equal(t, workingDirectory, output)
+
+	// Directory outside of the allowlist
+	_, err = runPwd(guest.WithWorkingDirectory(filepath.Dir(workingDirectory)))
+	require.ErrorIs(t, err, guest.ErrNotAllowed)
+
+	// Shells can't be overridden by default
+	_, err = runPwd(guest.WithShell("/bin/sh"))
+	require.ErrorIs(t, err, guest.ErrNotAllowed)
+
+	cancel()
+	wait()
+}
+
+func TestShellExitStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	const secret = "fixed secret used in tests"
+
+	serverAddress, locator, wait := runServerAndHost(ctx, t, logger, secret)
+
+	terminalGuest, err := guest.New(ctx,
+		guest.WithLogger(logger),
+		guest.WithServerAddress(serverAddress),
+		guest.WithLocator(locator),
+		guest.WithSecret(secret),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fmt.Fprintln(terminalGuest, "exit 7")
+	require.NoError(t, err)
+
+	// Shell's exit is reported as the end of the output
+	_, err = io.ReadAll(terminalGuest)
+	require.NoError(t, err)
+
+	exitCode, exited := terminalGuest.ExitCode()
+	require.True(t, exited)
+	require.Equal(t, 7, exitCode)
+
+	require.NoError(t, terminalGuest.Close())
+
+	cancel()
+	wait()
+}
+
+func TestRecording(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	const secret = "fixed secret used in tests"
+
+	recordingDir := t.TempDir()
+
+	serverAddress, locator, wait := runServerAndHost(ctx, t, logger, secret,
+		host.WithRecorder(recording.NewAsciicastRecorder(recordingDir, true)))
+
+	terminalGuest, err := guest.New(ctx,
+		guest.WithLogger(logger),
+		guest.WithServerAddress(serverAddress),
+		guest.WithLocator(locator),
+		guest.WithSecret(secret),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.NoError(t, terminalGuest.Resize(100, 30))
+
+	_, err = fmt.Fprintln(terminalGuest, "echo recorded-$((6*7)); exit")
+	require.NoError(t, err)
+
+	_, err = io.ReadAll(terminalGuest)
+	require.NoError(t, err)
+	require.NoError(t, terminalGuest.Close())
+
+	cancel()
+	wait()
+
+	// The session should be recorded into a single file
+	recordings, err := filepath.Glob(filepath.Join(recordingDir, "*.cast"))
+	require.NoError(t, err)
+	require.Len(t, recordings, 1)
+
+	cast, err := os.ReadFile(recordings[0])
+	require.NoError(t, err)
+	require.Contains(t, string(cast), `"version":2`)
+	require.Contains(t, string(cast), `"i","echo recorded-$((6*7)); exit\n"]`)
+	require.Contains(t, string(cast), `"r","100x30"]`)
+	require.Contains(t, string(cast), "recorded-42")
+}
+
+func TestIdleTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	const secret = "fixed secret used in tests"
+
+	serverAddress, locator, wait := runServerAndHost(ctx, t, logger, secret,
+		host.WithIdleTimeout(3*time.Second),
+		host.WithTimeoutWarning(2*time.Second),
+	)
+
+	terminalGuest, err := guest.New(ctx,
+		guest.WithLogger(logger),
+		guest.WithServerAddress(serverAddress),
+		guest.WithLocator(locator),
+		guest.WithSecret(secret),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The session should be closed with a warning since we don't send anything
+	output, err := io.ReadAll(terminalGuest)
+	require.ErrorIs(t, err, guest.ErrTerminated)
+	require.Contains(t, err.Error(), "idle")
+	require.Contains(t, string(output), "will be closed in 2s due to inactivity")
+
+	_, exited := terminalGuest.ExitCode()
+	require.False(t, exited)
+
+	require.NoError(t, terminalGuest.Close())
+
+	cancel()
+	wait()
+}
+
+func TestTunnel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	const secret = "fixed secret used in tests"
+
+	// Run an echo server on the Host's side
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	port := uint16(listener.Addr().(*net.TCPAddr).Port) //nolint:gosec // TCP ports always fit
+
+	serverAddress, locator, wait := runServerAndHost(ctx, t, logger, secret,
+		host.WithAllowedTunnelPorts(port))
+
+	newTunnel := func(port uint16, secret string) (*guest.Tunnel, error) {
+		return guest.NewTunnel(ctx, port,
+			guest.WithLogger(logger),
+			guest.WithServerAddress(serverAddress),
+			guest.WithLocator(locator),
+			guest.WithSecret(secret),
+		)
+	}
+
+	tunnel, err := newTunnel(port, secret)
+	require.NoError(t, err)
+
+	_, err = fmt.Fprint(tunnel, "hello through the tunnel")
+	require.NoError(t, err)
+	require.NoError(t, tunnel.CloseWrite())
+
+	output, err := io.ReadAll(tunnel)
+	require.NoError(t, err)
+	require.Equal(t, "hello through the tunnel", string(output))
+	require.NoError(t, tunnel.Close())
+
+	// Ports outside of the allowlist
+	_, err = newTunnel(port+1, secret)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	// Read-only secret
+	_, err = newTunnel(port, "read-only "+secret)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	cancel()
+	wait()
+}
+
+func TestFileTransfer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	const secret = "fixed secret used in tests"
+
+	rootDir := t.TempDir()
+	outsideDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(outsideDir, "secret.txt"), []byte("secret"), 0600))
+	require.NoError(t, os.Symlink(outsideDir, filepath.Join(rootDir, "escape")))
+
+	serverAddress, locator, wait := runServerAndHost(ctx, t, logger, secret,
+		host.WithFileTransferRoots(rootDir))
+
+	opts := func(secret string) []guest.Option {
+		return []guest.Option{
+			guest.WithLogger(logger),
+			guest.WithServerAddress(serverAddress),
+			guest.WithLocator(locator),
+			guest.WithSecret(secret),
+		}
+	}
+
+	// Upload a file that spans multiple chunks and download it back
+	contents := bytes.Repeat([]byte("0123456789abcdef"), 10000)
+	path := filepath.Join(rootDir, "file.bin")
+
+	require.NoError(t, guest.Upload(ctx, path, bytes.NewReader(contents), uint64(len(contents)), 0600,
+		opts(secret)...))
+
+	onDisk, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, contents, onDisk)
+
+	var downloaded bytes.Buffer
+
+	fileInfo, err := guest.Download(ctx, path, &downloaded, opts(secret)...)
+	require.NoError(t, err)
+	require.Equal(t, contents, downloaded.Bytes())
+	require.EqualValues(t, len(contents), fileInfo.Size)
+	require.Equal(t, os.FileMode(0600), fileInfo.Mode)
+
+	// Incomplete upload doesn't replace the file
+	err = guest.Upload(ctx, path, bytes.NewReader([]byte("short")), 5, 0600, opts(secret)...)
+	require.NoError(t, err)
+	err = guest.Upload(ctx, path, bytes.NewReader(contents), uint64(len(contents)+1), 0600, opts(secret)...)
+	require.ErrorIs(t, err, guest.ErrFileTransferFailed)
+
+	onDisk, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "short", string(onDisk))
+
+	// Paths outside of the root directories, including the ones escaping through a symbolic link
+	_, err = guest.Download(ctx, filepath.Join(outsideDir, "secret.txt"), io.Discard, opts(secret)...)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = guest.Download(ctx, filepath.Join(rootDir, "escape", "secret.txt"), io.Discard, opts(secret)...)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	err = guest.Upload(ctx, filepath.Join(rootDir, "escape", "new.txt"), bytes.NewReader([]byte("x")), 1, 0600,
+		opts(secret)...)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.NoFileExists(t, filepath.Join(outsideDir, "new.txt"))
+
+	// Read-only secret
+	_, err = guest.Download(ctx, path, io.Discard, opts("read-only "+secret)...)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	cancel()
+	wait()
+}
+
+func TestSFTP(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	const secret = "fixed secret used in tests"
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("existing"), 0600))
+
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0600))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(dir, "escape.txt")))
+
+	serverAddress, locator, wait := runServerAndHost(ctx, t, logger, secret,
+		host.WithSFTP(true), host.WithFileTransferRoots(dir))
+
+	sftpClient, err := guest.NewSFTPClient(ctx,
+		guest.WithLogger(logger),
+		guest.WithServerAddress(serverAddress),
+		guest.WithLocator(locator),
+		guest.WithSecret(secret),
+	)
+	require.NoError(t, err)
+
+	file, err := sftpClient.Create(filepath.Join(dir, "new.txt"))
+	require.NoError(t, err)
+	_, err = fmt.Fprint(file, "hello over SFTP")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	entries, err := sftpClient.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	require.ElementsMatch(t, []string{"existing.txt", "escape.txt", "new.txt"}, names)
+
+	onDisk, err := os.ReadFile(filepath.Join(dir, "new.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "hello over SFTP", string(onDisk))
+
+	// Paths outside of the root directories are refused, even through the symbolic links
+	_, err = sftpClient.Create(filepath.Join(outside, "new.txt"))
+	require.Error(t, err)
+	_, err = sftpClient.Open(filepath.Join(dir, "escape.txt"))
+	require.Error(t, err)
+	_, err = sftpClient.ReadDir(outside)
+	require.Error(t, err)
+	require.Error(t, sftpClient.Symlink(outside, filepath.Join(dir, "link")))
+	require.Error(t, sftpClient.Rename(filepath.Join(dir, "new.txt"), filepath.Join(outside, "moved.txt")))
+	require.NoFileExists(t, filepath.Join(outside, "new.txt"))
+	require.NoFileExists(t, filepath.Join(outside, "moved.txt"))
+
+	require.NoError(t, sftpClient.Close())
+
+	cancel()
+	wait()
+
+	// SFTP is disabled by default
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	serverAddress, locator, wait = runServerAndHost(ctx, t, logger, secret)
+
+	_, err = guest.NewSFTPClient(ctx,
+		guest.WithLogger(logger),
+		guest.WithServerAddress(serverAddress),
+		guest.WithLocator(locator),
+		guest.WithSecret(secret),
+	)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	cancel()
+	wait()
+}
+
+// runServerAndHost runs the terminal server and the terminal host with the specified
+// secret, and returns the server's address, the terminal's locator and a function
+// that waits for both to finish once the ctx is cancelled.
+func runServerAndHost(
+	ctx context.Context,
+	t *testing.T,
+	logger *zap.Logger,
+	secret string,
+	hostOpts ...host.Option,
+) (string, string, func()) {
+	t.Helper()
+
+	// Run terminal server
+	terminalServer, err := server.New(server.WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminalServerErrChan := make(chan error)
+	go func() {
+		terminalServerErrChan <- terminalServer.Run(ctx)
+	}()
+
+	serverAddress := "http://" + terminalServer.Addresses()[0]
+
+	// Run terminal host
+	locatorChan := make(chan string, 1)
+
+	terminalHost, err := host.New(append([]host.Option{
+		host.WithLogger(logger),
+		host.WithServerAddress(serverAddress),
+		host.WithTrustedSecret(secret),
+		host.WithReadOnlySecret("read-only " + secret),
+		host.WithLocatorCallback(func(locator string) error {
+			locatorChan <- locator
+			return nil
+		}),
+	}, hostOpts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminalHostErrChan := make(chan error)
+	go func() {
+		terminalHostErrChan <- terminalHost.Run(ctx)
+	}()
+
+	var locator string
+	select {
+	case locator = <-locatorChan:
+	case err := <-terminalHostErrChan:
+		t.Fatal(err)
+	}
+
+	return serverAddress, locator, func() {
+		if err := <-terminalHostErrChan; err != nil && !errors.Is(err, context.Canceled) {
+			t.Fatal(err)
+		}
+
+		if err := <-terminalServerErrChan; err != nil && !errors.Is(err, context.Canceled) {
+			t.Fatal(err)
+		}
+	}
+}
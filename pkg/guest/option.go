@@ -0,0 +1,121 @@
+package guest
+
+import (
+	"github.com/cirruslabs/terminal/internal/api"
+	"go.uber.org/zap"
+	"io"
+)
+
+type Option func(*TerminalGuest)
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(tg *TerminalGuest) {
+		tg.logger = logger
+	}
+}
+
+func WithServerAddress(address string) Option {
+	return func(tg *TerminalGuest) {
+		tg.serverAddress = address
+	}
+}
+
+func WithLocator(locator string) Option {
+	return func(tg *TerminalGuest) {
+		tg.locator = locator
+	}
+}
+
+func WithSecret(secret string) Option {
+	return func(tg *TerminalGuest) {
+		tg.secret = secret
+	}
+}
+
+// WithToken authenticates using the alternative credentials (e.g. a signed JWT)
+// instead of the secret, which requires the server to have an authenticator configured.
+func WithToken(token string) Option {
+	return func(tg *TerminalGuest) {
+		tg.token = token
+	}
+}
+
+// WithDimensions specifies the initial dimensions of the terminal to be created on the Host.
+func WithDimensions(widthColumns, heightRows uint32) Option {
+	return func(tg *TerminalGuest) {
+		tg.requestedDimensions = &api.TerminalDimensions{
+			WidthColumns: widthColumns,
+			HeightRows:   heightRows,
+		}
+	}
+}
+
+// WithSessionID joins an existing session as an observer instead of creating a new one.
+func WithSessionID(sessionID string) Option {
+	return func(tg *TerminalGuest) {
+		tg.sessionID = sessionID
+	}
+}
+
+// WithResume resumes the session previously started by this Guest,
+// replaying the terminal output starting from the specified offset.
+func WithResume(resumeToken string, offset uint64) Option {
+	return func(tg *TerminalGuest) {
+		tg.resumeToken = resumeToken
+		tg.offset.Store(offset)
+	}
+}
+
+// WithCommand runs the specified command on the Host instead of the shell,
+// optionally allocating a PTY for it. Without the PTY, the command's
+// standard error is delivered separately, see WithErrorOutput().
+func WithCommand(name string, args []string, pty bool) Option {
+	return func(tg *TerminalGuest) {
+		tg.command = &api.Command{
+			Name: name,
+			Args: args,
+			Pty:  pty,
+		}
+	}
+}
+
+// WithShell asks the Host to run the specified shell instead of its default one,
+// the Host only allows the shells from its allowlist.
+func WithShell(shell string, args ...string) Option {
+	return func(tg *TerminalGuest) {
+		if tg.shellOverride == nil {
+			tg.shellOverride = &api.ShellOverride{}
+		}
+
+		tg.shellOverride.Shell = shell
+		tg.shellOverride.Args = args
+	}
+}
+
+// WithWorkingDirectory asks the Host to start the shell or the command in the
+// specified directory, the Host only allows the directories from its allowlist.
+func WithWorkingDirectory(workingDirectory string) Option {
+	return func(tg *TerminalGuest) {
+		if tg.shellOverride == nil {
+			tg.shellOverride = &api.ShellOverride{}
+		}
+
+		tg.shellOverride.WorkingDirectory = workingDirectory
+	}
+}
+
+// WithErrorOutput specifies where to write the standard error of the command,
+// by default it's discarded.
+func WithErrorOutput(errorOutput io.Writer) Option {
+	return func(tg *TerminalGuest) {
+		tg.errorOutput = errorOutput
+	}
+}
+
+// WithCompression lets the server compress the terminal output sent to this Guest,
+// by default it's enabled.
+func WithCompression(compression bool) Option {
+	return func(tg *TerminalGuest) {
+		tg.compression = compression
+	}
+}
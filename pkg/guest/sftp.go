@@ -0,0 +1,63 @@
+package guest
+
+import (
+	"context"
+	"github.com/pkg/sftp"
+)
+
+const subsystemSFTP = "sftp"
+
+// SFTPClient is an SFTP client talking to the Host's SFTP server through the terminal server.
+type SFTPClient struct {
+	*sftp.Client
+
+	tunnel *Tunnel
+}
+
+// NewSFTPClient starts an SFTP session with the Host of the terminal with the specified
+// locator. Only the WithLogger(), WithServerAddress(), WithLocator() and WithSecret()
+// options are taken into account.
+//
+// The Host should have SFTP enabled and the secret should be the Host's
+// trusted secret, since the read-only secret doesn't allow tunneling.
+func NewSFTPClient(ctx context.Context, opts ...Option) (*SFTPClient, error) {
+	tunnel, err := newTunnel(ctx, 0, subsystemSFTP, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := sftp.NewClientPipe(tunnel, &tunnelWriter{tunnel: tunnel})
+	if err != nil {
+		_ = tunnel.Close()
+
+		return nil, err
+	}
+
+	return &SFTPClient{
+		Client: client,
+		tunnel: tunnel,
+	}, nil
+}
+
+// Close ends the SFTP session and releases the associated resources.
+func (client *SFTPClient) Close() error {
+	err := client.Client.Close()
+
+	_ = client.tunnel.Close()
+
+	return err
+}
+
+// tunnelWriter closes the tunnel for writing when closed,
+// which lets the Host's SFTP server know that we're done.
+type tunnelWriter struct {
+	tunnel *Tunnel
+}
+
+func (writer *tunnelWriter) Write(p []byte) (int, error) {
+	return writer.tunnel.Write(p)
+}
+
+func (writer *tunnelWriter) Close() error {
+	return writer.tunnel.CloseWrite()
+}
@@ -0,0 +1,186 @@
+package guest
+
+import (
+	"context"
+	"fmt"
+	"github.com/cirruslabs/cirrus-ci-agent/pkg/grpchelper"
+	"github.com/cirruslabs/terminal/internal/api"
+	"google.golang.org/grpc"
+	"io"
+	"sync"
+)
+
+// Tunnel is a TCP connection to a port on the Host's loopback interface
+// proxied through the terminal server, which can be read from and written
+// to just like a regular io.ReadWriteCloser.
+type Tunnel struct {
+	clientConn    *grpc.ClientConn
+	tunnelChannel api.GuestService_TunnelChannelClient
+	cancel        context.CancelFunc
+
+	sendLock sync.Mutex
+
+	readLock sync.Mutex
+	pending  []byte
+	eof      bool
+}
+
+// NewTunnel asks the Host of the terminal with the specified locator to connect
+// to the port on its loopback interface. Only the WithLogger(), WithServerAddress(),
+// WithLocator() and WithSecret() options are taken into account.
+//
+// The port should be in the Host's allowlist and the secret should be
+// the Host's trusted secret, since the read-only secret doesn't allow tunneling.
+func NewTunnel(ctx context.Context, port uint16, opts ...Option) (*Tunnel, error) {
+	return newTunnel(ctx, port, "", opts...)
+}
+
+func newTunnel(ctx context.Context, port uint16, subsystem string, opts ...Option) (*Tunnel, error) {
+	tg, err := newTerminalGuest(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tunnel := &Tunnel{}
+
+	if err := tunnel.connect(ctx, tg, port, subsystem); err != nil {
+		tunnel.Close()
+
+		return nil, err
+	}
+
+	if subsystem != "" {
+		tg.logger.Sugar().Debugf("established tunnel to subsystem %s", subsystem)
+	} else {
+		tg.logger.Sugar().Debugf("established tunnel to port %d", port)
+	}
+
+	return tunnel, nil
+}
+
+func (tunnel *Tunnel) connect(ctx context.Context, tg *TerminalGuest, port uint16, subsystem string) error {
+	target, transportSecurity := grpchelper.TransportSettingsAsDialOption(tg.serverAddress)
+
+	clientConn, err := grpc.Dial(target, transportSecurity)
+	if err != nil {
+		return err
+	}
+	tunnel.clientConn = clientConn
+
+	// The channel should outlive the ctx passed to NewTunnel(),
+	// since it's only meant to limit the connection establishment
+	channelCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	tunnel.cancel = cancel
+
+	connectDone := make(chan struct{})
+	defer close(connectDone)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-connectDone:
+		}
+	}()
+
+	tunnel.tunnelChannel, err = api.NewGuestServiceClient(clientConn).TunnelChannel(channelCtx)
+	if err != nil {
+		return err
+	}
+
+	// Send Hello
+	if err := tunnel.tunnelChannel.Send(&api.GuestTunnelRequest{
+		Operation: &api.GuestTunnelRequest_Hello_{
+			Hello: &api.GuestTunnelRequest_Hello{
+				Locator:   tg.locator,
+				Secret:    tg.secret,
+				Port:      uint32(port),
+				Subsystem: subsystem,
+			},
+		},
+	}); err != nil {
+		return err
+	}
+
+	// Receive Hello, which is only sent once the Host has connected to the port or started the subsystem
+	responseFromServer, err := tunnel.tunnelChannel.Recv()
+	if err != nil {
+		return err
+	}
+	if responseFromServer.GetHello() == nil {
+		return fmt.Errorf("%w: should've received a Hello message", ErrProtocol)
+	}
+
+	return nil
+}
+
+// Read reads the data from the tunneled connection, returning
+// io.EOF once the other side has closed it for writing.
+func (tunnel *Tunnel) Read(p []byte) (int, error) {
+	tunnel.readLock.Lock()
+	defer tunnel.readLock.Unlock()
+
+	for len(tunnel.pending) == 0 {
+		if tunnel.eof {
+			return 0, io.EOF
+		}
+
+		responseFromServer, err := tunnel.tunnelChannel.Recv()
+		if err != nil {
+			return 0, err
+		}
+
+		// Ignore the messages we don't know about
+		switch op := responseFromServer.Operation.(type) {
+		case *api.GuestTunnelResponse_Data:
+			tunnel.pending = op.Data.Data
+		case *api.GuestTunnelResponse_CloseWrite:
+			tunnel.eof = true
+		}
+	}
+
+	n := copy(p, tunnel.pending)
+	tunnel.pending = tunnel.pending[n:]
+
+	return n, nil
+}
+
+// Write writes the data to the tunneled connection.
+func (tunnel *Tunnel) Write(p []byte) (int, error) {
+	tunnel.sendLock.Lock()
+	defer tunnel.sendLock.Unlock()
+
+	if err := tunnel.tunnelChannel.Send(&api.GuestTunnelRequest{
+		Operation: &api.GuestTunnelRequest_Data{
+			Data: &api.Data{
+				Data: p,
+			},
+		},
+	}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// CloseWrite closes the tunneled connection for writing, while
+// the data from the other side can still be read.
+func (tunnel *Tunnel) CloseWrite() error {
+	tunnel.sendLock.Lock()
+	defer tunnel.sendLock.Unlock()
+
+	return tunnel.tunnelChannel.CloseSend()
+}
+
+// Close closes the tunneled connection and releases the associated resources.
+func (tunnel *Tunnel) Close() error {
+	if tunnel.cancel != nil {
+		tunnel.cancel()
+	}
+
+	if tunnel.clientConn != nil {
+		return tunnel.clientConn.Close()
+	}
+
+	return nil
+}
@@ -1,6 +1,10 @@
 package host
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/cirruslabs/terminal/pkg/host/recording"
 	"github.com/cirruslabs/terminal/pkg/host/session"
 	"go.uber.org/zap"
 	"sync"
@@ -12,12 +16,44 @@ type TerminalHost struct {
 
 	shellEnv []string
 
-	serverAddress string
+	shell                     string
+	shellArgs                 []string
+	loginShell                bool
+	workingDirectory          string
+	term                      string
+	allowedShells             []string
+	allowedCommands           []string
+	allowedWorkingDirectories []string
 
-	trustedSecret string
+	allowedTunnelPorts []uint16
+
+	fileTransferRoots []string
+
+	sftp bool
+
+	outputCoalescingDelay time.Duration
+	compression           bool
+
+	serverAddress     string
+	clientCertificate *tls.Certificate
+	rootCAs           *x509.CertPool
+
+	trustedSecret  string
+	readOnlySecret string
 
 	locatorCallback LocatorCallback
 
+	reconnectBackOff backoff.BackOff
+
+	recorder recording.Recorder
+
+	idleTimeout        time.Duration
+	maxSessionDuration time.Duration
+	timeoutWarning     time.Duration
+
+	locator      string
+	locatorProof string
+
 	lastConnectionMtx sync.Mutex
 	lastConnection    time.Time
 
@@ -0,0 +1,359 @@
+//go:build !windows
+// +build !windows
+
+package host
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/terminal/internal/api"
+	"go.uber.org/zap"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const fileTransferChunkSize = 32 * 1024
+
+var (
+	ErrFileTransferNotAllowed = errors.New("file transfer is not allowed")
+	ErrFileTransferFailed     = errors.New("file transfer has failed")
+)
+
+// serveFileTransfer sends the requested file to the Guest or stores
+// the file sent by the Guest, depending on the transfer direction.
+func (th *TerminalHost) serveFileTransfer(
+	ctx context.Context,
+	hostService api.HostServiceClient,
+	locator string,
+	fileTransferRequest *api.HostControlResponse_FileTransferRequest,
+) {
+	request := fileTransferRequest.FileTransfer
+	if request == nil {
+		request = &api.FileTransfer{}
+	}
+
+	logger := th.logger.With(zap.Stringer("direction", request.Direction), zap.String("path", request.Path))
+
+	fileTransferCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var file *os.File
+	var path string
+	var openErr error
+
+	switch request.Direction {
+	case api.FileTransfer_DOWNLOAD:
+		file, openErr = th.openDownload(request.Path)
+	case api.FileTransfer_UPLOAD:
+		file, path, openErr = th.openUpload(request.Path)
+	default:
+		openErr = fmt.Errorf("%w: unsupported direction %s", ErrFileTransferNotAllowed, request.Direction)
+	}
+	if openErr != nil {
+		logger.Warn("refusing the file transfer", zap.Error(openErr))
+	} else {
+		defer file.Close()
+
+		if request.Direction == api.FileTransfer_UPLOAD {
+			// Only left behind when the upload didn't succeed
+			defer os.Remove(file.Name())
+		}
+	}
+
+	fileTransferChannel, err := hostService.FileTransferDataChannel(fileTransferCtx)
+	if err != nil {
+		logger.Warn("failed to open file transfer data channel", zap.Error(err))
+		return
+	}
+
+	hello := &api.HostFileTransferRequest_Hello{
+		Locator: locator,
+		Token:   fileTransferRequest.Token,
+	}
+
+	if openErr != nil {
+		hello.Error = &api.Error{
+			Message: openErr.Error(),
+		}
+	}
+
+	if err := fileTransferChannel.Send(&api.HostFileTransferRequest{
+		Operation: &api.HostFileTransferRequest_Hello_{
+			Hello: hello,
+		},
+	}); err != nil {
+		logger.Warn("failed to send Hello message via file transfer data channel", zap.Error(err))
+		return
+	}
+
+	var transferErr error
+
+	if openErr == nil {
+		if request.Direction == api.FileTransfer_DOWNLOAD {
+			transferErr = sendFile(fileTransferChannel, file)
+		} else {
+			transferErr = receiveFile(fileTransferChannel, file, path, request)
+		}
+
+		if transferErr != nil {
+			logger.Warn("file transfer has failed", zap.Error(transferErr))
+
+			_ = fileTransferChannel.Send(&api.HostFileTransferRequest{
+				Operation: &api.HostFileTransferRequest_Error{
+					Error: &api.Error{
+						Message: transferErr.Error(),
+					},
+				},
+			})
+		} else {
+			logger.Info("file transfer was completed")
+		}
+	}
+
+	// Wait for the server to deliver everything we've sent and close the channel
+	_ = fileTransferChannel.CloseSend()
+
+	for {
+		if _, err := fileTransferChannel.Recv(); err != nil {
+			return
+		}
+	}
+}
+
+// openDownload opens the file requested by the Guest, as long
+// as it's located inside of one of the root directories.
+func (th *TerminalHost) openDownload(path string) (*os.File, error) {
+	if !filepath.IsAbs(path) {
+		return nil, fmt.Errorf("%w: %q is not an absolute path", ErrFileTransferNotAllowed, path)
+	}
+
+	// Resolve the symbolic links so that they can't be used to escape the root directories
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to resolve %q: %v", ErrFileTransferNotAllowed, path, err)
+	}
+
+	if !th.isInFileTransferRoots(resolved) {
+		return nil, fmt.Errorf("%w: %q is outside of the host's root directories", ErrFileTransferNotAllowed, path)
+	}
+
+	file, err := os.Open(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	if !fileInfo.Mode().IsRegular() {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("%w: %q is not a regular file", ErrFileTransferNotAllowed, path)
+	}
+
+	return file, nil
+}
+
+// openUpload creates a temporary file next to the path requested by the Guest, as long
+// as it's located inside of one of the root directories. The temporary file is renamed
+// to the requested path once the whole file is received and its checksum is verified.
+func (th *TerminalHost) openUpload(path string) (*os.File, string, error) {
+	if !filepath.IsAbs(path) || strings.HasSuffix(path, string(filepath.Separator)) {
+		return nil, "", fmt.Errorf("%w: %q is not an absolute path to a file", ErrFileTransferNotAllowed, path)
+	}
+
+	// The file itself might not exist yet, so only resolve the directory it's in
+	resolvedDir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: failed to resolve %q: %v", ErrFileTransferNotAllowed,
+			filepath.Dir(path), err)
+	}
+
+	resolved := filepath.Join(resolvedDir, filepath.Base(path))
+
+	if !th.isInFileTransferRoots(resolved) {
+		return nil, "", fmt.Errorf("%w: %q is outside of the host's root directories", ErrFileTransferNotAllowed, path)
+	}
+
+	// Only regular files can be overwritten, note that the rename
+	// replaces a symbolic link instead of following it
+	if fileInfo, err := os.Lstat(resolved); err == nil && !fileInfo.Mode().IsRegular() &&
+		fileInfo.Mode()&os.ModeSymlink == 0 {
+		return nil, "", fmt.Errorf("%w: %q is not a regular file", ErrFileTransferNotAllowed, path)
+	}
+
+	file, err := os.CreateTemp(resolvedDir, "."+filepath.Base(resolved)+".*.tmp")
+	if err != nil {
+		return nil, "", err
+	}
+
+	return file, resolved, nil
+}
+
+func (th *TerminalHost) isInFileTransferRoots(path string) bool {
+	for _, root := range th.fileTransferRoots {
+		resolvedRoot, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			resolvedRoot = filepath.Clean(root)
+		}
+
+		rel, err := filepath.Rel(resolvedRoot, path)
+		if err != nil {
+			continue
+		}
+
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sendFile sends the file preceded by its size and followed by its checksum.
+func sendFile(fileTransferChannel api.HostService_FileTransferDataChannelClient, file *os.File) error {
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := fileTransferChannel.Send(&api.HostFileTransferRequest{
+		Operation: &api.HostFileTransferRequest_Header{
+			Header: &api.FileHeader{
+				Size: uint64(fileInfo.Size()),
+				Mode: uint32(fileInfo.Mode().Perm()),
+			},
+		},
+	}); err != nil {
+		return err
+	}
+
+	checksum := sha256.New()
+	buf := make([]byte, fileTransferChunkSize)
+
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			checksum.Write(buf[:n])
+
+			if err := fileTransferChannel.Send(&api.HostFileTransferRequest{
+				Operation: &api.HostFileTransferRequest_Chunk{
+					Chunk: &api.Data{Data: append([]byte{}, buf[:n]...)},
+				},
+			}); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return err
+		}
+	}
+
+	return fileTransferChannel.Send(&api.HostFileTransferRequest{
+		Operation: &api.HostFileTransferRequest_Trailer{
+			Trailer: &api.FileTrailer{
+				Sha256: checksum.Sum(nil),
+			},
+		},
+	})
+}
+
+// receiveFile writes the chunks into the temporary file and renames it to the
+// requested path once the size and the checksum match the ones sent by the Guest.
+func receiveFile(
+	fileTransferChannel api.HostService_FileTransferDataChannelClient,
+	file *os.File,
+	path string,
+	request *api.FileTransfer,
+) error {
+	checksum := sha256.New()
+
+	var written uint64
+
+	for {
+		responseFromServer, err := fileTransferChannel.Recv()
+		if err != nil {
+			return err
+		}
+
+		switch op := responseFromServer.Operation.(type) {
+		case *api.HostFileTransferResponse_Chunk:
+			written += uint64(len(op.Chunk.Data))
+			if written > request.Size {
+				return fmt.Errorf("%w: received more than the announced %d bytes", ErrFileTransferFailed,
+					request.Size)
+			}
+
+			if _, err := file.Write(op.Chunk.Data); err != nil {
+				return err
+			}
+
+			checksum.Write(op.Chunk.Data)
+		case *api.HostFileTransferResponse_Trailer:
+			return storeFile(fileTransferChannel, file, path, request, written, checksum, op.Trailer)
+		default:
+			return fmt.Errorf("%w: should've received a Chunk or a Trailer message", ErrProtocol)
+		}
+	}
+}
+
+func storeFile(
+	fileTransferChannel api.HostService_FileTransferDataChannelClient,
+	file *os.File,
+	path string,
+	request *api.FileTransfer,
+	written uint64,
+	checksum hash.Hash,
+	trailer *api.FileTrailer,
+) error {
+	if written != request.Size {
+		return fmt.Errorf("%w: received %d bytes, but %d bytes were announced", ErrFileTransferFailed,
+			written, request.Size)
+	}
+
+	sum := checksum.Sum(nil)
+
+	if !bytes.Equal(sum, trailer.Sha256) {
+		return fmt.Errorf("%w: checksum mismatch", ErrFileTransferFailed)
+	}
+
+	mode := os.FileMode(request.Mode).Perm()
+	if mode == 0 {
+		mode = 0644
+	}
+
+	if err := file.Chmod(mode); err != nil {
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return err
+	}
+
+	// Acknowledge that the file was stored
+	return fileTransferChannel.Send(&api.HostFileTransferRequest{
+		Operation: &api.HostFileTransferRequest_Trailer{
+			Trailer: &api.FileTrailer{
+				Sha256: sum,
+			},
+		},
+	})
+}
@@ -5,29 +5,40 @@ package host
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"github.com/cenkalti/backoff/v4"
 	"github.com/cirruslabs/cirrus-ci-agent/pkg/grpchelper"
 	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/compression"
+	"github.com/cirruslabs/terminal/internal/protocol"
 	"github.com/cirruslabs/terminal/pkg/host/session"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 	"sync"
 	"time"
 )
 
 const (
-	defaultServerAddress = "https://terminal.cirrus-ci.com:443"
+	defaultServerAddress         = "https://terminal.cirrus-ci.com:443"
+	defaultOutputCoalescingDelay = 5 * time.Millisecond
 )
 
 var (
 	ErrProtocol = errors.New("protocol error")
 	ErrSecurity = errors.New("security violation")
+	ErrDraining = errors.New("terminal server is draining")
 )
 
 func New(opts ...Option) (*TerminalHost, error) {
 	client := &TerminalHost{
-		sessions: make(map[string]*session.Session),
+		sessions:              make(map[string]*session.Session),
+		outputCoalescingDelay: defaultOutputCoalescingDelay,
+		compression:           true,
 	}
 
 	// Apply options
@@ -42,35 +53,129 @@ func New(opts ...Option) (*TerminalHost, error) {
 	if client.serverAddress == "" {
 		client.serverAddress = defaultServerAddress
 	}
+	if client.reconnectBackOff == nil {
+		reconnectBackOff := backoff.NewExponentialBackOff()
+		reconnectBackOff.MaxElapsedTime = 0
+		client.reconnectBackOff = reconnectBackOff
+	}
 
 	// Sanity check
 	if client.trustedSecret == "" {
 		return nil, fmt.Errorf("%w: empty trusted secret supplied", ErrSecurity)
 	}
+	if client.readOnlySecret == client.trustedSecret {
+		return nil, fmt.Errorf("%w: read-only secret should differ from the trusted secret", ErrSecurity)
+	}
+	if _, insecure := grpchelper.TransportSettings(client.serverAddress); insecure &&
+		(client.clientCertificate != nil || client.rootCAs != nil) {
+		return nil, fmt.Errorf("%w: client certificate and root CAs require a secure server address", ErrSecurity)
+	}
+	if client.sftp && len(client.fileTransferRoots) == 0 {
+		return nil, fmt.Errorf("%w: SFTP requires the file transfer root directories", ErrSecurity)
+	}
 
 	return client, nil
 }
 
 func (th *TerminalHost) Run(ctx context.Context) error {
-	target, transportSecurity := grpchelper.TransportSettingsAsDialOption(th.serverAddress)
+	target, transportSecurity := th.transportSettings()
 
+	// gRPC re-dials the underlying transport on its own, so the same
+	// connection is re-used for all the control channel (re-)connections
 	clientConn, err := grpc.Dial(target, transportSecurity)
 	if err != nil {
 		return err
 	}
+	defer clientConn.Close()
 
 	hostService := api.NewHostServiceClient(clientConn)
 
-	controlChannel, err := hostService.ControlChannel(ctx)
+	// Sessions are intentionally not tied to a particular control channel
+	// so that they survive the reconnects
+	var sessionWG sync.WaitGroup
+	defer sessionWG.Wait()
+
+	var attempt int
+
+	return backoff.RetryNotify(func() error {
+		lastConnection := th.LastConnection()
+
+		err := th.runControlChannel(ctx, hostService, &sessionWG)
+
+		// Only count the consecutive failed attempts
+		if th.LastConnection().Equal(lastConnection) {
+			attempt++
+		} else {
+			attempt = 1
+		}
+
+		if isPermanent(err) {
+			return backoff.Permanent(err)
+		}
+
+		return err
+	}, backoff.WithContext(th.reconnectBackOff, ctx), func(err error, delay time.Duration) {
+		th.logger.Sugar().Warnf("control channel failed on attempt %d: %v, reconnecting in %s",
+			attempt, err, delay)
+	})
+}
+
+// isPermanent returns true for the errors returned by the server that won't go away
+// on their own by re-connecting (e.g. an invalid client certificate or an outdated
+// protocol version), so that the Host fails instead of re-connecting forever.
+func isPermanent(err error) bool {
+	switch status.Code(err) {
+	case codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition, codes.InvalidArgument:
+		return true
+	default:
+		return false
+	}
+}
+
+// transportSettings works just like grpchelper.TransportSettingsAsDialOption(),
+// but additionally presents the client certificate and trusts the root CAs, if any.
+func (th *TerminalHost) transportSettings() (string, grpc.DialOption) {
+	if th.clientCertificate == nil && th.rootCAs == nil {
+		return grpchelper.TransportSettingsAsDialOption(th.serverAddress)
+	}
+
+	target, _ := grpchelper.TransportSettings(th.serverAddress)
+
+	tlsConfig := &tls.Config{
+		RootCAs:    th.rootCAs,
+		MinVersion: tls.VersionTLS13,
+	}
+
+	if th.clientCertificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*th.clientCertificate}
+	}
+
+	return target, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+}
+
+func (th *TerminalHost) runControlChannel(
+	ctx context.Context,
+	hostService api.HostServiceClient,
+	sessionWG *sync.WaitGroup,
+) error {
+	controlChannelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	controlChannel, err := hostService.ControlChannel(controlChannelCtx)
 	if err != nil {
 		return err
 	}
 
-	// Send Hello
+	// Send Hello, trying to re-claim the previously assigned locator (if any)
 	err = controlChannel.Send(&api.HostControlRequest{
 		Operation: &api.HostControlRequest_Hello_{
 			Hello: &api.HostControlRequest_Hello{
-				TrustedSecret: th.trustedSecret,
+				TrustedSecret:   th.trustedSecret,
+				ReadOnlySecret:  th.readOnlySecret,
+				Locator:         th.locator,
+				LocatorProof:    th.locatorProof,
+				ProtocolVersion: protocol.Version,
+				Capabilities:    protocol.HostCapabilities,
 			},
 		},
 	})
@@ -90,16 +195,25 @@ func (th *TerminalHost) Run(ctx context.Context) error {
 
 	if th.locatorCallback != nil {
 		if err := th.locatorCallback(helloFromServer.Locator); err != nil {
-			return err
+			return backoff.Permanent(err)
 		}
 	}
 
+	th.locator = helloFromServer.Locator
+	th.locatorProof = helloFromServer.LocatorProof
+
 	th.lastConnectionMtx.Lock()
 	th.lastConnection = time.Now()
 	th.lastConnectionMtx.Unlock()
 
-	var sessionWG sync.WaitGroup
-	defer sessionWG.Wait()
+	th.logger.Sugar().Infof("connected to the terminal server, assigned locator: %s", helloFromServer.Locator)
+
+	// Older servers don't announce their version and capabilities
+	th.logger.Sugar().Debugf("terminal server implements protocol version %d with capabilities %v",
+		helloFromServer.ProtocolVersion, helloFromServer.Capabilities)
+
+	// Start counting from the initial interval on the next disconnect
+	th.reconnectBackOff.Reset()
 
 	// Loop waiting for the data channels to be requested
 	for {
@@ -112,22 +226,78 @@ func (th *TerminalHost) Run(ctx context.Context) error {
 			// appropriate, e.g. to check for the exact
 			// error in tests
 			case <-ctx.Done():
-				return ctx.Err()
+				return backoff.Permanent(ctx.Err())
 			default:
 				return err
 			}
 		}
+		if drain := controlFromServer.GetDrain(); drain != nil {
+			// The sessions keep running on the draining server,
+			// so only the control channel needs to re-connect
+			th.logger.Sugar().Infof("terminal server is draining (%s), re-connecting", drain.Reason)
+
+			return fmt.Errorf("%w: %s", ErrDraining, drain.Reason)
+		}
+
+		if tunnelRequest := controlFromServer.GetTunnelRequest(); tunnelRequest != nil {
+			sessionWG.Add(1)
+
+			go func() {
+				th.serveTunnel(ctx, hostService, helloFromServer.Locator, tunnelRequest)
+				sessionWG.Done()
+			}()
+
+			continue
+		}
+
+		if fileTransferRequest := controlFromServer.GetFileTransferRequest(); fileTransferRequest != nil {
+			sessionWG.Add(1)
+
+			go func() {
+				th.serveFileTransfer(ctx, hostService, helloFromServer.Locator, fileTransferRequest)
+				sessionWG.Done()
+			}()
+
+			continue
+		}
+
 		dataChannelRequest := controlFromServer.GetDataChannelRequest()
 		if dataChannelRequest == nil {
-			return fmt.Errorf("%w: should've received a DataChannelRequest message", ErrProtocol)
+			return fmt.Errorf("%w: should've received a DataChannelRequest, a TunnelRequest, "+
+				"a FileTransferRequest or a Drain message", ErrProtocol)
+		}
+
+		sessionOpts := []session.Option{
+			session.WithIdleTimeout(th.idleTimeout),
+			session.WithMaxSessionDuration(th.maxSessionDuration),
+			session.WithTimeoutWarning(th.timeoutWarning),
+			session.WithShell(th.shell, th.shellArgs...),
+			session.WithLoginShell(th.loginShell),
+			session.WithWorkingDirectory(th.workingDirectory),
+			session.WithTerm(th.term),
+			session.WithAllowedShells(th.allowedShells...),
+			session.WithAllowedCommands(th.allowedCommands...),
+			session.WithAllowedWorkingDirectories(th.allowedWorkingDirectories...),
+			session.WithFlowControlWindow(dataChannelRequest.FlowControlWindow),
+			session.WithOutputCoalescingDelay(th.outputCoalescingDelay),
 		}
 
-		session := session.New(th.logger, dataChannelRequest.Token, th.shellEnv)
+		if th.compression {
+			sessionOpts = append(sessionOpts,
+				session.WithCompression(compression.Negotiate(dataChannelRequest.SupportedCompressions)))
+		}
+
+		if th.recorder != nil {
+			sessionOpts = append(sessionOpts, session.WithRecorder(th.recorder))
+		}
+
+		session := session.New(th.logger, dataChannelRequest.Token, th.shellEnv, sessionOpts...)
 		sessionWG.Add(1)
 
 		go func() {
 			th.registerSession(session)
-			session.Run(ctx, hostService, helloFromServer.Locator, dataChannelRequest.RequestedDimensions)
+			session.Run(ctx, hostService, helloFromServer.Locator, dataChannelRequest.RequestedDimensions,
+				dataChannelRequest.Command, dataChannelRequest.ShellOverride)
 			th.unregisterSession(session)
 			sessionWG.Done()
 		}()
@@ -135,8 +305,8 @@ func (th *TerminalHost) Run(ctx context.Context) error {
 }
 
 func (th *TerminalHost) LastConnection() time.Time {
-	th.sessionsLock.Lock()
-	defer th.sessionsLock.Unlock()
+	th.lastConnectionMtx.Lock()
+	defer th.lastConnectionMtx.Unlock()
 
 	return th.lastConnection
 }
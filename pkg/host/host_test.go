@@ -7,6 +7,7 @@ package host
 import (
 	"github.com/cirruslabs/terminal/pkg/host/session"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"testing"
 	"time"
@@ -31,3 +32,11 @@ func TestNumSessionsNormalAndFunc(t *testing.T) {
 		return session.LastActivity().Equal(uninitializedTime)
 	}))
 }
+
+func TestSFTPRequiresFileTransferRoots(t *testing.T) {
+	_, err := New(WithTrustedSecret("doesn't matter"), WithSFTP(true))
+	require.ErrorIs(t, err, ErrSecurity)
+
+	_, err = New(WithTrustedSecret("doesn't matter"), WithSFTP(true), WithFileTransferRoots(t.TempDir()))
+	require.NoError(t, err)
+}
@@ -1,7 +1,12 @@
 package host
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/cirruslabs/terminal/pkg/host/recording"
 	"go.uber.org/zap"
+	"time"
 )
 
 type Option func(*TerminalHost)
@@ -20,12 +25,36 @@ func WithServerAddress(address string) Option {
 	}
 }
 
+// WithClientCertificate presents the client certificate to the server,
+// which may require one from the hosts. Requires a secure server address.
+func WithClientCertificate(clientCertificate tls.Certificate) Option {
+	return func(th *TerminalHost) {
+		th.clientCertificate = &clientCertificate
+	}
+}
+
+// WithRootCAs verifies the server's certificate against the specified CAs instead
+// of the system ones (e.g. for a private server). Requires a secure server address.
+func WithRootCAs(rootCAs *x509.CertPool) Option {
+	return func(th *TerminalHost) {
+		th.rootCAs = rootCAs
+	}
+}
+
 func WithTrustedSecret(trustedSecret string) Option {
 	return func(th *TerminalHost) {
 		th.trustedSecret = trustedSecret
 	}
 }
 
+// WithReadOnlySecret specifies an additional secret that only
+// allows the Guests to observe the existing sessions.
+func WithReadOnlySecret(readOnlySecret string) Option {
+	return func(th *TerminalHost) {
+		th.readOnlySecret = readOnlySecret
+	}
+}
+
 func WithLocatorCallback(locatorCallback LocatorCallback) Option {
 	return func(th *TerminalHost) {
 		th.locatorCallback = locatorCallback
@@ -37,3 +66,151 @@ func WithShellEnv(shellEnv []string) Option {
 		th.shellEnv = shellEnv
 	}
 }
+
+// WithReconnectBackOff configures the policy used when re-establishing
+// the control channel after a server restart or a network failure.
+//
+// By default, the host reconnects forever using a jittered exponential
+// backoff, use backoff.StopBackOff to disable reconnects completely.
+func WithReconnectBackOff(reconnectBackOff backoff.BackOff) Option {
+	return func(th *TerminalHost) {
+		th.reconnectBackOff = reconnectBackOff
+	}
+}
+
+// WithRecorder records every terminal session, see recording.NewAsciicastRecorder().
+func WithRecorder(recorder recording.Recorder) Option {
+	return func(th *TerminalHost) {
+		th.recorder = recorder
+	}
+}
+
+// WithIdleTimeout closes the sessions that haven't received
+// any input from the Guests for the specified duration.
+//
+// Zero (the default) means no idle timeout.
+func WithIdleTimeout(idleTimeout time.Duration) Option {
+	return func(th *TerminalHost) {
+		th.idleTimeout = idleTimeout
+	}
+}
+
+// WithMaxSessionDuration closes the sessions once they have been
+// running for the specified duration, regardless of their activity.
+//
+// Zero (the default) means that the sessions can run forever.
+func WithMaxSessionDuration(maxSessionDuration time.Duration) Option {
+	return func(th *TerminalHost) {
+		th.maxSessionDuration = maxSessionDuration
+	}
+}
+
+// WithTimeoutWarning writes a warning banner into the terminal the specified
+// duration before closing the session due to one of the timeouts above.
+func WithTimeoutWarning(timeoutWarning time.Duration) Option {
+	return func(th *TerminalHost) {
+		th.timeoutWarning = timeoutWarning
+	}
+}
+
+// WithShell runs the specified shell with the specified arguments instead
+// of the auto-detected one (Zsh on macOS, Bash or /bin/sh otherwise).
+func WithShell(shell string, args ...string) Option {
+	return func(th *TerminalHost) {
+		th.shell = shell
+		th.shellArgs = args
+	}
+}
+
+// WithLoginShell starts the shell as a login shell, so that it reads the profile files.
+func WithLoginShell(loginShell bool) Option {
+	return func(th *TerminalHost) {
+		th.loginShell = loginShell
+	}
+}
+
+// WithWorkingDirectory starts the shell and the commands in the specified
+// directory (e.g. the CI task's clone directory) instead of the current one.
+func WithWorkingDirectory(workingDirectory string) Option {
+	return func(th *TerminalHost) {
+		th.workingDirectory = workingDirectory
+	}
+}
+
+// WithTerm specifies the TERM value for the shell and the commands attached to a PTY,
+// by default it's "xterm".
+func WithTerm(term string) Option {
+	return func(th *TerminalHost) {
+		th.term = term
+	}
+}
+
+// WithAllowedShells lets the Guests run one of the specified shells instead
+// of the default one. By default, the Guests can't override the shell.
+func WithAllowedShells(allowedShells ...string) Option {
+	return func(th *TerminalHost) {
+		th.allowedShells = allowedShells
+	}
+}
+
+// WithAllowedCommands restricts the commands that the Guests can run instead of the shell
+// to the specified ones (matched by the exact name or path the Guest requests). By default,
+// the Guests can run any command, just like they can in the shell. Note that allowing
+// a shell (e.g. "/bin/sh") effectively allows any command to be run through it.
+func WithAllowedCommands(allowedCommands ...string) Option {
+	return func(th *TerminalHost) {
+		th.allowedCommands = allowedCommands
+	}
+}
+
+// WithAllowedWorkingDirectories lets the Guests start the sessions in one of the
+// specified directories or their subdirectories. By default, the Guests can't
+// override the working directory.
+func WithAllowedWorkingDirectories(allowedWorkingDirectories ...string) Option {
+	return func(th *TerminalHost) {
+		th.allowedWorkingDirectories = allowedWorkingDirectories
+	}
+}
+
+// WithAllowedTunnelPorts lets the Guests open the tunnels to the specified ports
+// on the Host's loopback interface. By default, no tunnels are allowed.
+func WithAllowedTunnelPorts(allowedTunnelPorts ...uint16) Option {
+	return func(th *TerminalHost) {
+		th.allowedTunnelPorts = allowedTunnelPorts
+	}
+}
+
+// WithFileTransferRoots lets the Guests download the files from and upload the files
+// to the specified directories and their subdirectories. By default, no file transfers
+// are allowed.
+func WithFileTransferRoots(fileTransferRoots ...string) Option {
+	return func(th *TerminalHost) {
+		th.fileTransferRoots = fileTransferRoots
+	}
+}
+
+// WithSFTP lets the Guests browse the file transfer root directories (see WithFileTransferRoots),
+// which are required, over SFTP with the permissions of the user the Host is running as, e.g. through
+// the terminal server's SSH gateway. By default, SFTP is disabled.
+func WithSFTP(sftp bool) Option {
+	return func(th *TerminalHost) {
+		th.sftp = sftp
+	}
+}
+
+// WithOutputCoalescingDelay specifies how long to wait for more terminal output before
+// sending it to the server, so that the programs that print character by character don't
+// produce a message per character. By default, it's 5 milliseconds, zero disables coalescing.
+func WithOutputCoalescingDelay(delay time.Duration) Option {
+	return func(th *TerminalHost) {
+		th.outputCoalescingDelay = delay
+	}
+}
+
+// WithCompression compresses the terminal output sent to the server when the server
+// supports it (which is negotiated for each session). By default, compression is enabled.
+func WithCompression(compression bool) Option {
+	return func(th *TerminalHost) {
+		th.compression = compression
+	}
+}
@@ -0,0 +1,65 @@
+package recording
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/terminal/internal/asciicast"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var ErrInvalidToken = errors.New("invalid session token")
+
+// AsciicastRecorder records each session into a separate asciicast v2 file
+// (https://docs.asciinema.org/manual/asciicast/v2/) named after the session's
+// token in the specified directory.
+type AsciicastRecorder struct {
+	dir         string
+	recordInput bool
+}
+
+// NewAsciicastRecorder creates a recorder that stores the recordings in the dir,
+// the terminal input is only recorded when recordInput is true, since it may
+// contain sensitive data like passwords.
+func NewAsciicastRecorder(dir string, recordInput bool) *AsciicastRecorder {
+	return &AsciicastRecorder{
+		dir:         dir,
+		recordInput: recordInput,
+	}
+}
+
+func (recorder *AsciicastRecorder) NewRecording(token string, header Header) (Recording, error) {
+	// The token comes from the server, so make sure it won't escape the directory
+	if token == "" || token == "." || token == ".." || filepath.Base(token) != token {
+		return nil, fmt.Errorf("%w: %q can't be used as a file name", ErrInvalidToken, token)
+	}
+
+	file, err := os.OpenFile(filepath.Join(recorder.dir, token+".cast"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	recording, err := NewAsciicastRecording(file, header, recorder.recordInput)
+	if err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	return recording, nil
+}
+
+// AsciicastRecording writes the session events into the writer in asciicast v2 format.
+type AsciicastRecording struct {
+	*asciicast.Writer
+}
+
+func NewAsciicastRecording(writer io.WriteCloser, header Header, recordInput bool) (*AsciicastRecording, error) {
+	asciicastWriter, err := asciicast.NewWriter(writer, asciicast.Header(header), recordInput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AsciicastRecording{Writer: asciicastWriter}, nil
+}
@@ -0,0 +1,107 @@
+package recording_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/cirruslabs/terminal/pkg/host/recording"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestAsciicastRecording(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+
+	asciicastRecording, err := recording.NewAsciicastRecording(nopWriteCloser{buf}, recording.Header{
+		WidthColumns: 80,
+		HeightRows:   24,
+		Timestamp:    time.Unix(1700000000, 0),
+		Env:          map[string]string{"TERM": "xterm"},
+	}, false)
+	require.NoError(t, err)
+
+	// Multi-byte character split across the chunks
+	snowman := []byte("☃")
+	require.NoError(t, asciicastRecording.Output(append([]byte("hello "), snowman[:1]...)))
+	require.NoError(t, asciicastRecording.Output(snowman[1:]))
+	require.NoError(t, asciicastRecording.Input([]byte("secret")))
+	require.NoError(t, asciicastRecording.Resize(100, 50))
+	require.NoError(t, asciicastRecording.Close())
+
+	lines := readLines(t, buf)
+	require.Len(t, lines, 4)
+
+	var header map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &header))
+	require.Equal(t, map[string]interface{}{
+		"version":   2.0,
+		"width":     80.0,
+		"height":    24.0,
+		"timestamp": 1700000000.0,
+		"env":       map[string]interface{}{"TERM": "xterm"},
+	}, header)
+
+	// Input is not recorded unless explicitly requested
+	require.Equal(t, []string{"o", "hello "}, eventCodeAndData(t, lines[1]))
+	require.Equal(t, []string{"o", "☃"}, eventCodeAndData(t, lines[2]))
+	require.Equal(t, []string{"r", "100x50"}, eventCodeAndData(t, lines[3]))
+}
+
+func TestAsciicastRecorder(t *testing.T) {
+	dir := t.TempDir()
+
+	recorder := recording.NewAsciicastRecorder(dir, true)
+
+	_, err := recorder.NewRecording("../escape", recording.Header{})
+	require.ErrorIs(t, err, recording.ErrInvalidToken)
+
+	asciicastRecording, err := recorder.NewRecording("token", recording.Header{})
+	require.NoError(t, err)
+	require.NoError(t, asciicastRecording.Input([]byte("ls\r")))
+	require.NoError(t, asciicastRecording.Close())
+
+	file, err := os.Open(filepath.Join(dir, "token.cast"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	lines := readLines(t, file)
+	require.Len(t, lines, 2)
+	require.Equal(t, []string{"i", "ls\r"}, eventCodeAndData(t, lines[1]))
+}
+
+func readLines(t *testing.T, reader io.Reader) []string {
+	t.Helper()
+
+	var result []string
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		result = append(result, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+
+	return result
+}
+
+func eventCodeAndData(t *testing.T, line string) []string {
+	t.Helper()
+
+	var event []interface{}
+	require.NoError(t, json.Unmarshal([]byte(line), &event))
+	require.Len(t, event, 3)
+	require.IsType(t, 0.0, event[0])
+
+	return []string{event[1].(string), event[2].(string)}
+}
@@ -0,0 +1,34 @@
+// Package recording provides a way to record the terminal sessions
+// running on the Host, e.g. for post-mortem analysis.
+package recording
+
+import "time"
+
+// Recorder creates a new Recording for each terminal session.
+type Recorder interface {
+	NewRecording(token string, header Header) (Recording, error)
+}
+
+// Header describes the recorded terminal session.
+type Header struct {
+	WidthColumns uint32
+	HeightRows   uint32
+	Timestamp    time.Time
+	Env          map[string]string
+}
+
+// Recording receives the terminal session events as they happen,
+// its methods may be called concurrently.
+type Recording interface {
+	// Output records a chunk of the terminal output
+	Output(data []byte) error
+
+	// Input records a chunk of the terminal input
+	Input(data []byte) error
+
+	// Resize records the change of the terminal dimensions
+	Resize(widthColumns, heightRows uint32) error
+
+	// Close is called once the session ends
+	Close() error
+}
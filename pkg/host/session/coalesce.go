@@ -0,0 +1,106 @@
+//go:build !windows
+// +build !windows
+
+package session
+
+import (
+	"io"
+	"time"
+)
+
+const (
+	coalescingReadSize = 4096
+	coalescingMaxSize  = 32 * 1024
+)
+
+// readChunks reads from the reader in a separate goroutine and sends each chunk read
+// to the returned channel, so that the chunks can be coalesced while the next read is
+// blocked. The channel is closed once the read fails, the error (io.EOF included) is
+// stored in readErr beforehand.
+func readChunks(reader io.Reader, done <-chan struct{}, readErr *error) <-chan []byte {
+	chunks := make(chan []byte)
+
+	go func() {
+		defer close(chunks)
+
+		for {
+			buf := make([]byte, coalescingReadSize)
+
+			n, err := reader.Read(buf)
+			if n > 0 {
+				select {
+				case chunks <- buf[:n]:
+				case <-done:
+					return
+				}
+			}
+			if err != nil {
+				*readErr = err
+
+				return
+			}
+		}
+	}()
+
+	return chunks
+}
+
+// coalesce passes the chunks to the flush callback, but instead of flushing each chunk
+// right away, it waits up to the delay for more chunks to arrive, unless coalescingMaxSize
+// bytes are pending already. So the programs that print character by character produce
+// fewer messages, while a steady stream of output is flushed without any delay.
+func coalesce(chunks <-chan []byte, delay time.Duration, flush func([]byte) error) error {
+	var pending []byte
+
+	var timer *time.Timer
+	var timerChan <-chan time.Time
+
+	stopTimer := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, timerChan = nil, nil
+		}
+	}
+	defer stopTimer()
+
+	flushPending := func() error {
+		stopTimer()
+
+		if len(pending) == 0 {
+			return nil
+		}
+
+		data := pending
+		pending = nil
+
+		return flush(data)
+	}
+
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				return flushPending()
+			}
+
+			pending = append(pending, chunk...)
+
+			if delay <= 0 || len(pending) >= coalescingMaxSize {
+				if err := flushPending(); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if timer == nil {
+				timer = time.NewTimer(delay)
+				timerChan = timer.C
+			}
+		case <-timerChan:
+			if err := flushPending(); err != nil {
+				return err
+			}
+		}
+	}
+}
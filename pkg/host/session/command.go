@@ -0,0 +1,316 @@
+//go:build !windows
+// +build !windows
+
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/compression"
+	"golang.org/x/sys/unix"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Exit code used by the shells when the command cannot be found or executed.
+const exitCodeCannotExecute = 127
+
+// runCommand runs the command with its standard output and standard error
+// connected to the data channel as separate streams and reports its exit status.
+func (session *Session) runCommand(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	dataChannel api.HostService_DataChannelClient,
+	command *api.Command,
+	shellConfig *shellConfig,
+) {
+	cmd := exec.CommandContext(ctx, command.Name, command.Args...)
+	session.prepareCmd(cmd, shellConfig)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		session.reportStartFailure(dataChannel, err)
+		return
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		session.reportStartFailure(dataChannel, err)
+		return
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		session.reportStartFailure(dataChannel, err)
+		return
+	}
+
+	if err := cmd.Start(); err != nil {
+		session.reportStartFailure(dataChannel, err)
+		return
+	}
+
+	session.logger.Debugf("started command process with PID %d", cmd.Process.Pid)
+
+	go session.enforceTimeouts(ctx, cancel, dataChannel, false)
+
+	// Receive the input from the server and write it to the command
+	go func() {
+		defer cancel()
+		defer session.window.close()
+		session.ioToCommand(dataChannel, stdin)
+	}()
+
+	// Read the output from the command and send it to the server
+	var outputWG sync.WaitGroup
+
+	outputWG.Add(2)
+
+	go func() {
+		defer outputWG.Done()
+
+		session.ioFromCommand(dataChannel, stdout, func(data []byte) *api.HostDataRequest {
+			return &api.HostDataRequest{
+				Operation: &api.HostDataRequest_Output{Output: &api.Data{Data: data}},
+			}
+		})
+	}()
+
+	go func() {
+		defer outputWG.Done()
+
+		session.ioFromCommand(dataChannel, stderr, func(data []byte) *api.HostDataRequest {
+			return &api.HostDataRequest{
+				Operation: &api.HostDataRequest_ErrorOutput{ErrorOutput: &api.Data{Data: data}},
+			}
+		})
+	}()
+
+	// All the reads from the pipes should be completed before calling Wait()
+	outputWG.Wait()
+
+	var exitErr *exec.ExitError
+
+	if err := cmd.Wait(); err != nil && !errors.As(err, &exitErr) {
+		session.logger.Warnf("failed to wait for the command: %v", err)
+	}
+
+	if err := session.sendTermination(dataChannel, &api.Termination{
+		Reason:     api.Termination_SHELL_EXITED,
+		ExitStatus: newExitStatus(cmd.ProcessState),
+	}); err != nil {
+		return
+	}
+
+	// Wait for the server to deliver the termination and close the channel
+	<-ctx.Done()
+}
+
+func (session *Session) ioToCommand(dataChannel api.HostService_DataChannelClient, stdin io.WriteCloser) {
+	defer stdin.Close()
+
+	for {
+		dataFromServer, err := dataChannel.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && dataChannel.Context().Err() == nil {
+				session.logger.Warnf("failed to receive Data message from data channel: %v", err)
+			}
+
+			return
+		}
+
+		// Window updates are not the Guest's activity
+		if windowUpdate, ok := dataFromServer.Operation.(*api.HostDataResponse_WindowUpdate); ok {
+			session.window.release(windowUpdate.WindowUpdate)
+
+			continue
+		}
+
+		session.updateLastActivity()
+
+		switch op := dataFromServer.Operation.(type) {
+		case *api.HostDataResponse_Input:
+			if _, err := stdin.Write(op.Input.Data); err != nil {
+				session.logger.Debugf("failed to write to the command's standard input: %v", err)
+			}
+		case *api.HostDataResponse_CloseInput:
+			if err := stdin.Close(); err != nil {
+				session.logger.Debugf("failed to close the command's standard input: %v", err)
+			}
+		case *api.HostDataResponse_ChangeDimensions:
+			// Commands without a PTY have no notion of terminal dimensions
+		default:
+			session.logger.Warnf("should've received a Data, a ChangeDimensions or a CloseInput message")
+			return
+		}
+	}
+}
+
+func (session *Session) ioFromCommand(
+	dataChannel api.HostService_DataChannelClient,
+	reader io.Reader,
+	wrap func(data []byte) *api.HostDataRequest,
+) {
+	const bufSize = 4096
+	buf := make([]byte, bufSize)
+
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			if err := session.send(dataChannel, wrap(append([]byte{}, buf[:n]...))); err != nil {
+				// Keep draining the pipe so that the command doesn't block on a write
+				_, _ = io.Copy(io.Discard, reader)
+
+				return
+			}
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrClosed) {
+				session.logger.Warnf("failed to read data from the command: %v", err)
+			}
+
+			return
+		}
+	}
+}
+
+// reportStartFailure lets the Guest know why the command wasn't started.
+func (session *Session) reportStartFailure(dataChannel api.HostService_DataChannelClient, err error) {
+	session.logger.Warnf("failed to start command: %v", err)
+
+	if err := session.send(dataChannel, &api.HostDataRequest{
+		Operation: &api.HostDataRequest_ErrorOutput{
+			ErrorOutput: &api.Data{
+				Data: []byte(fmt.Sprintf("failed to start command: %v\n", err)),
+			},
+		},
+	}); err != nil {
+		return
+	}
+
+	termination := &api.Termination{
+		Reason: api.Termination_SHELL_EXITED,
+		ExitStatus: &api.ExitStatus{
+			Code: exitCodeCannotExecute,
+		},
+		Error: &api.Error{
+			Message: fmt.Sprintf("failed to start command: %v", err),
+		},
+	}
+
+	// Let the Guest tell the refusals apart from the commands that can't be found
+	if errors.Is(err, ErrOverrideNotAllowed) {
+		termination = &api.Termination{
+			Reason: api.Termination_NOT_ALLOWED,
+			Error: &api.Error{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if err := session.sendTermination(dataChannel, termination); err != nil {
+		return
+	}
+
+	// Wait for the server to deliver the termination and close the channel
+	for {
+		if _, err := dataChannel.Recv(); err != nil {
+			return
+		}
+	}
+}
+
+// send sends the message on the data channel, it's safe to call it concurrently.
+//
+// The output waits for the server to let it through when the flow control is enabled.
+// Once the termination is sent, no more messages can be sent and io.EOF is
+// returned, just like when the server closes the data channel.
+func (session *Session) send(dataChannel api.HostService_DataChannelClient, request *api.HostDataRequest) error {
+	switch op := request.Operation.(type) {
+	case *api.HostDataRequest_Output:
+		if err := session.window.acquire(len(op.Output.Data)); err != nil {
+			return err
+		}
+
+		op.Output = session.compress(op.Output.Data)
+	case *api.HostDataRequest_ErrorOutput:
+		if err := session.window.acquire(len(op.ErrorOutput.Data)); err != nil {
+			return err
+		}
+
+		op.ErrorOutput = session.compress(op.ErrorOutput.Data)
+	}
+
+	session.sendLock.Lock()
+	defer session.sendLock.Unlock()
+
+	if session.terminationSent {
+		return io.EOF
+	}
+
+	return dataChannel.Send(request)
+}
+
+// compress compresses the output with the compression negotiated with the server, if any.
+func (session *Session) compress(data []byte) *api.Data {
+	if session.compression == api.Compression_COMPRESSION_NONE {
+		return &api.Data{Data: data}
+	}
+
+	compressed := compression.Compress(session.compression, data)
+
+	session.compressionStats.Record(len(data), len(compressed.Data))
+
+	return compressed
+}
+
+// sendTermination sends the termination as the last message on the data channel,
+// only the first termination is sent, e.g. the shell exit after a timeout is not reported.
+func (session *Session) sendTermination(
+	dataChannel api.HostService_DataChannelClient,
+	termination *api.Termination,
+) error {
+	session.sendLock.Lock()
+	defer session.sendLock.Unlock()
+
+	if session.terminationSent {
+		return nil
+	}
+
+	session.terminationSent = true
+
+	if err := dataChannel.Send(&api.HostDataRequest{
+		Operation: &api.HostDataRequest_Termination{
+			Termination: termination,
+		},
+	}); err != nil {
+		if dataChannel.Context().Err() == nil {
+			session.logger.Warnf("failed to send the termination: %v", err)
+		}
+
+		return err
+	}
+
+	return dataChannel.CloseSend()
+}
+
+// newExitStatus describes how the exited process has terminated.
+func newExitStatus(processState *os.ProcessState) *api.ExitStatus {
+	if processState == nil {
+		return &api.ExitStatus{Code: -1}
+	}
+
+	exitStatus := &api.ExitStatus{
+		Code: int32(processState.ExitCode()), //nolint:gosec // exit codes always fit
+	}
+
+	if waitStatus, ok := processState.Sys().(syscall.WaitStatus); ok && waitStatus.Signaled() {
+		exitStatus.Signal = strings.TrimPrefix(unix.SignalName(waitStatus.Signal()), "SIG")
+	}
+
+	return exitStatus
+}
@@ -0,0 +1,120 @@
+//go:build !windows
+// +build !windows
+
+package session
+
+import (
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/pkg/host/recording"
+	"time"
+)
+
+type Option func(*Session)
+
+// WithRecorder records the session's terminal output, input and resizes.
+func WithRecorder(recorder recording.Recorder) Option {
+	return func(session *Session) {
+		session.recorder = recorder
+	}
+}
+
+// WithIdleTimeout closes the session when no input was received from the Guests for the specified duration.
+func WithIdleTimeout(idleTimeout time.Duration) Option {
+	return func(session *Session) {
+		session.idleTimeout = idleTimeout
+	}
+}
+
+// WithMaxSessionDuration closes the session once it has been running for the specified duration.
+func WithMaxSessionDuration(maxSessionDuration time.Duration) Option {
+	return func(session *Session) {
+		session.maxSessionDuration = maxSessionDuration
+	}
+}
+
+// WithTimeoutWarning warns the Guests about the session being closed
+// due to one of the timeouts the specified duration in advance.
+func WithTimeoutWarning(timeoutWarning time.Duration) Option {
+	return func(session *Session) {
+		session.timeoutWarning = timeoutWarning
+	}
+}
+
+// WithShell runs the specified shell instead of the auto-detected one.
+func WithShell(shell string, args ...string) Option {
+	return func(session *Session) {
+		session.shell = shell
+		session.shellArgs = args
+	}
+}
+
+// WithLoginShell starts the shell as a login shell.
+func WithLoginShell(loginShell bool) Option {
+	return func(session *Session) {
+		session.loginShell = loginShell
+	}
+}
+
+// WithWorkingDirectory starts the shell and the commands in the specified directory.
+func WithWorkingDirectory(workingDirectory string) Option {
+	return func(session *Session) {
+		session.workingDirectory = workingDirectory
+	}
+}
+
+// WithTerm specifies the TERM value for the processes attached to a PTY.
+func WithTerm(term string) Option {
+	return func(session *Session) {
+		session.term = term
+	}
+}
+
+// WithAllowedShells specifies the shells that the Guests are allowed to request.
+func WithAllowedShells(allowedShells ...string) Option {
+	return func(session *Session) {
+		session.allowedShells = allowedShells
+	}
+}
+
+// WithAllowedCommands specifies the commands that the Guests are allowed to run instead of the shell,
+// all the commands are allowed if none are specified.
+func WithAllowedCommands(allowedCommands ...string) Option {
+	return func(session *Session) {
+		session.allowedCommands = allowedCommands
+	}
+}
+
+// WithAllowedWorkingDirectories specifies the directories (including their
+// subdirectories) that the Guests are allowed to request as a working directory.
+func WithAllowedWorkingDirectories(allowedWorkingDirectories ...string) Option {
+	return func(session *Session) {
+		session.allowedWorkingDirectories = allowedWorkingDirectories
+	}
+}
+
+// WithFlowControlWindow makes the session wait for the window updates from the server
+// once the specified number of output bytes is sent. Zero disables the flow control.
+func WithFlowControlWindow(flowControlWindow uint64) Option {
+	return func(session *Session) {
+		session.window = nil
+
+		if flowControlWindow != 0 {
+			session.window = newWindow(flowControlWindow)
+		}
+	}
+}
+
+// WithOutputCoalescingDelay waits up to the specified delay for more PTY output
+// before sending it to the server, zero sends each read right away.
+func WithOutputCoalescingDelay(delay time.Duration) Option {
+	return func(session *Session) {
+		session.outputCoalescingDelay = delay
+	}
+}
+
+// WithCompression compresses the output sent to the server with the specified compression.
+func WithCompression(compression api.Compression) Option {
+	return func(session *Session) {
+		session.compression = compression
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"errors"
 	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/internal/compression"
+	"github.com/cirruslabs/terminal/pkg/host/recording"
 	"github.com/creack/pty"
 	"go.uber.org/zap"
 	"io"
@@ -14,6 +16,7 @@ import (
 	"os/exec"
 	"runtime"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -29,16 +32,50 @@ type Session struct {
 
 	shellEnv []string
 
+	recorder         recording.Recorder
+	recording        recording.Recording
+	recordingErrOnce sync.Once
+
+	shell                     string
+	shellArgs                 []string
+	loginShell                bool
+	workingDirectory          string
+	term                      string
+	allowedShells             []string
+	allowedCommands           []string
+	allowedWorkingDirectories []string
+
+	idleTimeout        time.Duration
+	maxSessionDuration time.Duration
+	timeoutWarning     time.Duration
+
+	// Only set when the server has enabled the flow control
+	window *window
+
+	outputCoalescingDelay time.Duration
+	compression           api.Compression
+	compressionStats      compression.Stats
+
+	sendLock        sync.Mutex
+	terminationSent bool
+
 	lastActivityLock sync.Mutex
 	lastActivity     time.Time
 }
 
-func New(logger *zap.Logger, token string, shellEnv []string) *Session {
-	return &Session{
+func New(logger *zap.Logger, token string, shellEnv []string, opts ...Option) *Session {
+	session := &Session{
 		logger:   logger.Sugar(),
 		token:    token,
 		shellEnv: shellEnv,
 	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(session)
+	}
+
+	return session
 }
 
 func (session *Session) Token() string {
@@ -50,6 +87,8 @@ func (session *Session) Run(
 	hostService api.HostServiceClient,
 	locator string,
 	dimensions *api.TerminalDimensions,
+	command *api.Command,
+	shellOverride *api.ShellOverride,
 ) {
 	dataChannelCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -60,11 +99,19 @@ func (session *Session) Run(
 		return
 	}
 
+	if session.compression != api.Compression_COMPRESSION_NONE {
+		defer func() {
+			session.logger.Debugf("%s compression saved %d bytes out of %d bytes of output",
+				session.compression, session.compressionStats.Saved(), session.compressionStats.Uncompressed())
+		}()
+	}
+
 	if err := dataChannel.Send(&api.HostDataRequest{
 		Operation: &api.HostDataRequest_Hello_{
 			Hello: &api.HostDataRequest_Hello{
-				Locator: locator,
-				Token:   session.Token(),
+				Locator:     locator,
+				Token:       session.Token(),
+				FlowControl: session.window != nil,
 			},
 		},
 	}); err != nil {
@@ -72,8 +119,30 @@ func (session *Session) Run(
 		return
 	}
 
-	shellPty, err := NewShellPTY(session.logger, dimensions, session.shellEnv)
+	shellConfig, err := session.resolveShellConfig(shellOverride)
+	if err != nil {
+		session.reportStartFailure(dataChannel, err)
+
+		return
+	}
+
+	if err := session.checkCommand(command); err != nil {
+		session.reportStartFailure(dataChannel, err)
+
+		return
+	}
+
+	// Commands that don't need a PTY are run with their standard streams connected to pipes
+	if command != nil && !command.Pty {
+		session.runCommand(dataChannelCtx, cancel, dataChannel, command, shellConfig)
+
+		return
+	}
+
+	shellPty, err := session.startPTY(dimensions, command, shellConfig)
 	if err != nil {
+		session.reportStartFailure(dataChannel, err)
+
 		session.logger.Warnf("failed to create PTY with shell: %v", err)
 		return
 	}
@@ -85,16 +154,38 @@ func (session *Session) Run(
 		}
 	}()
 
+	if session.recorder != nil {
+		session.startRecording(shellPty, dimensions)
+	}
+	if session.recording != nil {
+		defer func() {
+			if err := session.recording.Close(); err != nil {
+				session.logger.Warnf("failed to finish the session recording: %v", err)
+			}
+		}()
+	}
+
+	go session.enforceTimeouts(dataChannelCtx, cancel, dataChannel, true)
+
 	// Receive terminal input from the server and write it to the PTY
 	go func() {
 		defer cancel()
+		defer session.window.close()
 		session.ioToPty(dataChannel, shellPty)
 	}()
 
 	// Read output from the PTY and send it to the server
 	go func() {
-		defer cancel()
 		session.ioFromPty(dataChannel, shellPty)
+
+		// Let the Guest know how the shell has exited, the server
+		// will close the channel once the termination is delivered
+		if err := session.sendTermination(dataChannel, &api.Termination{
+			Reason:     api.Termination_SHELL_EXITED,
+			ExitStatus: shellPty.ExitStatus(),
+		}); err != nil {
+			cancel()
+		}
 	}()
 
 	<-dataChannelCtx.Done()
@@ -111,6 +202,13 @@ func (session *Session) ioToPty(dataChannel api.HostService_DataChannelClient, s
 			return
 		}
 
+		// Window updates are not the Guest's activity
+		if windowUpdate, ok := dataFromServer.Operation.(*api.HostDataResponse_WindowUpdate); ok {
+			session.window.release(windowUpdate.WindowUpdate)
+
+			continue
+		}
+
 		session.updateLastActivity()
 
 		switch op := dataFromServer.Operation.(type) {
@@ -119,11 +217,24 @@ func (session *Session) ioToPty(dataChannel api.HostService_DataChannelClient, s
 				session.logger.Warnf("failed to write to PTY: %v", err)
 				return
 			}
+
+			session.record(func(recording recording.Recording) error {
+				return recording.Input(op.Input.Data)
+			})
 		case *api.HostDataResponse_ChangeDimensions:
 			if err := shellPty.Resize(op.ChangeDimensions); err != nil {
 				session.logger.Warnf("failed to resize PTY: %v", err)
 				return
 			}
+
+			winsize := terminalDimensionsToPtyWinsize(op.ChangeDimensions)
+
+			session.record(func(recording recording.Recording) error {
+				return recording.Resize(uint32(winsize.Cols), uint32(winsize.Rows))
+			})
+		case *api.HostDataResponse_CloseInput:
+			// There's no way to signal the end of input through a PTY
+			// without interfering with the program, so just ignore it
 		default:
 			session.logger.Warnf("should've received a Data or a ChangeDimensions message")
 			return
@@ -132,32 +243,73 @@ func (session *Session) ioToPty(dataChannel api.HostService_DataChannelClient, s
 }
 
 func (session *Session) ioFromPty(dataChannel api.HostService_DataChannelClient, shellPty io.Reader) {
-	const bufSize = 4096
-	buf := make([]byte, bufSize)
+	done := make(chan struct{})
+	defer close(done)
 
-	for {
-		n, err := shellPty.Read(buf)
-		if err != nil {
-			if !errors.Is(err, io.EOF) {
-				session.logger.Warnf("failed to read data from the PTY: %v", err)
-			}
+	var readErr error
 
-			return
-		}
+	chunks := readChunks(shellPty, done, &readErr)
 
-		if err := dataChannel.Send(&api.HostDataRequest{
+	// Coalesce the small reads to avoid sending a message per character
+	err := coalesce(chunks, session.outputCoalescingDelay, func(data []byte) error {
+		session.record(func(recording recording.Recording) error {
+			return recording.Output(data)
+		})
+
+		return session.send(dataChannel, &api.HostDataRequest{
 			Operation: &api.HostDataRequest_Output{
 				Output: &api.Data{
-					Data: buf[:n],
+					Data: data,
 				},
 			},
-		}); err != nil {
-			if !errors.Is(err, io.EOF) && dataChannel.Context().Err() == nil {
-				session.logger.Warnf("failed to send data from PTY: %v", err)
-			}
-
-			return
+		})
+	})
+	if err != nil {
+		if !errors.Is(err, io.EOF) && dataChannel.Context().Err() == nil {
+			session.logger.Warnf("failed to send data from PTY: %v", err)
 		}
+
+		return
+	}
+
+	// Reading from a PTY whose process has exited results in EIO on Linux
+	if !errors.Is(readErr, io.EOF) && !errors.Is(readErr, syscall.EIO) {
+		session.logger.Warnf("failed to read data from the PTY: %v", readErr)
+	}
+}
+
+func (session *Session) startRecording(shellPty *ShellPTY, dimensions *api.TerminalDimensions) {
+	winsize := terminalDimensionsToPtyWinsize(dimensions)
+
+	newRecording, err := session.recorder.NewRecording(session.token, recording.Header{
+		WidthColumns: uint32(winsize.Cols),
+		HeightRows:   uint32(winsize.Rows),
+		Timestamp:    time.Now(),
+		Env: map[string]string{
+			"SHELL": shellPty.shellCmd.Path,
+			"TERM":  session.ptyTerm(),
+		},
+	})
+	if err != nil {
+		session.logger.Warnf("failed to start recording the session: %v", err)
+
+		return
+	}
+
+	session.recording = newRecording
+}
+
+// record passes the event to the session recording (if any), recording
+// failures shouldn't affect the session, so they are only logged once.
+func (session *Session) record(event func(recording recording.Recording) error) {
+	if session.recording == nil {
+		return
+	}
+
+	if err := event(session.recording); err != nil {
+		session.recordingErrOnce.Do(func() {
+			session.logger.Warnf("failed to record the session: %v", err)
+		})
 	}
 }
 
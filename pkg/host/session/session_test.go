@@ -5,11 +5,16 @@
 package session
 
 import (
+	"bytes"
+	"fmt"
 	"github.com/cirruslabs/terminal/internal/api"
 	"github.com/creack/pty"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -31,3 +36,123 @@ func TestTerminalDimensionsToPtyWinsize(t *testing.T) {
 	assert.Equal(t, &pty.Winsize{Rows: 48, Cols: 160},
 		terminalDimensionsToPtyWinsize(&api.TerminalDimensions{WidthColumns: 160, HeightRows: 48}))
 }
+
+// shellOutput runs the command in the session's shell started just like the Run() does and returns its output.
+func shellOutput(t *testing.T, session *Session, command string) string {
+	config, err := session.resolveShellConfig(nil)
+	require.NoError(t, err)
+
+	shellPty, err := session.startPTY(nil, nil, config)
+	require.NoError(t, err)
+
+	_, err = fmt.Fprintln(shellPty, command+" ; exit")
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer([]byte{})
+
+	_, _ = io.Copy(buf, shellPty)
+
+	require.NoError(t, shellPty.Close())
+
+	return buf.String()
+}
+
+func TestShellAndTermAreConfigurable(t *testing.T) {
+	session := New(zap.NewNop(), "", nil, WithShell("/bin/sh"), WithTerm("xterm-256color"))
+
+	output := shellOutput(t, session, "echo \"shell=$0\" ; env")
+	assert.Contains(t, output, "shell=/bin/sh")
+	assert.Contains(t, output, "TERM=xterm-256color")
+}
+
+func TestShellOverrideAllowlist(t *testing.T) {
+	allowedDir := t.TempDir()
+	nestedDir := filepath.Join(allowedDir, "nested")
+	require.NoError(t, os.Mkdir(nestedDir, 0700))
+
+	// A symbolic link shouldn't be a way to escape the allowed directory
+	escapingLink := filepath.Join(allowedDir, "escape")
+	require.NoError(t, os.Symlink(t.TempDir(), escapingLink))
+
+	session := New(zap.NewNop(), "", nil,
+		WithShell("/bin/sh"),
+		WithAllowedShells("/bin/bash"),
+		WithAllowedWorkingDirectories(allowedDir),
+	)
+
+	config, err := session.resolveShellConfig(nil)
+	require.NoError(t, err)
+	require.Equal(t, "/bin/sh", config.shell)
+
+	config, err = session.resolveShellConfig(&api.ShellOverride{
+		Shell:            "/bin/bash",
+		Args:             []string{"--norc"},
+		WorkingDirectory: nestedDir,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "/bin/bash", config.shell)
+	require.Equal(t, []string{"--norc"}, config.args)
+
+	resolvedNestedDir, err := filepath.EvalSymlinks(nestedDir)
+	require.NoError(t, err)
+	require.Equal(t, resolvedNestedDir, config.workingDirectory)
+
+	for _, override := range []*api.ShellOverride{
+		{Shell: "/bin/zsh"},
+		{Args: []string{"-c", "true"}},
+		{WorkingDirectory: "relative"},
+		{WorkingDirectory: filepath.Dir(allowedDir)},
+		{WorkingDirectory: escapingLink},
+	} {
+		_, err := session.resolveShellConfig(override)
+		require.ErrorIs(t, err, ErrOverrideNotAllowed, "override %v", override)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		delay    time.Duration
+		expected [][]byte
+	}{
+		{"coalesced", time.Minute, [][]byte{[]byte("abc")}},
+		{"disabled", 0, [][]byte{[]byte("a"), []byte("b"), []byte("c")}},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			chunks := make(chan []byte, 3)
+			chunks <- []byte("a")
+			chunks <- []byte("b")
+			chunks <- []byte("c")
+			close(chunks)
+
+			var flushed [][]byte
+
+			require.NoError(t, coalesce(chunks, testCase.delay, func(data []byte) error {
+				flushed = append(flushed, data)
+
+				return nil
+			}))
+			require.Equal(t, testCase.expected, flushed)
+		})
+	}
+}
+
+func TestCoalesceFlushesAfterDelay(t *testing.T) {
+	chunks := make(chan []byte)
+	flushed := make(chan []byte, 1)
+
+	go func() {
+		_ = coalesce(chunks, 100*time.Millisecond, func(data []byte) error {
+			flushed <- data
+
+			return nil
+		})
+	}()
+	defer close(chunks)
+
+	chunks <- []byte("a")
+	chunks <- []byte("b")
+
+	// Flushed without waiting for the channel to be closed
+	require.Equal(t, []byte("ab"), <-flushed)
+}
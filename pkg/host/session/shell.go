@@ -0,0 +1,157 @@
+//go:build !windows
+// +build !windows
+
+package session
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/terminal/internal/api"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const defaultTerm = "xterm"
+
+var ErrOverrideNotAllowed = errors.New("shell override is not allowed")
+
+// shellConfig describes how to start the shell or the command of the session.
+type shellConfig struct {
+	shell            string
+	args             []string
+	login            bool
+	workingDirectory string
+}
+
+// resolveShellConfig combines the Host's shell configuration with
+// the Guest's override, which is only allowed if it's in the allowlist.
+func (session *Session) resolveShellConfig(override *api.ShellOverride) (*shellConfig, error) {
+	config := &shellConfig{
+		shell:            session.shell,
+		args:             session.shellArgs,
+		login:            session.loginShell,
+		workingDirectory: session.workingDirectory,
+	}
+
+	if config.shell == "" {
+		config.shell = determineShellPath()
+	}
+
+	if override == nil {
+		return config, nil
+	}
+
+	if override.Shell != "" {
+		if !slices.Contains(session.allowedShells, override.Shell) {
+			return nil, fmt.Errorf("%w: shell %q is not in the Host's allowlist",
+				ErrOverrideNotAllowed, override.Shell)
+		}
+
+		config.shell = override.Shell
+		config.args = override.Args
+	} else if len(override.Args) != 0 {
+		return nil, fmt.Errorf("%w: shell arguments can only be overridden together with the shell",
+			ErrOverrideNotAllowed)
+	}
+
+	if override.WorkingDirectory != "" {
+		workingDirectory, err := session.resolveWorkingDirectory(override.WorkingDirectory)
+		if err != nil {
+			return nil, err
+		}
+
+		config.workingDirectory = workingDirectory
+	}
+
+	return config, nil
+}
+
+// checkCommand makes sure that the command requested by the Guest is in the allowlist,
+// if the Host has configured one, otherwise all the commands are allowed.
+func (session *Session) checkCommand(command *api.Command) error {
+	if command == nil || len(session.allowedCommands) == 0 || slices.Contains(session.allowedCommands, command.Name) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: command %q is not in the Host's allowlist", ErrOverrideNotAllowed, command.Name)
+}
+
+// resolveWorkingDirectory makes sure that the directory requested by the Guest
+// is one of the allowed directories or is located inside of one of them.
+func (session *Session) resolveWorkingDirectory(workingDirectory string) (string, error) {
+	if !filepath.IsAbs(workingDirectory) {
+		return "", fmt.Errorf("%w: working directory %q is not an absolute path",
+			ErrOverrideNotAllowed, workingDirectory)
+	}
+
+	// Resolve the symbolic links so that they can't be used to escape the allowed directories
+	resolved, err := filepath.EvalSymlinks(workingDirectory)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to resolve working directory %q: %v",
+			ErrOverrideNotAllowed, workingDirectory, err)
+	}
+
+	for _, allowedDirectory := range session.allowedWorkingDirectories {
+		resolvedAllowedDirectory, err := filepath.EvalSymlinks(allowedDirectory)
+		if err != nil {
+			resolvedAllowedDirectory = filepath.Clean(allowedDirectory)
+		}
+
+		if isSubdirectory(resolvedAllowedDirectory, resolved) {
+			return resolved, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: working directory %q is not in the Host's allowlist",
+		ErrOverrideNotAllowed, workingDirectory)
+}
+
+// newShellCmd creates the shell process described by the config.
+func (session *Session) newShellCmd(config *shellConfig) *exec.Cmd {
+	cmd := exec.Command(config.shell, config.args...)
+
+	// Login shells are conventionally started with a "-" prepended to their name
+	if config.login {
+		cmd.Args[0] = "-" + filepath.Base(config.shell)
+	}
+
+	session.prepareCmd(cmd, config)
+
+	return cmd
+}
+
+// prepareCmd sets the environment and the working directory of the process.
+func (session *Session) prepareCmd(cmd *exec.Cmd, config *shellConfig) {
+	cmd.Env = commandEnv(session.shellEnv)
+	cmd.Dir = config.workingDirectory
+}
+
+// ptyTerm returns the TERM value for the processes attached to a PTY.
+func (session *Session) ptyTerm() string {
+	if session.term == "" {
+		return defaultTerm
+	}
+
+	return session.term
+}
+
+func commandEnv(env []string) []string {
+	// Inherit this process environment variables
+	if len(env) == 0 {
+		return os.Environ()
+	}
+
+	return slices.Clone(env)
+}
+
+func isSubdirectory(parent string, child string) bool {
+	rel, err := filepath.Rel(parent, child)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -4,34 +4,55 @@
 package session
 
 import (
+	"errors"
 	"github.com/cirruslabs/terminal/internal/api"
 	"github.com/creack/pty"
 	"go.uber.org/zap"
 	"os"
 	"os/exec"
+	"sync"
 )
 
 type ShellPTY struct {
 	logger   *zap.SugaredLogger
 	shellCmd *exec.Cmd
 	pty      *os.File
+
+	waitOnce sync.Once
+	waitErr  error
 }
 
 func NewShellPTY(logger *zap.SugaredLogger, dimensions *api.TerminalDimensions, env []string) (*ShellPTY, error) {
-	// Create a PTY with a shell attached to it
-	shellPath := determineShellPath()
-	shellCmd := exec.Command(shellPath)
+	// Create a PTY with the default shell attached to it
+	session := &Session{logger: logger, shellEnv: env}
+
+	return session.startPTY(dimensions, nil, &shellConfig{shell: determineShellPath()})
+}
 
-	// Inherit this process environment variables
-	if len(env) == 0 {
-		shellCmd.Env = os.Environ()
+// startPTY starts the shell described by the config, or the command
+// (if any) in the shell's environment, attached to a new PTY.
+func (session *Session) startPTY(
+	dimensions *api.TerminalDimensions,
+	command *api.Command,
+	config *shellConfig,
+) (*ShellPTY, error) {
+	var shellCmd *exec.Cmd
+
+	if command != nil {
+		shellCmd = exec.Command(command.Name, command.Args...)
+		session.prepareCmd(shellCmd, config)
 	} else {
-		shellCmd.Env = env
+		shellCmd = session.newShellCmd(config)
 	}
 
 	// Set TERM to avoid "Error opening terminal: unknown." error
-	shellCmd.Env = append(shellCmd.Env, "TERM=xterm")
+	shellCmd.Env = append(shellCmd.Env, "TERM="+session.ptyTerm())
 
+	return newPTY(session.logger, dimensions, shellCmd)
+}
+
+// newPTY starts the prepared process attached to a new PTY.
+func newPTY(logger *zap.SugaredLogger, dimensions *api.TerminalDimensions, shellCmd *exec.Cmd) (*ShellPTY, error) {
 	pty, err := pty.StartWithSize(shellCmd, terminalDimensionsToPtyWinsize(dimensions))
 	if err != nil {
 		return nil, err
@@ -58,6 +79,22 @@ func (sp *ShellPTY) Resize(dimensions *api.TerminalDimensions) error {
 	return pty.Setsize(sp.pty, terminalDimensionsToPtyWinsize(dimensions))
 }
 
+// Wait waits for the process to exit, it's safe to call it multiple times.
+func (sp *ShellPTY) Wait() error {
+	sp.waitOnce.Do(func() {
+		sp.waitErr = sp.shellCmd.Wait()
+	})
+
+	return sp.waitErr
+}
+
+// ExitStatus waits for the process to exit and returns its exit code or the signal that killed it.
+func (sp *ShellPTY) ExitStatus() *api.ExitStatus {
+	_ = sp.Wait()
+
+	return newExitStatus(sp.shellCmd.ProcessState)
+}
+
 func (sp *ShellPTY) Close() error {
 	var result error
 
@@ -67,7 +104,7 @@ func (sp *ShellPTY) Close() error {
 
 	sp.logger.Debugf("killing shell process with PID %d", sp.shellCmd.Process.Pid)
 
-	if err := sp.shellCmd.Process.Kill(); err != nil {
+	if err := sp.shellCmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
 		sp.logger.Warnf("failed to kill shell process with PID %d: %v", sp.shellCmd.Process.Pid, err)
 
 		if result == nil {
@@ -75,7 +112,10 @@ func (sp *ShellPTY) Close() error {
 		}
 	}
 
-	if err := sp.shellCmd.Wait(); err != nil && result == nil {
+	// Non-zero exit code is expected after the kill
+	var exitErr *exec.ExitError
+
+	if err := sp.Wait(); err != nil && !errors.As(err, &exitErr) && result == nil {
 		result = err
 	}
 
@@ -0,0 +1,174 @@
+//go:build !windows
+// +build !windows
+
+package session
+
+import (
+	"context"
+	"fmt"
+	"github.com/cirruslabs/terminal/internal/api"
+	"github.com/cirruslabs/terminal/pkg/host/recording"
+	"time"
+)
+
+// How long to wait for the server to deliver the timeout termination
+// to the Guests before closing the data channel forcibly.
+const terminationDeliveryTimeout = 5 * time.Second
+
+// enforceTimeouts ends the session once it has been idle for too long or has
+// reached its maximum duration, optionally warning the Guests beforehand.
+//
+// The warning is written into the terminal for PTY sessions and into the
+// standard error for the commands, to avoid corrupting their output.
+func (session *Session) enforceTimeouts(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	dataChannel api.HostService_DataChannelClient,
+	pty bool,
+) {
+	if session.idleTimeout == 0 && session.maxSessionDuration == 0 {
+		return
+	}
+
+	startedAt := time.Now()
+
+	// Deadline we've already warned about, the idle deadline moves
+	// with each activity, so there may be more than one warning
+	var warnedDeadline time.Time
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		deadline, reason := session.nextDeadline(startedAt)
+
+		if !now.Before(deadline) {
+			session.terminateOnTimeout(ctx, cancel, dataChannel, reason)
+
+			return
+		}
+
+		next := deadline
+
+		if session.timeoutWarning > 0 {
+			warnAt := deadline.Add(-session.timeoutWarning)
+
+			if now.Before(warnAt) {
+				next = warnAt
+			} else if !deadline.Equal(warnedDeadline) {
+				session.sendTimeoutWarning(dataChannel, reason, deadline.Sub(now), pty)
+				warnedDeadline = deadline
+			}
+		}
+
+		timer.Reset(next.Sub(now))
+	}
+}
+
+// nextDeadline returns the earliest of the idle and the maximum duration deadlines.
+func (session *Session) nextDeadline(startedAt time.Time) (time.Time, api.Termination_Reason) {
+	var deadline time.Time
+	var reason api.Termination_Reason
+
+	if session.idleTimeout != 0 {
+		lastActivity := session.LastActivity()
+		if lastActivity.Before(startedAt) {
+			lastActivity = startedAt
+		}
+
+		deadline = lastActivity.Add(session.idleTimeout)
+		reason = api.Termination_IDLE_TIMEOUT
+	}
+
+	if session.maxSessionDuration != 0 {
+		maxDeadline := startedAt.Add(session.maxSessionDuration)
+
+		if deadline.IsZero() || maxDeadline.Before(deadline) {
+			deadline = maxDeadline
+			reason = api.Termination_MAX_DURATION_EXCEEDED
+		}
+	}
+
+	return deadline, reason
+}
+
+func (session *Session) sendTimeoutWarning(
+	dataChannel api.HostService_DataChannelClient,
+	reason api.Termination_Reason,
+	remaining time.Duration,
+	pty bool,
+) {
+	banner := fmt.Sprintf("\r\n*** This session will be closed in %s %s ***\r\n",
+		remaining.Round(time.Second), describeTimeout(reason))
+
+	request := &api.HostDataRequest{
+		Operation: &api.HostDataRequest_ErrorOutput{
+			ErrorOutput: &api.Data{Data: []byte(banner)},
+		},
+	}
+
+	if pty {
+		request.Operation = &api.HostDataRequest_Output{
+			Output: &api.Data{Data: []byte(banner)},
+		}
+
+		session.record(func(recording recording.Recording) error {
+			return recording.Output([]byte(banner))
+		})
+	}
+
+	if err := session.send(dataChannel, request); err != nil {
+		session.logger.Debugf("failed to send the timeout warning: %v", err)
+	}
+}
+
+// terminateOnTimeout lets the Guests know why the session is being
+// closed and then closes the data channel, which kills the shell.
+func (session *Session) terminateOnTimeout(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	dataChannel api.HostService_DataChannelClient,
+	reason api.Termination_Reason,
+) {
+	defer cancel()
+
+	var message string
+
+	if reason == api.Termination_IDLE_TIMEOUT {
+		message = fmt.Sprintf("session was idle for more than %s", session.idleTimeout)
+	} else {
+		message = fmt.Sprintf("session has exceeded its maximum duration of %s", session.maxSessionDuration)
+	}
+
+	session.logger.Infof("closing the session: %s", message)
+
+	if err := session.sendTermination(dataChannel, &api.Termination{
+		Reason: reason,
+		Error: &api.Error{
+			Message: message,
+		},
+	}); err != nil {
+		return
+	}
+
+	// Wait for the server to deliver the termination and close the channel
+	select {
+	case <-ctx.Done():
+	case <-time.After(terminationDeliveryTimeout):
+	}
+}
+
+func describeTimeout(reason api.Termination_Reason) string {
+	if reason == api.Termination_IDLE_TIMEOUT {
+		return "due to inactivity"
+	}
+
+	return "because it has reached its maximum duration"
+}
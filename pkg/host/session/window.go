@@ -0,0 +1,83 @@
+//go:build !windows
+// +build !windows
+
+package session
+
+import (
+	"io"
+	"math"
+	"sync"
+)
+
+// window tracks how many output bytes the server is ready to accept.
+type window struct {
+	lock   sync.Mutex
+	cond   *sync.Cond
+	credit int64
+	closed bool
+}
+
+func newWindow(size uint64) *window {
+	window := &window{
+		credit: int64(min(size, math.MaxInt32)),
+	}
+
+	window.cond = sync.NewCond(&window.lock)
+
+	return window
+}
+
+// acquire waits until there's at least some credit available and then consumes
+// n bytes of it. The credit can go negative, so that the output chunks don't need
+// to be split, which only lets the Host overshoot the window by a single chunk.
+//
+// Returns io.EOF once the window is closed.
+func (window *window) acquire(n int) error {
+	if window == nil {
+		return nil
+	}
+
+	window.lock.Lock()
+	defer window.lock.Unlock()
+
+	for window.credit <= 0 && !window.closed {
+		window.cond.Wait()
+	}
+
+	if window.closed {
+		return io.EOF
+	}
+
+	window.credit -= int64(n)
+
+	return nil
+}
+
+// release returns the credit for the output consumed by the server.
+func (window *window) release(n uint64) {
+	if window == nil {
+		return
+	}
+
+	window.lock.Lock()
+	defer window.lock.Unlock()
+
+	// Clamp the update to not to overflow the credit
+	window.credit += int64(min(n, math.MaxInt32))
+
+	window.cond.Broadcast()
+}
+
+// close wakes up and fails the pending and all the future acquire() calls.
+func (window *window) close() {
+	if window == nil {
+		return
+	}
+
+	window.lock.Lock()
+	defer window.lock.Unlock()
+
+	window.closed = true
+
+	window.cond.Broadcast()
+}
@@ -0,0 +1,297 @@
+//go:build !windows
+// +build !windows
+
+package host
+
+import (
+	"errors"
+	"fmt"
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const subsystemSFTP = "sftp"
+
+var ErrSubsystemNotAllowed = errors.New("subsystem is not allowed")
+
+// startSubsystem starts the subsystem requested by the Guest and returns
+// a connection to it that can be proxied through the tunnel data channel.
+func (th *TerminalHost) startSubsystem(logger *zap.SugaredLogger, subsystem string) (io.ReadWriteCloser, error) {
+	switch subsystem {
+	case subsystemSFTP:
+		if !th.sftp {
+			return nil, fmt.Errorf("%w: SFTP is disabled on this host", ErrSubsystemNotAllowed)
+		}
+
+		return th.startSFTP(logger)
+	default:
+		return nil, fmt.Errorf("%w: unsupported subsystem %q", ErrSubsystemNotAllowed, subsystem)
+	}
+}
+
+// startSFTP runs an in-process SFTP server, which serves the file transfer root directories
+// with the permissions of the user the Host is running as.
+func (th *TerminalHost) startSFTP(logger *zap.SugaredLogger) (io.ReadWriteCloser, error) {
+	fromGuestReader, fromGuestWriter := io.Pipe()
+	toGuestReader, toGuestWriter := io.Pipe()
+
+	handler := &sftpHandler{th: th}
+
+	server := sftp.NewRequestServer(&pipeConn{
+		Reader:      fromGuestReader,
+		WriteCloser: toGuestWriter,
+		closeRead:   fromGuestReader,
+	}, sftp.Handlers{
+		FileGet:  handler,
+		FilePut:  handler,
+		FileCmd:  handler,
+		FileList: handler,
+	}, sftp.WithStartDirectory(filepath.ToSlash(th.fileTransferRoots[0])))
+
+	go func() {
+		// The server stops once the Guest closes its side for writing
+		if err := server.Serve(); err != nil && !errors.Is(err, io.EOF) {
+			logger.Debugf("SFTP server has failed: %v", err)
+		}
+
+		_ = server.Close()
+		_ = toGuestWriter.Close()
+	}()
+
+	return &pipeConn{
+		Reader:      toGuestReader,
+		WriteCloser: fromGuestWriter,
+		closeRead:   toGuestReader,
+	}, nil
+}
+
+// sftpHandler serves the SFTP requests, confining them
+// to the file transfer root directories just like the file transfers.
+type sftpHandler struct {
+	th *TerminalHost
+}
+
+// resolve resolves the symbolic links in the requested path, including the last element
+// only if follow is true, and makes sure that it's inside of one of the root directories.
+func (handler *sftpHandler) resolve(path string, follow bool) (string, error) {
+	path = filepath.Clean(filepath.FromSlash(path))
+
+	// The last element might not exist yet, so only resolve the directory it's in
+	resolvedDir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to resolve %q: %v", ErrFileTransferNotAllowed, filepath.Dir(path), err)
+	}
+
+	resolved := filepath.Join(resolvedDir, filepath.Base(path))
+
+	if fileInfo, err := os.Lstat(resolved); follow && err == nil && fileInfo.Mode()&os.ModeSymlink != 0 {
+		// Dangling symbolic links are refused too, since they could be used to create files anywhere
+		resolved, err = filepath.EvalSymlinks(resolved)
+		if err != nil {
+			return "", fmt.Errorf("%w: failed to resolve %q: %v", ErrFileTransferNotAllowed, path, err)
+		}
+	}
+
+	if !handler.th.isInFileTransferRoots(resolved) {
+		return "", fmt.Errorf("%w: %q is outside of the host's root directories", ErrFileTransferNotAllowed, path)
+	}
+
+	return resolved, nil
+}
+
+func (handler *sftpHandler) Fileread(request *sftp.Request) (io.ReaderAt, error) {
+	path, err := handler.resolve(request.Filepath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(path)
+}
+
+func (handler *sftpHandler) Filewrite(request *sftp.Request) (io.WriterAt, error) {
+	return handler.OpenFile(request)
+}
+
+func (handler *sftpHandler) OpenFile(request *sftp.Request) (sftp.WriterAtReaderAt, error) {
+	path, err := handler.resolve(request.Filepath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// O_APPEND is not used, since it conflicts with WriteAt()
+	pflags := request.Pflags()
+
+	flag := os.O_WRONLY
+	if pflags.Read {
+		flag = os.O_RDWR
+	}
+	if pflags.Creat {
+		flag |= os.O_CREATE
+	}
+	if pflags.Trunc {
+		flag |= os.O_TRUNC
+	}
+	if pflags.Excl {
+		flag |= os.O_EXCL
+	}
+
+	return os.OpenFile(path, flag, 0644)
+}
+
+func (handler *sftpHandler) Filecmd(request *sftp.Request) error {
+	switch request.Method {
+	case "Setstat":
+		path, err := handler.resolve(request.Filepath, true)
+		if err != nil {
+			return err
+		}
+
+		return setstat(path, request.AttrFlags(), request.Attributes())
+	case "Rename":
+		oldPath, err := handler.resolve(request.Filepath, false)
+		if err != nil {
+			return err
+		}
+
+		newPath, err := handler.resolve(request.Target, false)
+		if err != nil {
+			return err
+		}
+
+		return os.Rename(oldPath, newPath)
+	case "Mkdir":
+		path, err := handler.resolve(request.Filepath, false)
+		if err != nil {
+			return err
+		}
+
+		return os.Mkdir(path, 0755)
+	case "Rmdir", "Remove":
+		path, err := handler.resolve(request.Filepath, false)
+		if err != nil {
+			return err
+		}
+
+		return os.Remove(path)
+	default:
+		// The links are not supported to keep the Guests from linking to the files outside of the root directories
+		return sftp.ErrSSHFxOpUnsupported
+	}
+}
+
+func (handler *sftpHandler) Filelist(request *sftp.Request) (sftp.ListerAt, error) {
+	switch request.Method {
+	case "List":
+		path, err := handler.resolve(request.Filepath, true)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		fileInfos := make([]os.FileInfo, 0, len(entries))
+
+		for _, entry := range entries {
+			fileInfo, err := entry.Info()
+			if err != nil {
+				continue
+			}
+
+			fileInfos = append(fileInfos, fileInfo)
+		}
+
+		return sftpLister(fileInfos), nil
+	case "Stat":
+		return handler.stat(request.Filepath, true)
+	default:
+		return nil, sftp.ErrSSHFxOpUnsupported
+	}
+}
+
+func (handler *sftpHandler) Lstat(request *sftp.Request) (sftp.ListerAt, error) {
+	return handler.stat(request.Filepath, false)
+}
+
+func (handler *sftpHandler) stat(path string, follow bool) (sftp.ListerAt, error) {
+	path, err := handler.resolve(path, follow)
+	if err != nil {
+		return nil, err
+	}
+
+	fileInfo, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return sftpLister{fileInfo}, nil
+}
+
+func setstat(path string, attrFlags sftp.FileAttrFlags, attributes *sftp.FileStat) error {
+	if attrFlags.Size {
+		if err := os.Truncate(path, int64(attributes.Size)); err != nil { //nolint:gosec // sizes fit into int64
+			return err
+		}
+	}
+
+	if attrFlags.Permissions {
+		if err := os.Chmod(path, attributes.FileMode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	if attrFlags.Acmodtime {
+		if err := os.Chtimes(path, time.Unix(int64(attributes.Atime), 0),
+			time.Unix(int64(attributes.Mtime), 0)); err != nil {
+			return err
+		}
+	}
+
+	if attrFlags.UidGid {
+		if err := os.Chown(path, int(attributes.UID), int(attributes.GID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type sftpLister []os.FileInfo
+
+func (lister sftpLister) ListAt(fileInfos []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(lister)) {
+		return 0, io.EOF
+	}
+
+	n := copy(fileInfos, lister[offset:])
+	if n < len(fileInfos) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// pipeConn is one side of the in-process subsystem connection, which
+// can be closed for writing while the data from the other side is still read.
+type pipeConn struct {
+	io.Reader
+	io.WriteCloser
+
+	closeRead io.Closer
+}
+
+func (conn *pipeConn) CloseWrite() error {
+	return conn.WriteCloser.Close()
+}
+
+func (conn *pipeConn) Close() error {
+	_ = conn.WriteCloser.Close()
+
+	return conn.closeRead.Close()
+}
@@ -0,0 +1,157 @@
+//go:build !windows
+// +build !windows
+
+package host
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/terminal/internal/api"
+	"io"
+	"math"
+	"net"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	tunnelDialTimeout = 10 * time.Second
+	tunnelBufSize     = 32 * 1024
+)
+
+// serveTunnel connects to the port (or starts the subsystem) requested by the Guest and
+// proxies the connection through the tunnel data channel until either side closes it.
+func (th *TerminalHost) serveTunnel(
+	ctx context.Context,
+	hostService api.HostServiceClient,
+	locator string,
+	tunnelRequest *api.HostControlResponse_TunnelRequest,
+) {
+	logger := th.logger.Sugar().With("port", tunnelRequest.Port, "subsystem", tunnelRequest.Subsystem)
+
+	tunnelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var conn io.ReadWriteCloser
+	var dialErr error
+
+	if tunnelRequest.Subsystem != "" {
+		conn, dialErr = th.startSubsystem(logger, tunnelRequest.Subsystem)
+	} else {
+		conn, dialErr = th.dialTunnel(tunnelCtx, tunnelRequest.Port)
+	}
+	if dialErr != nil {
+		logger.Warnf("refusing the tunnel: %v", dialErr)
+	} else {
+		defer conn.Close()
+	}
+
+	tunnelChannel, err := hostService.TunnelDataChannel(tunnelCtx)
+	if err != nil {
+		logger.Warnf("failed to open tunnel data channel: %v", err)
+		return
+	}
+
+	hello := &api.HostTunnelRequest_Hello{
+		Locator: locator,
+		Token:   tunnelRequest.Token,
+	}
+
+	if dialErr != nil {
+		hello.Error = &api.Error{
+			Message: dialErr.Error(),
+		}
+	}
+
+	if err := tunnelChannel.Send(&api.HostTunnelRequest{
+		Operation: &api.HostTunnelRequest_Hello_{
+			Hello: hello,
+		},
+	}); err != nil {
+		logger.Warnf("failed to send Hello message via tunnel data channel: %v", err)
+		return
+	}
+
+	if dialErr != nil {
+		// Wait for the server to deliver the error and close the channel
+		_ = tunnelChannel.CloseSend()
+		_, _ = tunnelChannel.Recv()
+
+		return
+	}
+
+	logger.Debugf("established new tunnel")
+
+	// Read from the connection and send it to the server
+	go func() {
+		buf := make([]byte, tunnelBufSize)
+
+		for {
+			n, err := conn.Read(buf)
+			if n > 0 {
+				if err := tunnelChannel.Send(&api.HostTunnelRequest{
+					Operation: &api.HostTunnelRequest_Data{
+						Data: &api.Data{Data: append([]byte{}, buf[:n]...)},
+					},
+				}); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) && !errors.Is(err, io.ErrClosedPipe) {
+					logger.Debugf("failed to read from the tunneled connection: %v", err)
+				}
+
+				_ = tunnelChannel.CloseSend()
+
+				return
+			}
+		}
+	}()
+
+	// Receive data from the server and write it to the connection,
+	// the server closes the channel once both directions are done
+	for {
+		responseFromServer, err := tunnelChannel.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && tunnelCtx.Err() == nil {
+				logger.Debugf("tunnel data channel was closed: %v", err)
+			}
+
+			return
+		}
+
+		switch op := responseFromServer.Operation.(type) {
+		case *api.HostTunnelResponse_Data:
+			if _, err := conn.Write(op.Data.Data); err != nil {
+				logger.Debugf("failed to write to the tunneled connection: %v", err)
+
+				return
+			}
+		case *api.HostTunnelResponse_CloseWrite:
+			if closeWriter, ok := conn.(interface{ CloseWrite() error }); ok {
+				_ = closeWriter.CloseWrite()
+			}
+		default:
+			logger.Warnf("should've received a Data or a CloseWrite message")
+
+			return
+		}
+	}
+}
+
+// dialTunnel connects to the port on the loopback interface, as long as it's in the allowlist.
+func (th *TerminalHost) dialTunnel(ctx context.Context, port uint32) (net.Conn, error) {
+	if port > math.MaxUint16 || !slices.Contains(th.allowedTunnelPorts, uint16(port)) {
+		return nil, fmt.Errorf("port %d is not in the host's allowlist", port)
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, tunnelDialTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+
+	return dialer.DialContext(dialCtx, "tcp", net.JoinHostPort("127.0.0.1", strconv.FormatUint(uint64(port), 10)))
+}